@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"github.com/seoyhaein/tori/config"
+	globallog "github.com/seoyhaein/tori/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"path"
+	"strings"
+)
+
+var logger = globallog.Log
+
+// Identity 인증된 호출자. Subject 는 로그용 이름이고, Roles 로 인가 여부를 판단함.
+type Identity struct {
+	Subject string
+	Roles   []string
+}
+
+type identityKey struct{}
+
+// NewContext identity 를 담은 context 를 반환함.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext 인터셉터가 넣어둔 identity 를 꺼냄. 인증을 사용하지 않으면 nil.
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
+
+// Authenticator 요청 context(metadata 등)에서 호출자를 확인함.
+// 자격 증명이 없거나 잘못되었으면 codes.Unauthenticated status 를 반환해야 함.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Identity, error)
+}
+
+// Chain 여러 Authenticator 를 순서대로 시도해서 처음 성공한 identity 를 사용함.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context) (*Identity, error) {
+	var lastErr error
+	for _, a := range c {
+		id, err := a.Authenticate(ctx)
+		if err == nil {
+			return id, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = status.Error(codes.Unauthenticated, "no authenticator configured")
+	}
+	return nil, lastErr
+}
+
+// bearerToken metadata 의 "authorization: Bearer <token>" 에서 토큰을 꺼냄.
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing metadata")
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", status.Error(codes.Unauthenticated, "missing authorization header")
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "bearer") || strings.TrimSpace(token) == "" {
+		return "", status.Error(codes.Unauthenticated, "authorization header must be 'Bearer <token>'")
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// Policy role 별로 호출 가능한 메서드를 판단함.
+type Policy struct {
+	roles map[string][]string
+}
+
+// NewPolicy roles(role → 메서드 패턴 목록)로 Policy 를 만듦. 패턴이 잘못되었으면 에러.
+func NewPolicy(roles map[string][]string) (*Policy, error) {
+	for role, patterns := range roles {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid method pattern %q for role %q: %w", p, role, err)
+			}
+		}
+	}
+	return &Policy{roles: roles}, nil
+}
+
+// Allowed identity 의 role 중 하나라도 fullMethod 를 허용하면 true.
+func (p *Policy) Allowed(id *Identity, fullMethod string) bool {
+	if id == nil {
+		return false
+	}
+	method := shortMethod(fullMethod)
+	for _, role := range id.Roles {
+		for _, pattern := range p.roles[role] {
+			if pattern == "*" {
+				return true
+			}
+			if ok, _ := path.Match(pattern, method); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authorize 허용되지 않으면 codes.PermissionDenied status 를 반환함.
+func (p *Policy) Authorize(id *Identity, fullMethod string) error {
+	if p.Allowed(id, fullMethod) {
+		return nil
+	}
+	if id == nil {
+		return status.Errorf(codes.PermissionDenied, "anonymous caller is not allowed to call %s", fullMethod)
+	}
+	return status.Errorf(codes.PermissionDenied, "%s (roles %v) is not allowed to call %s", id.Subject, id.Roles, fullMethod)
+}
+
+// shortMethod "/package.Service/Method" 를 proto 패키지 이름과 무관하게 "Service/Method" 로 바꿈.
+func shortMethod(fullMethod string) string {
+	svc, method, found := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !found {
+		return fullMethod
+	}
+	if i := strings.LastIndex(svc, "."); i >= 0 {
+		svc = svc[i+1:]
+	}
+	return svc + "/" + method
+}
+
+// New cfg 에 설정된 토큰 소스들로 Authenticator 와 Policy 를 만듦. 인증이 꺼져 있으면 (nil, nil, nil).
+func New(cfg config.AuthConfig) (Authenticator, *Policy, error) {
+	if !cfg.Enabled() {
+		return nil, nil, nil
+	}
+	var chain Chain
+	// 인증서가 매핑되어 있으면 토큰보다 먼저 사용함. 토큰은 인증서가 없는 클라이언트를 위한 것.
+	if len(cfg.ClientCerts) > 0 {
+		chain = append(chain, NewClientCerts(cfg.ClientCerts))
+	}
+	if cfg.TokenFile != "" {
+		tokens, err := LoadTokenFile(cfg.TokenFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		chain = append(chain, tokens)
+	}
+	if cfg.HMACSecretFile != "" {
+		h, err := LoadHMACSecretFile(cfg.HMACSecretFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		chain = append(chain, h)
+	}
+	roles := cfg.Roles
+	if len(roles) == 0 {
+		roles = config.DefaultRoles()
+	}
+	policy, err := NewPolicy(roles)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chain, policy, nil
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"github.com/seoyhaein/tori/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func bearerCtx(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestPolicyAllowed(t *testing.T) {
+	p, err := NewPolicy(config.DefaultRoles())
+	if err != nil {
+		t.Fatalf("NewPolicy error: %v", err)
+	}
+	reader := &Identity{Subject: "r", Roles: []string{"reader"}}
+	admin := &Identity{Subject: "a", Roles: []string{"admin"}}
+
+	if !p.Allowed(reader, "/ichthys.DataBlockService/GetDataBlock") {
+		t.Errorf("reader should be allowed to call GetDataBlock")
+	}
+	if p.Allowed(reader, "/ichthys.DBApisService/SyncFoldersInfo") {
+		t.Errorf("reader should not be allowed to sync")
+	}
+	if !p.Allowed(admin, "/ichthys.DBApisService/SyncFoldersInfo") {
+		t.Errorf("admin should be allowed to sync")
+	}
+	if p.Allowed(nil, "/ichthys.DataBlockService/GetDataBlock") {
+		t.Errorf("nil identity should not be allowed")
+	}
+	if code := status.Code(p.Authorize(reader, "/ichthys.DBApisService/SyncFoldersInfo")); code != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied, got %v", code)
+	}
+
+	wild, err := NewPolicy(map[string][]string{"ops": {"DBApisService/*"}})
+	if err != nil {
+		t.Fatalf("NewPolicy error: %v", err)
+	}
+	if !wild.Allowed(&Identity{Roles: []string{"ops"}}, "/protos.DBApisService/SyncFoldersInfo") {
+		t.Errorf("service wildcard should match")
+	}
+	if _, err := NewPolicy(map[string][]string{"bad": {"["}}); err == nil {
+		t.Errorf("expected error for malformed pattern")
+	}
+}
+
+func TestStaticTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte("# comment\n\nt1 alice reader\nt2 bob reader,admin\n"), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+	st, err := LoadTokenFile(path)
+	if err != nil {
+		t.Fatalf("LoadTokenFile error: %v", err)
+	}
+	id, err := st.Authenticate(bearerCtx("t2"))
+	if err != nil {
+		t.Fatalf("Authenticate error: %v", err)
+	}
+	if id.Subject != "bob" || len(id.Roles) != 2 || id.Roles[1] != "admin" {
+		t.Errorf("unexpected identity: %+v", id)
+	}
+	if _, err := st.Authenticate(bearerCtx("t3")); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for unknown token, got %v", err)
+	}
+	if _, err := st.Authenticate(context.Background()); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated without metadata, got %v", err)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad")
+	if err := os.WriteFile(bad, []byte("only-token\n"), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+	if _, err := LoadTokenFile(bad); err == nil {
+		t.Errorf("expected error for malformed token line")
+	}
+}
+
+func TestHMACTokens(t *testing.T) {
+	h, err := NewHMACTokens([]byte("0123456789abcdef0123"))
+	if err != nil {
+		t.Fatalf("NewHMACTokens error: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	token, err := h.Sign(Claims{Subject: "ci", Roles: []string{"admin"}, ExpiresAt: now.Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+	id, err := h.Authenticate(bearerCtx(token))
+	if err != nil {
+		t.Fatalf("Authenticate error: %v", err)
+	}
+	if id.Subject != "ci" || len(id.Roles) != 1 || id.Roles[0] != "admin" {
+		t.Errorf("unexpected identity: %+v", id)
+	}
+
+	// 서명이 다른 secret 으로 만들어졌으면 거부.
+	other, _ := NewHMACTokens([]byte("another-secret-value"))
+	forged, _ := other.Sign(Claims{Subject: "ci", Roles: []string{"admin"}})
+	if _, err := h.Authenticate(bearerCtx(forged)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for forged token, got %v", err)
+	}
+
+	// 만료된 토큰 거부.
+	now = now.Add(2 * time.Hour)
+	if _, err := h.Authenticate(bearerCtx(token)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for expired token, got %v", err)
+	}
+
+	if _, err := NewHMACTokens([]byte("short")); err == nil {
+		t.Errorf("expected error for short secret")
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ClientCerts mTLS 로 검증된 클라이언트 인증서의 CN/SAN 으로 호출자를 확인함.
+// 인증서 검증 자체는 TLS handshake(server.tls.clientCAFile)에서 끝났으므로 여기서는 이름을 role 로 바꾸기만 함.
+type ClientCerts struct {
+	names map[string][]string
+}
+
+// NewClientCerts 인증서 이름(CN 또는 SAN) → role 목록으로 ClientCerts 를 만듦.
+func NewClientCerts(names map[string][]string) *ClientCerts {
+	return &ClientCerts{names: names}
+}
+
+func (c *ClientCerts) Authenticate(ctx context.Context) (*Identity, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing peer information")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+	leaf := tlsInfo.State.VerifiedChains[0][0]
+	for _, name := range CertNames(leaf) {
+		if roles, ok := c.names[name]; ok {
+			return &Identity{Subject: name, Roles: roles}, nil
+		}
+	}
+	return nil, status.Errorf(codes.Unauthenticated, "client certificate %q is not mapped to any role", leaf.Subject.CommonName)
+}
+
+// CertNames 인증서에서 identity 로 쓸 수 있는 이름들. CN, DNS, URI, email, IP SAN 순서.
+func CertNames(cert *x509.Certificate) []string {
+	var names []string
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	names = append(names, cert.DNSNames...)
+	for _, u := range cert.URIs {
+		names = append(names, u.String())
+	}
+	names = append(names, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return names
+}
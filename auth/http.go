@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net/http"
+)
+
+// CheckHTTP HTTP 게이트웨이 요청을 gRPC 와 같은 방식으로 인증/인가함. fullMethod 는 요청이 대응되는 gRPC 메서드.
+// Authorization 헤더는 gRPC metadata 로, HTTPS 연결 정보는 gRPC peer 로 옮겨서 같은 Authenticator 를 그대로 사용함.
+func CheckHTTP(r *http.Request, authn Authenticator, policy *Policy, fullMethod string) (context.Context, error) {
+	ctx := r.Context()
+	if r.TLS != nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: *r.TLS}})
+	}
+	if h := r.Header.Get("Authorization"); h != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", h))
+	} else {
+		ctx = metadata.NewIncomingContext(ctx, metadata.MD{})
+	}
+	return check(ctx, authn, policy, fullMethod)
+}
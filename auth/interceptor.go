@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"strings"
+)
+
+// publicMethodPrefixes 인증 없이 호출할 수 있는 서비스. 로드밸런서 등의 헬스 체크는 토큰이 없음.
+var publicMethodPrefixes = []string{"/grpc.health.v1.Health/"}
+
+func isPublic(fullMethod string) bool {
+	for _, p := range publicMethodPrefixes {
+		if strings.HasPrefix(fullMethod, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// check 인증 후 인가까지 확인하고, identity 를 담은 context 를 반환함.
+func check(ctx context.Context, authn Authenticator, policy *Policy, fullMethod string) (context.Context, error) {
+	if isPublic(fullMethod) {
+		return ctx, nil
+	}
+	id, err := authn.Authenticate(ctx)
+	if err != nil {
+		logger.Infof("unauthenticated call to %s: %v", fullMethod, err)
+		return nil, err
+	}
+	if err := policy.Authorize(id, fullMethod); err != nil {
+		logger.Infof("permission denied: %v", err)
+		return nil, err
+	}
+	return NewContext(ctx, id), nil
+}
+
+// UnaryServerInterceptor 단항 RPC 마다 인증/인가를 확인함.
+func UnaryServerInterceptor(authn Authenticator, policy *Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := check(ctx, authn, policy, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 스트림 RPC 시작 시 인증/인가를 확인함.
+func StreamServerInterceptor(authn Authenticator, policy *Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := check(ss.Context(), authn, policy, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// wrappedStream identity 가 담긴 context 를 핸들러에 넘기기 위한 ServerStream.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
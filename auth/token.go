@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"os"
+	"strings"
+	"time"
+)
+
+// StaticTokens 정적 토큰 파일에서 읽은 토큰 목록. 토큰은 sha256 으로만 보관함.
+type StaticTokens struct {
+	tokens map[[sha256.Size]byte]*Identity
+}
+
+// LoadTokenFile 토큰 파일을 읽음. 빈 줄과 '#' 으로 시작하는 줄은 무시함.
+// 각 줄은 "<token> <subject> <role>[,<role>...]" 형식.
+func LoadTokenFile(filePath string) (*StaticTokens, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token file: %w", err)
+	}
+	defer func() {
+		if cErr := f.Close(); cErr != nil {
+			logger.Warnf("failed to close token file: %v", cErr)
+		}
+	}()
+
+	st := &StaticTokens{tokens: make(map[[sha256.Size]byte]*Identity)}
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("token file %s line %d: expected '<token> <subject> <roles>'", filePath, lineNo)
+		}
+		st.tokens[sha256.Sum256([]byte(fields[0]))] = &Identity{Subject: fields[1], Roles: strings.Split(fields[2], ",")}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	if len(st.tokens) == 0 {
+		return nil, fmt.Errorf("token file %s has no tokens", filePath)
+	}
+	return st, nil
+}
+
+func (st *StaticTokens) Authenticate(ctx context.Context) (*Identity, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 해시 값으로 찾으므로 토큰 문자열 비교 시간으로 토큰을 유추할 수 없음.
+	if id, ok := st.tokens[sha256.Sum256([]byte(token))]; ok {
+		return id, nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "invalid token")
+}
+
+// Claims HMAC 서명 토큰에 담기는 내용.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Roles     []string `json:"roles"`
+	ExpiresAt int64    `json:"exp,omitempty"` // unix 초. 0 이면 만료 없음.
+}
+
+// HMACTokens "<base64url(claims json)>.<base64url(hmac-sha256)>" 형식의 bearer 토큰을 검증함.
+type HMACTokens struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewHMACTokens secret 으로 토큰을 서명/검증하는 HMACTokens 를 만듦.
+func NewHMACTokens(secret []byte) (*HMACTokens, error) {
+	if len(secret) < 16 {
+		return nil, fmt.Errorf("hmac secret must be at least 16 bytes")
+	}
+	return &HMACTokens{secret: secret, now: time.Now}, nil
+}
+
+// LoadHMACSecretFile secret 파일을 읽어 HMACTokens 를 만듦. 앞뒤 공백은 무시함.
+func LoadHMACSecretFile(filePath string) (*HMACTokens, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hmac secret file: %w", err)
+	}
+	return NewHMACTokens([]byte(strings.TrimSpace(string(b))))
+}
+
+// Sign claims 를 서명한 토큰을 만듦.
+func (h *HMACTokens) Sign(claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal claims: %w", err)
+	}
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	return enc + "." + base64.RawURLEncoding.EncodeToString(h.mac(enc)), nil
+}
+
+func (h *HMACTokens) mac(payload string) []byte {
+	m := hmac.New(sha256.New, h.secret)
+	m.Write([]byte(payload))
+	return m.Sum(nil)
+}
+
+// Verify 토큰의 서명과 만료 시각을 확인하고 claims 를 반환함.
+func (h *HMACTokens) Verify(token string) (*Claims, error) {
+	payload, sig, found := strings.Cut(token, ".")
+	if !found {
+		return nil, fmt.Errorf("malformed token")
+	}
+	gotMAC, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	if !hmac.Equal(gotMAC, h.mac(payload)) {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token payload: %w", err)
+	}
+	var claims Claims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if claims.ExpiresAt != 0 && h.now().Unix() >= claims.ExpiresAt {
+		return nil, fmt.Errorf("token expired at %v", time.Unix(claims.ExpiresAt, 0).UTC())
+	}
+	return &claims, nil
+}
+
+func (h *HMACTokens) Authenticate(ctx context.Context) (*Identity, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := h.Verify(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return &Identity{Subject: claims.Subject, Roles: claims.Roles}, nil
+}
@@ -0,0 +1,34 @@
+package block
+
+import (
+	"fmt"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sort"
+)
+
+// ConvertMapToFileBlock rules.GroupFiles 로 묶은 행들을 blockID 의 FileBlock 으로 만듦. 행은 행 번호 순서로 넣음.
+func ConvertMapToFileBlock(rows map[int]map[string]string, headers []string, blockID string) *pb.FileBlock {
+	fb := &pb.FileBlock{BlockId: blockID, ColumnHeaders: headers}
+	keys := make([]int, 0, len(rows))
+	for k := range rows {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		cells := make(map[string]string, len(rows[k]))
+		for c, v := range rows[k] {
+			cells[c] = v
+		}
+		fb.Rows = append(fb.Rows, &pb.Row{RowNumber: int32(k), Cells: cells})
+	}
+	return fb
+}
+
+// MergeFileBlocksFromData blocks 를 DataBlock 하나로 묶고 UpdatedAt 을 지금 시각으로 채움.
+func MergeFileBlocksFromData(blocks []*pb.FileBlock) (*pb.DataBlock, error) {
+	if blocks == nil {
+		return nil, fmt.Errorf("no FileBlocks to merge")
+	}
+	return &pb.DataBlock{UpdatedAt: timestamppb.Now(), Blocks: blocks}, nil
+}
@@ -2,16 +2,15 @@ package block
 
 import (
 	"fmt"
-	pb "github.com/seoyhaein/api-protos/gen/go/datablock/ichthys"
-	"github.com/seoyhaein/api-protos/gen/go/datablock/ichthys/service"
+	"github.com/seoyhaein/tori/protofile"
+	pb "github.com/seoyhaein/tori/protos"
 	"github.com/seoyhaein/tori/rules"
 	"path/filepath"
 )
 
-//TODO pb "github.com/seoyhaein/api-protos/gen/go/datablock/ichthys" "github.com/seoyhaein/api-protos/gen/go/datablock/ichthys/service" 없애는 방향으로
-
 // GenerateFileBlockFromDir 디렉터리 경로를 받아서 FileBlock 객체를 생성하고, 바이너리 protobuf 파일로 저장
-func GenerateFileBlockFromDir(dirPath string) (*pb.FileBlock, error) {
+// *files.pb 는 compression 으로 압축해서 저장함.
+func GenerateFileBlockFromDir(dirPath string, compression string) (*pb.FileBlock, error) {
 	// 1. 룰 로딩
 	ruleSet, err := rules.LoadRuleSetFromFile(dirPath)
 	if err != nil {
@@ -24,7 +23,7 @@ func GenerateFileBlockFromDir(dirPath string) (*pb.FileBlock, error) {
 	}
 
 	// 3. 디렉터리 내 파일 목록 읽기 (제외 패턴 지정)
-	exclusions := []string{"rule.json", "invalid_files", "fileblock.csv", "*.pb"}
+	exclusions := []string{"rule.json", rules.InvalidFilesPattern, "fileblock.csv", "*.pb"}
 	fileNames, err := rules.ListFilesExclude(dirPath, exclusions)
 	if err != nil {
 		return nil, fmt.Errorf("ReadAllFileNames error: %w", err)
@@ -50,11 +49,11 @@ func GenerateFileBlockFromDir(dirPath string) (*pb.FileBlock, error) {
 	}
 
 	// 8. validMap + headers → FileBlock 객체 생성
-	fb := service.ConvertMapToFileBlock(validMap, ruleSet.Header, dirPath)
+	fb := ConvertMapToFileBlock(validMap, ruleSet.Header, dirPath)
 
 	// 9. FileBlock → 바이너리 protobuf 파일로 저장
 	outPath := filepath.Join(dirPath, filepath.Base(dirPath)+"files.pb")
-	if err := service.SaveProtoToFile(outPath, fb, 0o777); err != nil {
+	if err := protofile.Save(outPath, fb, 0o644, compression); err != nil {
 		return nil, fmt.Errorf("SaveProtoToFile error: %w", err)
 	}
 
@@ -62,21 +61,33 @@ func GenerateFileBlockFromDir(dirPath string) (*pb.FileBlock, error) {
 }
 
 // GenerateFileBlock 일단 이름 고침. filePath 는 rule.josn 이 있는 위치이자 fileblock.csv, invalid_files, *.pb 파일 등이 가 저장될 위치.
-func GenerateFileBlock(filePath string, files []string) (*pb.FileBlock, error) {
+// *files.pb 는 compression 으로 압축해서 저장함.
+func GenerateFileBlock(filePath string, files []string, compression string) (*pb.FileBlock, error) {
+	return GenerateFileBlockWithRules(filePath, filePath, files, compression)
+}
+
+// GenerateFileBlockWithRules GenerateFileBlock 과 같지만 rule.json 을 ruleDir 에서 읽음. 결과 파일은 filePath 에 저장함.
+func GenerateFileBlockWithRules(filePath, ruleDir string, files []string, compression string) (*pb.FileBlock, error) {
+	fb, _, err := generateFileBlock(filePath, ruleDir, files, compression)
+	return fb, err
+}
+
+// generateFileBlock GenerateFileBlockWithRules 와 같고, invalid_files 로 빠진 행 수도 함께 반환함.
+func generateFileBlock(filePath, ruleDir string, files []string, compression string) (*pb.FileBlock, int, error) {
 	// Load the rule set
-	ruleSet, err := rules.LoadRuleSetFromFile(filePath) // 이 메서드에서 filepath 의 검증을 해줌.
+	ruleSet, err := rules.LoadRuleSetFromFile(ruleDir) // 이 메서드에서 filepath 의 검증을 해줌.
 	if err != nil {
-		return nil, fmt.Errorf("failed to load rule set: %w", err)
+		return nil, 0, fmt.Errorf("failed to load rule set: %w", err)
 	}
 
 	// Validate the rule set
 	if !rules.IsValidRuleSet(ruleSet) {
-		return nil, fmt.Errorf("rule set has conflicts or unused parts")
+		return nil, 0, fmt.Errorf("rule set has conflicts or unused parts")
 	}
 
 	resultMap, err := rules.GroupFiles(files, ruleSet)
 	if err != nil {
-		return nil, fmt.Errorf("failed to blockify files: %w", err)
+		return nil, 0, fmt.Errorf("failed to blockify files: %w", err)
 	}
 
 	// Filter the result map into valid and invalid rows. 열의 갯수 기준으로 유효/무효 행을 분리
@@ -84,21 +95,21 @@ func GenerateFileBlock(filePath string, files []string) (*pb.FileBlock, error) {
 
 	// Save valid rows to a CSV file. 사용자에게 보여주기 위함.
 	if err := rules.ExportResultsCSV(validRows, ruleSet.Header, filePath); err != nil {
-		return nil, fmt.Errorf("failed to save result map to CSV: %w", err)
+		return nil, 0, fmt.Errorf("failed to save result map to CSV: %w", err)
 	}
 
 	// Save invalid rows to a separate file
 	if err := rules.SaveInvalidFiles(invalidRows, filePath); err != nil {
-		return nil, fmt.Errorf("failed to write invalid files: %w", err)
+		return nil, 0, fmt.Errorf("failed to write invalid files: %w", err)
 	}
 
 	// blockId 를 filePath 로 잡아둠.
-	fbd := service.ConvertMapToFileBlock(validRows, ruleSet.Header, filePath)
+	fbd := ConvertMapToFileBlock(validRows, ruleSet.Header, filePath)
 	pbName := filepath.Join(filePath, fmt.Sprintf("%sfiles.pb", filepath.Base(filePath)))
-	err = service.SaveProtoToFile(pbName, fbd, 0777)
+	err = protofile.Save(pbName, fbd, 0o644, compression)
 	if err != nil {
-		return nil, fmt.Errorf("failed to save proto to file: %w", err)
+		return nil, 0, fmt.Errorf("failed to save proto to file: %w", err)
 	}
 
-	return fbd, nil
+	return fbd, len(invalidRows), nil
 }
@@ -2,49 +2,81 @@ package block
 
 import (
 	"fmt"
-	pb "github.com/seoyhaein/api-protos/gen/go/datablock/ichthys"
-	"github.com/seoyhaein/api-protos/gen/go/datablock/ichthys/service"
-	"os"
+	"github.com/seoyhaein/tori/protofile"
+	pb "github.com/seoyhaein/tori/protos"
 )
 
-// GenerateFBs folderFiles 를 받아서 FileBlock 객체를 생성하고, 바이너리 protobuf 파일로 저장
-func GenerateFBs(folderFiles [][]string) ([]*pb.FileBlock, error) {
-	var fileBlocks []*pb.FileBlock
+// FolderFiles FileBlock 하나로 만들 폴더와 그 폴더의 파일 이름.
+type FolderFiles struct {
+	Path string
+	// RuleDir rule.json 을 읽을 폴더. 비어 있으면 Path. 하위 폴더가 상위 폴더의 rule.json 을 물려받을 때 사용함.
+	RuleDir string
+	Files   []string
+}
 
+// GenerateFBs folderFiles 를 받아서 FileBlock 객체를 생성하고, compression 으로 압축한 바이너리 protobuf 파일로 저장
+// folderFiles 의 각 항목은 [폴더 경로, 파일 이름...] 형식이고, rule.json 은 각 폴더에서 읽음.
+func GenerateFBs(folderFiles [][]string, compression string) ([]*pb.FileBlock, error) {
+	folders := make([]FolderFiles, 0, len(folderFiles))
 	for _, ff := range folderFiles {
 		if len(ff) == 0 {
 			continue
 		}
-		folderPath := ff[0]
+		folders = append(folders, FolderFiles{Path: ff[0], Files: ff[1:]})
+	}
+	return GenerateFolderBlocks(folders, compression)
+}
 
-		var fileNames []string
-		if len(ff) > 1 {
-			fileNames = ff[1:]
+// GenerateFolderBlocks folders 마다 FileBlock 을 만들어서 각 폴더에 compression 으로 압축해 저장함.
+func GenerateFolderBlocks(folders []FolderFiles, compression string) ([]*pb.FileBlock, error) {
+	var fileBlocks []*pb.FileBlock
+	invalidRows := 0
+	for _, f := range folders {
+		ruleDir := f.RuleDir
+		if ruleDir == "" {
+			ruleDir = f.Path
 		}
-
-		fb, err := GenerateFileBlock(folderPath, fileNames)
+		fb, invalid, err := generateFileBlock(f.Path, ruleDir, f.Files, compression)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate file block for folder %s: %w", folderPath, err)
+			return nil, fmt.Errorf("failed to generate file block for folder %s: %w", f.Path, err)
 		}
 
 		fileBlocks = append(fileBlocks, fb)
+		invalidRows += invalid
 	}
+	// 모든 폴더를 다시 만들기 때문에, 사라진 폴더의 값이 남지 않도록 합계로 바꿔 씀.
+	invalidRowsGauge.Set(float64(invalidRows))
 	return fileBlocks, nil
 }
 
 // GenerateDataBlock fileblock 을 병합하여 datablcok 으로 저장
-// outputFile 은 파일이어야 함. 파일이 존재할 경우는 체크 하지 않고 덮어씀.
-func GenerateDataBlock(inputBlocks []*pb.FileBlock, outputFile string) error {
-	dataBlock, err := service.MergeFileBlocksFromData(inputBlocks)
+// outputFile 은 파일이어야 함. 파일이 존재할 경우는 체크 하지 않고 덮어씀. generation 은 매기지 않음(0).
+func GenerateDataBlock(inputBlocks []*pb.FileBlock, outputFile string, compression string) error {
+	dataBlock, err := MergeDataBlock(inputBlocks)
 	if err != nil {
 		return err
 	}
+	return SaveDataBlock(dataBlock, outputFile, compression)
+}
+
+// MergeDataBlock fileblock 들을 DataBlock 하나로 합치고 content_hash 를 채움.
+func MergeDataBlock(inputBlocks []*pb.FileBlock) (*pb.DataBlock, error) {
+	dataBlock, err := MergeFileBlocksFromData(inputBlocks)
+	if err != nil {
+		return nil, err
+	}
+	if dataBlock.ContentHash, err = ContentHash(dataBlock); err != nil {
+		return nil, err
+	}
+	return dataBlock, nil
+}
 
-	// DataBlock 저장
-	if err := service.SaveProtoToFile(outputFile, dataBlock, os.ModePerm); err != nil {
+// SaveDataBlock dataBlock 을 compression 으로 압축해서 outputFile 에 저장함.
+func SaveDataBlock(dataBlock *pb.DataBlock, outputFile string, compression string) error {
+	if err := protofile.Save(outputFile, dataBlock, 0o644, compression); err != nil {
 		return fmt.Errorf("failed to save DataBlock: %w", err)
 	}
 
-	fmt.Printf("Successfully merged %d FileBlock files into %s\n", len(inputBlocks), outputFile)
+	fmt.Printf("Successfully merged %d FileBlock files into %s\n", len(dataBlock.GetBlocks()), outputFile)
 	return nil
 }
@@ -0,0 +1,12 @@
+package block
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// invalidRowsGauge 마지막 FileBlock 생성 때 invalid_files 로 빠진 행 수의 합. 폴더 경로가 드러나지 않도록 폴더별로 나누지 않음.
+var invalidRowsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "tori_invalid_rows",
+	Help: "Rows written to invalid_files by the last FileBlock generation, summed over all folders.",
+})
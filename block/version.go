@@ -0,0 +1,23 @@
+package block
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/proto"
+)
+
+// contentHashPrefix content_hash 에 붙는 알고리즘 이름. 나중에 알고리즘을 바꿔도 이전 값과 구분할 수 있게 함.
+const contentHashPrefix = "sha256:"
+
+// ContentHash dataBlock 의 FileBlock 내용만으로 hash 를 계산함. updated_at, generation, content_hash 는 포함하지 않으므로
+// 폴더 내용이 같으면 언제 만들었든 같은 값이 나옴. 셀 map 은 deterministic marshal 로 key 순서를 고정함.
+func ContentHash(dataBlock *pb.DataBlock) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(&pb.DataBlock{Blocks: dataBlock.GetBlocks()})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal datablock for hashing: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return contentHashPrefix + hex.EncodeToString(sum[:]), nil
+}
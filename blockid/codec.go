@@ -0,0 +1,135 @@
+package blockid
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/seoyhaein/tori/config"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidID 클라이언트가 보낸 block ID 를 해석할 수 없을 때 반환함.
+var ErrInvalidID = errors.New("invalid block id")
+
+// Codec 디스크의 FileBlock.block_id(폴더 절대 경로)와 클라이언트에게 보여주는 block ID 를 서로 변환함.
+type Codec interface {
+	// Encode 폴더 경로를 클라이언트용 ID 로 바꿈.
+	Encode(folderPath string) (string, error)
+	// Decode 클라이언트용 ID 를 폴더 경로로 되돌림. 해석할 수 없으면 ErrInvalidID 를 감싼 에러.
+	Decode(id string) (string, error)
+}
+
+// Raw 경로를 그대로 ID 로 사용함. 기존 클라이언트 호환용.
+type Raw struct{}
+
+func (Raw) Encode(folderPath string) (string, error) { return folderPath, nil }
+
+func (Raw) Decode(id string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalidID)
+	}
+	return id, nil
+}
+
+// Opaque RootDir 기준 상대 경로를 AES-256-GCM 으로 암호화해서 ID 로 사용함.
+// nonce 를 경로의 HMAC 으로 만들기 때문에 같은 경로는 항상 같은 ID 가 되고(stable),
+// 키 없이는 경로를 알 수도, 위조할 수도 없음.
+type Opaque struct {
+	rootDir  string
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+// KeySize 서버 키 길이(바이트).
+const KeySize = 32
+
+// NewOpaque rootDir 와 32 바이트 서버 키로 Opaque 코덱을 만듦.
+func NewOpaque(rootDir string, key []byte) (*Opaque, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("block id key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(deriveKey(key, "tori block id encryption"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return &Opaque{
+		rootDir:  filepath.Clean(rootDir),
+		aead:     aead,
+		nonceKey: deriveKey(key, "tori block id nonce"),
+	}, nil
+}
+
+// deriveKey 하나의 서버 키에서 용도별 키를 만듦.
+func deriveKey(key []byte, label string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(label))
+	return m.Sum(nil)
+}
+
+func (o *Opaque) Encode(folderPath string) (string, error) {
+	rel, err := filepath.Rel(o.rootDir, filepath.Clean(folderPath))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("folder %s is not under root %s", folderPath, o.rootDir)
+	}
+	plain := []byte(filepath.ToSlash(rel))
+
+	m := hmac.New(sha256.New, o.nonceKey)
+	m.Write(plain)
+	nonce := m.Sum(nil)[:o.aead.NonceSize()]
+
+	sealed := o.aead.Seal(nonce[:len(nonce):len(nonce)], nonce, plain, nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (o *Opaque) Decode(id string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil || len(sealed) < o.aead.NonceSize()+o.aead.Overhead() {
+		return "", fmt.Errorf("%w: malformed", ErrInvalidID)
+	}
+	nonce, ciphertext := sealed[:o.aead.NonceSize()], sealed[o.aead.NonceSize():]
+	plain, err := o.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: authentication failed", ErrInvalidID)
+	}
+	return filepath.Join(o.rootDir, filepath.FromSlash(string(plain))), nil
+}
+
+// LoadKeyFile 서버 키 파일을 읽음. 64 자리 hex 문자열이나 32 바이트 원본 모두 허용함.
+func LoadKeyFile(filePath string) ([]byte, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block id key file: %w", err)
+	}
+	if trimmed := strings.TrimSpace(string(b)); len(trimmed) == hex.EncodedLen(KeySize) {
+		if key, err := hex.DecodeString(trimmed); err == nil {
+			return key, nil
+		}
+	}
+	if len(b) != KeySize {
+		return nil, fmt.Errorf("block id key file %s must contain %d raw bytes or %d hex characters", filePath, KeySize, hex.EncodedLen(KeySize))
+	}
+	return b, nil
+}
+
+// New cfg 의 blockIds 설정에 맞는 Codec 을 만듦.
+func New(cfg *config.Config) (Codec, error) {
+	if cfg.BlockIDs.Mode != config.BlockIDModeOpaque {
+		return Raw{}, nil
+	}
+	key, err := LoadKeyFile(cfg.BlockIDs.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewOpaque(cfg.RootDir, key)
+}
@@ -0,0 +1,111 @@
+package blockid
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey() []byte {
+	return bytes.Repeat([]byte{0x42}, KeySize)
+}
+
+func TestOpaqueRoundTrip(t *testing.T) {
+	root := "/mnt/lustre/data"
+	c, err := NewOpaque(root, testKey())
+	if err != nil {
+		t.Fatalf("NewOpaque error: %v", err)
+	}
+	folder := filepath.Join(root, "project", "sample1")
+	id, err := c.Encode(folder)
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if strings.Contains(id, "sample1") || strings.Contains(id, "lustre") {
+		t.Errorf("id leaks path: %s", id)
+	}
+	again, _ := c.Encode(folder + "/")
+	if again != id {
+		t.Errorf("expected stable id, got %s and %s", id, again)
+	}
+	other, _ := c.Encode(filepath.Join(root, "project", "sample2"))
+	if other == id {
+		t.Errorf("different folders must not share an id")
+	}
+	got, err := c.Decode(id)
+	if err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+	if got != folder {
+		t.Errorf("expected %s, got %s", folder, got)
+	}
+
+	// 같은 키라도 RootDir 가 바뀌면 새 RootDir 기준으로 해석됨.
+	moved, _ := NewOpaque("/srv/data", testKey())
+	if got, _ := moved.Decode(id); got != "/srv/data/project/sample1" {
+		t.Errorf("expected path relative to new root, got %s", got)
+	}
+}
+
+func TestOpaqueRejectsBadIDs(t *testing.T) {
+	c, _ := NewOpaque("/data", testKey())
+	id, _ := c.Encode("/data/a")
+
+	// 마지막 문자는 패딩 비트만 바뀔 수 있으므로 가운데 문자를 바꿈.
+	tampered := []byte(id)
+	mid := len(tampered) / 2
+	if tampered[mid] == 'A' {
+		tampered[mid] = 'B'
+	} else {
+		tampered[mid] = 'A'
+	}
+	for _, bad := range []string{"", "!!!", "/data/a", string(tampered)} {
+		if _, err := c.Decode(bad); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Decode(%q): expected ErrInvalidID, got %v", bad, err)
+		}
+	}
+
+	otherKey, _ := NewOpaque("/data", bytes.Repeat([]byte{0x01}, KeySize))
+	if _, err := otherKey.Decode(id); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("expected ErrInvalidID with another key, got %v", err)
+	}
+
+	if _, err := c.Encode("/elsewhere/a"); err == nil {
+		t.Errorf("expected error for folder outside root")
+	}
+	if _, err := NewOpaque("/data", []byte("short")); err == nil {
+		t.Errorf("expected error for short key")
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	hexPath := filepath.Join(dir, "hex.key")
+	if err := os.WriteFile(hexPath, []byte(hex.EncodeToString(testKey())+"\n"), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	key, err := LoadKeyFile(hexPath)
+	if err != nil || !bytes.Equal(key, testKey()) {
+		t.Errorf("hex key: got %x, err %v", key, err)
+	}
+
+	rawPath := filepath.Join(dir, "raw.key")
+	if err := os.WriteFile(rawPath, testKey(), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	if key, err := LoadKeyFile(rawPath); err != nil || !bytes.Equal(key, testKey()) {
+		t.Errorf("raw key: got %x, err %v", key, err)
+	}
+
+	badPath := filepath.Join(dir, "bad.key")
+	if err := os.WriteFile(badPath, []byte("too short"), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	if _, err := LoadKeyFile(badPath); err == nil {
+		t.Errorf("expected error for bad key file")
+	}
+}
@@ -0,0 +1,73 @@
+package checksum
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/cespare/xxhash/v2"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// 파일 내용 checksum 알고리즘.
+const (
+	// None checksum 을 계산하지 않음. 크기 비교로만 변경을 찾던 이전 동작과 같음.
+	None = "none"
+	// SHA256 crypto/sha256. 느리지만 외부 도구(sha256sum)로 같은 값을 확인할 수 있음.
+	SHA256 = "sha256"
+	// XXHash xxh64. 암호학적 해시는 아니지만 sha256 보다 훨씬 빨라서 큰 FASTQ 에 알맞음.
+	XXHash = "xxhash"
+)
+
+// ErrUnsupportedAlgorithm 지원하지 않는 checksum 알고리즘.
+var ErrUnsupportedAlgorithm = errors.New("unsupported checksum algorithm")
+
+// Enabled algorithm 이 checksum 을 계산하는 값인지. 빈 문자열은 None 과 같음.
+func Enabled(algorithm string) bool {
+	return algorithm != "" && algorithm != None
+}
+
+// Validate algorithm 이 사용할 수 있는 값인지 확인함.
+func Validate(algorithm string) error {
+	switch algorithm {
+	case "", None, SHA256, XXHash:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q (expected %q, %q or %q)", ErrUnsupportedAlgorithm, algorithm, None, SHA256, XXHash)
+	}
+}
+
+// Algorithm "<algorithm>:<hex>" 형식의 checksum 에서 알고리즘 이름. 형식이 다르면 빈 문자열.
+func Algorithm(sum string) string {
+	algorithm, _, ok := strings.Cut(sum, ":")
+	if !ok {
+		return ""
+	}
+	return algorithm
+}
+
+// File path 의 내용을 algorithm 으로 계산해서 "<algorithm>:<hex>" 로 반환함. 파일 전체를 읽으므로 큰 파일은 오래 걸림.
+func File(path, algorithm string) (string, error) {
+	var h hash.Hash
+	switch algorithm {
+	case SHA256:
+		h = sha256.New()
+	case XXHash:
+		h = xxhash.New()
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return algorithm + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
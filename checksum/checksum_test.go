@@ -0,0 +1,53 @@
+package checksum
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1_R1.fastq")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	sum, err := File(path, SHA256)
+	if err != nil {
+		t.Fatalf("File(sha256): %v", err)
+	}
+	if want := "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; sum != want {
+		t.Errorf("expected %s, got %s", want, sum)
+	}
+	if Algorithm(sum) != SHA256 {
+		t.Errorf("expected algorithm %q, got %q", SHA256, Algorithm(sum))
+	}
+
+	sum, err = File(path, XXHash)
+	if err != nil {
+		t.Fatalf("File(xxhash): %v", err)
+	}
+	if !strings.HasPrefix(sum, "xxhash:") || len(sum) != len("xxhash:")+16 {
+		t.Errorf("unexpected xxhash checksum %q", sum)
+	}
+
+	if _, err := File(path, None); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("expected ErrUnsupportedAlgorithm for none, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, algorithm := range []string{"", None, SHA256, XXHash} {
+		if err := Validate(algorithm); err != nil {
+			t.Errorf("Validate(%q): %v", algorithm, err)
+		}
+	}
+	if err := Validate("md5"); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("expected md5 to be rejected, got %v", err)
+	}
+	if Enabled("") || Enabled(None) || !Enabled(XXHash) {
+		t.Errorf("unexpected Enabled results")
+	}
+}
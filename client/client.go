@@ -0,0 +1,295 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	globallog "github.com/seoyhaein/tori/log"
+	"github.com/seoyhaein/tori/protofile"
+	pb "github.com/seoyhaein/tori/protos"
+	"github.com/seoyhaein/tori/tlsutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var logger = globallog.Log
+
+// ErrBlockNotFound 캐시된 DataBlock 에 해당 block_id 가 없음.
+var ErrBlockNotFound = errors.New("block not found")
+
+// cacheFileName CacheDir 에 저장하는 DataBlock 파일 이름.
+const cacheFileName = "datablock.pb"
+
+// Options tori 서버 연결과 로컬 캐시 설정.
+type Options struct {
+	// Address gRPC 주소. "host:port" 또는 "unix:///run/tori.sock".
+	Address string
+	// TLS nil 이면 평문으로 연결함.
+	TLS *TLSOptions
+	// Token 설정하면 매 요청에 "authorization: Bearer <token>" 을 붙임.
+	Token string
+	// CacheDir 설정하면 마지막으로 받은 DataBlock 을 여기에 저장하고, 다음 실행 때 다시 읽어서 조건부 요청에 사용함.
+	CacheDir string
+	// DialOptions 기본 옵션 뒤에 붙는 추가 옵션. 테스트에서 bufconn dialer 를 넘길 때 사용함.
+	DialOptions []grpc.DialOption
+}
+
+// TLSOptions 서버 인증서 검증과 mTLS 클라이언트 인증서 설정. 비어 있는 값은 tlsutil.ClientConfig 와 같게 처리함.
+type TLSOptions struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+// bearerToken grpc.WithPerRPCCredentials 로 넘겨서 매 요청에 "authorization: Bearer <token>" 을 붙이는 credentials.
+// 서버 쪽 검증은 auth 패키지가 하지만, auth 는 config 를 import 하므로 클라이언트에는 따로 둠.
+type bearerToken struct {
+	token string
+	// secure 가 true 면 TLS 연결에서만 토큰을 보냄.
+	secure bool
+}
+
+func (b bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + b.token}, nil
+}
+
+func (b bearerToken) RequireTransportSecurity() bool {
+	return b.secure
+}
+
+// Client tori DataBlockService 클라이언트. 마지막으로 받은 DataBlock 을 메모리(와 CacheDir)에 들고 있으면서
+// updated_at 을 보내는 조건부 요청으로 바뀐 경우에만 새로 받음. 여러 goroutine 에서 함께 사용할 수 있음.
+type Client struct {
+	conn       *grpc.ClientConn
+	dataBlocks pb.DataBlockServiceClient
+	cacheFile  string
+
+	mu      sync.RWMutex
+	current *pb.DataBlock
+	blocks  map[string]*pb.FileBlock
+}
+
+// New opts 로 tori 에 연결함. 실제 연결은 첫 요청에서 이루어짐.
+// CacheDir 에 이전에 받은 DataBlock 이 있으면 읽어 둠. 읽을 수 없는 캐시는 무시하고 다음 Fetch 에서 전체를 받음.
+func New(opts Options) (*Client, error) {
+	if opts.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if opts.TLS != nil {
+		tlsCfg, err := tlsutil.ClientConfig(opts.TLS.CAFile, opts.TLS.CertFile, opts.TLS.KeyFile, opts.TLS.ServerName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up TLS: %w", err)
+		}
+		dialOpts[0] = grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg))
+	}
+	if opts.Token != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(bearerToken{token: opts.Token, secure: opts.TLS != nil}))
+	}
+	conn, err := grpc.NewClient(opts.Address, append(dialOpts, opts.DialOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for %s: %w", opts.Address, err)
+	}
+
+	c := &Client{conn: conn, dataBlocks: pb.NewDataBlockServiceClient(conn)}
+	if opts.CacheDir != "" {
+		if err := os.MkdirAll(opts.CacheDir, 0o700); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to create cache dir: %w", err)
+		}
+		c.cacheFile = filepath.Join(opts.CacheDir, cacheFileName)
+		c.loadCache()
+	}
+	return c, nil
+}
+
+// Close 서버 연결을 닫음.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Conn 다른 RPC(Search, RenderScript 등)를 직접 호출할 때 쓸 연결.
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+func (c *Client) loadCache() {
+	dataBlock := &pb.DataBlock{}
+	if err := protofile.Load(c.cacheFile, dataBlock); err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("ignoring unreadable datablock cache %s: %v", c.cacheFile, err)
+		}
+		return
+	}
+	c.set(dataBlock)
+}
+
+// saveCache 임시 파일에 쓴 뒤 rename 해서, 같은 CacheDir 을 쓰는 다른 프로세스가 쓰다 만 파일을 읽지 않게 함.
+func (c *Client) saveCache(dataBlock *pb.DataBlock) error {
+	data, err := protofile.Marshal(dataBlock, protofile.Gzip)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.cacheFile), cacheFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return os.Rename(tmp.Name(), c.cacheFile)
+}
+
+// set 캐시를 dataBlock 으로 교체함. 받은 DataBlock 은 바꾸지 않고 그대로 공유함.
+func (c *Client) set(dataBlock *pb.DataBlock) {
+	blocks := make(map[string]*pb.FileBlock, len(dataBlock.GetBlocks()))
+	for _, fb := range dataBlock.GetBlocks() {
+		blocks[fb.GetBlockId()] = fb
+	}
+	c.mu.Lock()
+	c.current, c.blocks = dataBlock, blocks
+	c.mu.Unlock()
+}
+
+// update 새로 받은 DataBlock 으로 캐시를 바꾸고 CacheDir 에 저장함. 저장 실패는 메모리 캐시에 영향을 주지 않음.
+func (c *Client) update(dataBlock *pb.DataBlock) {
+	c.set(dataBlock)
+	if c.cacheFile == "" {
+		return
+	}
+	if err := c.saveCache(dataBlock); err != nil {
+		logger.Warnf("failed to save datablock cache: %v", err)
+	}
+}
+
+// DataBlock 캐시된 DataBlock. 아직 받은 적이 없으면 nil. 반환값은 수정하면 안 됨.
+func (c *Client) DataBlock() *pb.DataBlock {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.current
+}
+
+// UpdatedAt 캐시된 DataBlock 의 updated_at. 아직 받은 적이 없으면 nil.
+func (c *Client) UpdatedAt() *timestamppb.Timestamp {
+	return c.DataBlock().GetUpdatedAt()
+}
+
+// request 캐시된 DataBlock 의 버전을 담은 요청. 서버는 content_hash, generation, updated_at 순서로 채워진 값을 씀.
+func (c *Client) request() *pb.GetDataBlockRequest {
+	current := c.DataBlock()
+	return &pb.GetDataBlockRequest{
+		CurrentUpdatedAt:   current.GetUpdatedAt(),
+		CurrentGeneration:  current.GetGeneration(),
+		CurrentContentHash: current.GetContentHash(),
+	}
+}
+
+// Fetch 캐시의 버전으로 GetDataBlock 을 호출해서, 서버가 새 DataBlock 을 보낸 경우에만 캐시를 바꿈.
+// 응답이 no_update 이거나 data 가 비어 있으면 캐시를 그대로 둠. full_resync 면 서버가 캐시의 버전을 모르는 것이므로
+// 캐시를 통째로 받은 DataBlock 으로 바꿈. changed 는 캐시가 바뀌었는지를 나타냄.
+func (c *Client) Fetch(ctx context.Context, opts ...grpc.CallOption) (changed bool, err error) {
+	resp, err := c.dataBlocks.GetDataBlock(ctx, c.request(), opts...)
+	if err != nil {
+		return false, err
+	}
+	if resp.GetNoUpdate() || resp.GetData() == nil {
+		return false, nil
+	}
+	if resp.GetFullResync() {
+		logger.Infof("server does not know cached datablock generation %d; replacing the cache", c.DataBlock().GetGeneration())
+	}
+	c.update(resp.GetData())
+	return true, nil
+}
+
+// FetchStream Fetch 와 같지만 StreamDataBlock 으로 FileBlock 을 나눠 받음. gRPC 메시지 크기 제한을 넘는 DataBlock 용.
+func (c *Client) FetchStream(ctx context.Context, opts ...grpc.CallOption) (changed bool, err error) {
+	dataBlock, err := streamDataBlock(ctx, c.dataBlocks, c.request(), opts...)
+	if err != nil || dataBlock == nil {
+		return false, err
+	}
+	c.update(dataBlock)
+	return true, nil
+}
+
+// Blocks 캐시된 DataBlock 의 FileBlock 목록.
+func (c *Client) Blocks() []*pb.FileBlock {
+	return c.DataBlock().GetBlocks()
+}
+
+// Block blockID 의 FileBlock. 없으면 ErrBlockNotFound.
+func (c *Client) Block(blockID string) (*pb.FileBlock, error) {
+	c.mu.RLock()
+	fb, ok := c.blocks[blockID]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrBlockNotFound, blockID)
+	}
+	return fb, nil
+}
+
+// Rows blockID 의 행 목록. 없으면 ErrBlockNotFound.
+func (c *Client) Rows(blockID string) ([]*pb.Row, error) {
+	fb, err := c.Block(blockID)
+	if err != nil {
+		return nil, err
+	}
+	return fb.GetRows(), nil
+}
+
+// Select blockID 의 rowNumber 번째 행, column 컬럼을 가리키는 PathSelection.
+func Select(blockID string, rowNumber int32, column string) *pb.PathSelection {
+	return &pb.PathSelection{BlockId: blockID, Row: &pb.PathSelection_RowNumber{RowNumber: rowNumber}, Column: column}
+}
+
+// SelectKey blockID 에서 row key 로 찾은 행, column 컬럼을 가리키는 PathSelection.
+func SelectKey(blockID, rowKey, column string) *pb.PathSelection {
+	return &pb.PathSelection{BlockId: blockID, Row: &pb.PathSelection_RowKey{RowKey: rowKey}, Column: column}
+}
+
+// Resolve selections 를 서버에서 실제 파일 경로로 바꿈. 결과는 selections 와 같은 순서이고,
+// 항목별 실패는 ResolvedPath.Error 에 담김. relative 면 RootDir 기준 상대 경로.
+func (c *Client) Resolve(ctx context.Context, relative bool, selections ...*pb.PathSelection) ([]*pb.ResolvedPath, error) {
+	resp, err := c.dataBlocks.ResolvePaths(ctx, &pb.ResolvePathsRequest{Selections: selections, Relative: relative})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetResults(), nil
+}
+
+// Watch WatchDataBlock 으로 DataBlock 이 바뀔 때마다 캐시를 바꾸고 fn 을 호출함.
+// 첫 메시지가 no_update 면 fn 을 호출하지 않음. ctx 가 취소되면 nil, 서버가 stream 을 닫으면 그 에러를 반환함.
+// fn 은 stream 을 받는 goroutine 에서 호출되므로 오래 걸리는 작업은 따로 넘겨야 함.
+func (c *Client) Watch(ctx context.Context, fn func(*pb.DataBlock)) error {
+	stream, err := c.dataBlocks.WatchDataBlock(ctx, c.request())
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if resp.GetNoUpdate() || resp.GetData() == nil {
+			continue
+		}
+		c.update(resp.GetData())
+		if fn != nil {
+			fn(resp.GetData())
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/seoyhaein/tori/config"
+	"github.com/seoyhaein/tori/protofile"
+	pb "github.com/seoyhaein/tori/protos"
+	"github.com/seoyhaein/tori/server"
+	"github.com/seoyhaein/tori/service"
+	"go/parser"
+	"go/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startServer rootDir 를 서비스하는 tori 서버를 bufconn 위에서 띄우고, 그 서버에 붙는 Options 를 반환함.
+func startServer(t *testing.T, rootDir string) (Options, *service.DataBlockCliService) {
+	t.Helper()
+	core, err := service.NewDataBlockCliService(nil, &config.Config{RootDir: rootDir})
+	if err != nil {
+		t.Fatalf("NewDataBlockCliService failed: %v", err)
+	}
+	lis := bufconn.Listen(1024 * 1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Serve(ctx, lis, core) }()
+	t.Cleanup(func() {
+		cancel()
+		<-errCh
+	})
+	return Options{
+		Address: "passthrough:///bufnet",
+		DialOptions: []grpc.DialOption{
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		},
+	}, core
+}
+
+func writeDataBlock(t *testing.T, rootDir string, dataBlock *pb.DataBlock) {
+	t.Helper()
+	if err := protofile.Save(filepath.Join(rootDir, "datablock.pb"), dataBlock, 0o644, protofile.None); err != nil {
+		t.Fatalf("failed to save datablock: %v", err)
+	}
+}
+
+func newClient(t *testing.T, opts Options) *Client {
+	t.Helper()
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestClientFetchAndCache(t *testing.T) {
+	rootDir := t.TempDir()
+	block := filepath.Join(rootDir, "sample1")
+	first := &pb.DataBlock{
+		UpdatedAt: timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Blocks: []*pb.FileBlock{{BlockId: block, ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{
+			{RowNumber: 0, Cells: map[string]string{"R1": filepath.Join(block, "a_R1.fastq")}},
+		}}},
+	}
+	writeDataBlock(t, rootDir, first)
+	opts, _ := startServer(t, rootDir)
+	opts.CacheDir = t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c := newClient(t, opts)
+	if c.DataBlock() != nil {
+		t.Fatal("expected empty cache before the first fetch")
+	}
+	if changed, err := c.Fetch(ctx); err != nil || !changed {
+		t.Fatalf("first Fetch: changed=%v err=%v", changed, err)
+	}
+	if !proto.Equal(c.DataBlock(), first) {
+		t.Errorf("unexpected cached DataBlock: %v", c.DataBlock())
+	}
+	if changed, err := c.Fetch(ctx); err != nil || changed {
+		t.Errorf("second Fetch should keep the cache: changed=%v err=%v", changed, err)
+	}
+
+	rows, err := c.Rows(block)
+	if err != nil || len(rows) != 1 {
+		t.Errorf("Rows(%s) = %v, %v", block, rows, err)
+	}
+	if _, err := c.Rows("missing"); !errors.Is(err, ErrBlockNotFound) {
+		t.Errorf("expected ErrBlockNotFound, got %v", err)
+	}
+	results, err := c.Resolve(ctx, true, Select(block, 0, "R1"))
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Resolve = %v, %v", results, err)
+	}
+	// 파일이 디스크에 없으므로 항목 에러로 돌아와야 함.
+	if results[0].GetError() == "" {
+		t.Errorf("expected resolve error for a missing file, got %v", results[0])
+	}
+
+	// 같은 CacheDir 을 쓰는 새 클라이언트는 디스크 캐시로 시작하고, 조건부 요청으로 다시 받지 않음.
+	c2 := newClient(t, opts)
+	if !proto.Equal(c2.DataBlock(), first) || len(c2.Blocks()) != 1 {
+		t.Fatalf("expected DataBlock from disk cache, got %v", c2.DataBlock())
+	}
+	if changed, err := c2.Fetch(ctx); err != nil || changed {
+		t.Errorf("Fetch with disk cache should be a no-op: changed=%v err=%v", changed, err)
+	}
+
+	second := proto.Clone(first).(*pb.DataBlock)
+	second.UpdatedAt = timestamppb.New(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+	second.Blocks = append(second.Blocks, &pb.FileBlock{BlockId: filepath.Join(rootDir, "sample2")})
+	writeDataBlock(t, rootDir, second)
+	if changed, err := c2.FetchStream(ctx); err != nil || !changed {
+		t.Fatalf("FetchStream after update: changed=%v err=%v", changed, err)
+	}
+	if len(c2.Blocks()) != 2 {
+		t.Errorf("expected 2 blocks after update, got %d", len(c2.Blocks()))
+	}
+}
+
+func TestClientWatch(t *testing.T) {
+	rootDir := t.TempDir()
+	writeDataBlock(t, rootDir, &pb.DataBlock{UpdatedAt: timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))})
+	opts, core := startServer(t, rootDir)
+	c := newClient(t, opts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan *pb.DataBlock, 4)
+	done := make(chan error, 1)
+	go func() { done <- c.Watch(ctx, func(d *pb.DataBlock) { received <- d }) }()
+
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not receive the initial DataBlock")
+	}
+
+	next := &pb.DataBlock{UpdatedAt: timestamppb.New(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)), Blocks: []*pb.FileBlock{{BlockId: "b2"}}}
+	writeDataBlock(t, rootDir, next)
+	if err := core.NotifyDataBlockChanged(context.Background()); err != nil {
+		t.Fatalf("NotifyDataBlockChanged failed: %v", err)
+	}
+	select {
+	case got := <-received:
+		if !proto.Equal(got, next) || !proto.Equal(c.DataBlock(), next) {
+			t.Errorf("unexpected pushed DataBlock: %v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("did not receive the pushed DataBlock")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch returned error after cancel: %v", err)
+	}
+}
+
+// TestNoConfigImport client 는 다른 팀이 import 하는 패키지라서, config.init() 이 config.json 을 읽다가
+// 프로세스를 끝내지 않도록 config 를 (간접적으로도) import 하면 안 됨.
+func TestNoConfigImport(t *testing.T) {
+	const module = "github.com/seoyhaein/tori/"
+	seen := make(map[string]bool)
+	var visit func(pkg string)
+	visit = func(pkg string) {
+		if seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		if pkg == module+"config" {
+			t.Fatalf("client imports %s", pkg)
+		}
+		dir := filepath.Join("..", strings.TrimPrefix(pkg, module))
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatalf("glob %s: %v", dir, err)
+		}
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+			if err != nil {
+				t.Fatalf("parse %s: %v", file, err)
+			}
+			for _, imp := range f.Imports {
+				if path := strings.Trim(imp.Path.Value, `"`); strings.HasPrefix(path, module) {
+					visit(path)
+				}
+			}
+		}
+	}
+	visit(module + "client")
+}
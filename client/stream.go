@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+)
+
+// ErrIncompleteStream StreamDataBlock 이 header 에 적힌 FileBlock 수를 다 보내기 전에 끝났거나 순서가 맞지 않음.
+var ErrIncompleteStream = errors.New("incomplete datablock stream")
+
+// ChunkReceiver StreamDataBlock 의 클라이언트 stream. 테스트에서 임의의 chunk 목록을 넘길 수 있도록 Recv 만 요구함.
+type ChunkReceiver interface {
+	Recv() (*pb.DataBlockChunk, error)
+}
+
+// StreamDataBlock StreamDataBlock RPC 를 호출해서 받은 chunk 들을 DataBlock 하나로 합침.
+// currentUpdatedAt 이 서버와 같으면 nil, nil 을 반환함(GetDataBlock 의 no_update 와 같음).
+func StreamDataBlock(ctx context.Context, c pb.DataBlockServiceClient, currentUpdatedAt *timestamppb.Timestamp, opts ...grpc.CallOption) (*pb.DataBlock, error) {
+	return streamDataBlock(ctx, c, &pb.GetDataBlockRequest{CurrentUpdatedAt: currentUpdatedAt}, opts...)
+}
+
+func streamDataBlock(ctx context.Context, c pb.DataBlockServiceClient, req *pb.GetDataBlockRequest, opts ...grpc.CallOption) (*pb.DataBlock, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	// 중간에 실패해서 반환하면 stream 을 정리함.
+	defer cancel()
+	stream, err := c.StreamDataBlock(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ReceiveDataBlock(stream)
+}
+
+// ReceiveDataBlock header 다음에 FileBlock 이 하나씩 오는 stream 을 DataBlock 으로 다시 합침.
+// header 가 no_update 면 nil, nil 을 반환함.
+func ReceiveDataBlock(stream ChunkReceiver) (*pb.DataBlock, error) {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: no header", ErrIncompleteStream)
+	}
+	if err != nil {
+		return nil, err
+	}
+	header := first.GetHeader()
+	if header == nil {
+		return nil, fmt.Errorf("%w: first message is not a header", ErrIncompleteStream)
+	}
+	if header.GetNoUpdate() {
+		return nil, nil
+	}
+
+	dataBlock := &pb.DataBlock{
+		UpdatedAt:   header.GetUpdatedAt(),
+		Generation:  header.GetGeneration(),
+		ContentHash: header.GetContentHash(),
+		Blocks:      make([]*pb.FileBlock, 0, header.GetTotalBlocks()),
+	}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		fb := chunk.GetBlock()
+		if fb == nil {
+			return nil, fmt.Errorf("%w: unexpected header after %d blocks", ErrIncompleteStream, len(dataBlock.Blocks))
+		}
+		dataBlock.Blocks = append(dataBlock.Blocks, fb)
+	}
+	if got, want := len(dataBlock.Blocks), int(header.GetTotalBlocks()); got != want {
+		return nil, fmt.Errorf("%w: received %d of %d blocks", ErrIncompleteStream, got, want)
+	}
+	return dataBlock, nil
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"testing"
+)
+
+// chunks 미리 정한 chunk 들을 차례로 돌려주는 ChunkReceiver.
+type chunks []*pb.DataBlockChunk
+
+func (c *chunks) Recv() (*pb.DataBlockChunk, error) {
+	if len(*c) == 0 {
+		return nil, io.EOF
+	}
+	next := (*c)[0]
+	*c = (*c)[1:]
+	return next, nil
+}
+
+func header(total int32) *pb.DataBlockChunk {
+	return &pb.DataBlockChunk{Chunk: &pb.DataBlockChunk_Header{Header: &pb.DataBlockHeader{UpdatedAt: timestamppb.Now(), TotalBlocks: total}}}
+}
+
+func block(id string) *pb.DataBlockChunk {
+	return &pb.DataBlockChunk{Chunk: &pb.DataBlockChunk_Block{Block: &pb.FileBlock{BlockId: id}}}
+}
+
+func TestReceiveDataBlock(t *testing.T) {
+	stream := &chunks{header(2), block("a"), block("b")}
+	dataBlock, err := ReceiveDataBlock(stream)
+	if err != nil {
+		t.Fatalf("ReceiveDataBlock failed: %v", err)
+	}
+	if len(dataBlock.GetBlocks()) != 2 || dataBlock.GetBlocks()[1].GetBlockId() != "b" || dataBlock.GetUpdatedAt() == nil {
+		t.Errorf("unexpected DataBlock: %v", dataBlock)
+	}
+
+	noUpdate := &chunks{{Chunk: &pb.DataBlockChunk_Header{Header: &pb.DataBlockHeader{NoUpdate: true}}}}
+	if dataBlock, err := ReceiveDataBlock(noUpdate); dataBlock != nil || err != nil {
+		t.Errorf("expected nil, nil for no_update, got %v, %v", dataBlock, err)
+	}
+
+	broken := map[string]*chunks{
+		"empty":          {},
+		"missing header": {block("a")},
+		"short":          {header(3), block("a"), block("b")},
+		"second header":  {header(1), header(1)},
+	}
+	for name, stream := range broken {
+		if _, err := ReceiveDataBlock(stream); !errors.Is(err, ErrIncompleteStream) {
+			t.Errorf("%s: expected ErrIncompleteStream, got %v", name, err)
+		}
+	}
+}
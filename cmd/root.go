@@ -4,13 +4,22 @@ import (
 	"database/sql"
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/seoyhaein/tori/auth"
 	c "github.com/seoyhaein/tori/config"
 	dbUtils "github.com/seoyhaein/tori/db"
 	globallog "github.com/seoyhaein/tori/log"
+	"github.com/seoyhaein/tori/search"
+	"github.com/seoyhaein/tori/server"
 	"github.com/seoyhaein/tori/service"
+	"github.com/seoyhaein/tori/tlsutil"
+	"github.com/seoyhaein/tori/watch"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 )
 
 var (
@@ -38,7 +47,9 @@ func Execute() error {
 				return fmt.Errorf("DB 초기화 실패: %w", err)
 			}
 			// cfg 는 config.go 에서 init 에서 생성됨.
-			cliSvc = service.NewDataBlockCliService(database, cfg)
+			if cliSvc, err = service.NewDataBlockCliService(database, cfg); err != nil {
+				return fmt.Errorf("서비스 초기화 실패: %w", err)
+			}
 			return nil
 		},
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
@@ -57,28 +68,70 @@ func Execute() error {
 		resetCmd(),
 		snapshotCmd(),
 		syncCmd(),
+		watchCmd(),
+		tokenCmd(),
+		certsCmd(),
+		renderCmd(),
+		searchCmd(),
 	)
 
 	return root.Execute()
 }
 
-// TODO 일단 추후 구현.
+// serveCmd 는 DataBlockService 를 gRPC 로 노출하고, SIGINT/SIGTERM 수신 시 graceful shutdown 처리함.
+// 서버 설정은 config 의 server 항목을 따르고, 플래그를 주면 해당 값만 덮어씀.
 func serveCmd() *cobra.Command {
-	return &cobra.Command{
+	var address, unixSocket, httpAddress, metricsAddress string
+	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "gRPC 서버 실행",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			/*ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			flags := cmd.Flags()
+			if flags.Changed("addr") {
+				cfg.Server.Address, cfg.Server.UnixSocket = address, ""
+			}
+			if flags.Changed("unix-socket") {
+				cfg.Server.Address, cfg.Server.UnixSocket = "", unixSocket
+			}
+			if flags.Changed("http-addr") {
+				cfg.Server.HTTPAddress = httpAddress
+			}
+			if flags.Changed("metrics-addr") {
+				cfg.Server.MetricsAddress = metricsAddress
+			}
+			cfg.Server = cfg.Server.WithDefaults()
+			if err := cfg.Server.Validate(); err != nil {
+				return fmt.Errorf("서버 설정 오류: %w", err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
 			defer stop()
-			logger.Info("gRPC 서버 시작 :50053")
-			return api.ServeGRPC(ctx, database)*/
-			return nil
+			// 한쪽 서버가 실패하면 다른 쪽도 함께 종료함.
+			g, ctx := errgroup.WithContext(ctx)
+			g.Go(func() error { return server.ServeGRPC(ctx, cliSvc) })
+			if cfg.Server.HTTPAddress != "" {
+				g.Go(func() error { return server.ServeHTTP(ctx, cliSvc) })
+			}
+			if cfg.Server.MetricsAddress != "" {
+				g.Go(func() error { return server.ServeMetrics(ctx, cfg.Server) })
+			}
+			return g.Wait()
 		},
 	}
+	cmd.Flags().StringVar(&address, "addr", "", "gRPC 서버 listen 주소 (config 의 server.address 를 덮어씀)")
+	cmd.Flags().StringVar(&unixSocket, "unix-socket", "", "gRPC 서버 Unix socket 경로 (config 의 server.unixSocket 을 덮어씀)")
+	cmd.Flags().StringVar(&httpAddress, "http-addr", "", "HTTP/JSON 게이트웨이 listen 주소")
+	cmd.Flags().StringVar(&metricsAddress, "metrics-addr", "", "/metrics 만 노출할 listen 주소 (인증 없음)")
+	cmd.MarkFlagsMutuallyExclusive("addr", "unix-socket")
+	return cmd
 }
 
 func dumpCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		ui      bool
+		columns []string
+	)
+	cmd := &cobra.Command{
 		Use:   "dump [output-file]",
 		Short: "데이터블록을 텍스트 포맷으로 파일 저장",
 		Args:  cobra.ExactArgs(1),
@@ -89,6 +142,12 @@ func dumpCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("데이터블록 로드 실패: %w", err)
 			}
+			// UI 용이면 서버 경로를 모두 빼고 UI 에 필요한 정보만 남김.
+			if ui {
+				if db, err = cliSvc.ProjectForUI(db, columns); err != nil {
+					return fmt.Errorf("UI 용 변환 실패: %w", err)
+				}
+			}
 			if err := service.SaveDataBlockToTextFile(out, db); err != nil {
 				return fmt.Errorf("텍스트 저장 실패: %w", err)
 			}
@@ -96,6 +155,86 @@ func dumpCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&ui, "ui", false, "서버 경로를 뺀 UI 용 DataBlock 으로 저장")
+	cmd.Flags().StringSliceVar(&columns, "column", nil, "--ui 일 때 남길 컬럼 (비어 있으면 모든 컬럼)")
+	return cmd
+}
+
+// renderCmd 는 user script 템플릿의 자리표시자를 현재 DataBlock 의 파일 경로로 채웁니다.
+func renderCmd() *cobra.Command {
+	var (
+		output   string
+		relative bool
+	)
+	cmd := &cobra.Command{
+		Use:   "render [template-file]",
+		Short: "user script 템플릿을 실제 파일 경로로 렌더링",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("템플릿 읽기 실패: %w", err)
+			}
+			script, err := cliSvc.RenderScript(cmd.Context(), string(text), relative)
+			if err != nil {
+				return fmt.Errorf("렌더링 실패: %w", err)
+			}
+			if output == "" {
+				_, err = fmt.Fprint(cmd.OutOrStdout(), script)
+				return err
+			}
+			if err := os.WriteFile(output, []byte(script), 0o755); err != nil {
+				return fmt.Errorf("스크립트 저장 실패: %w", err)
+			}
+			logger.Infof("Rendered script to %s", output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "렌더링 결과를 저장할 파일 (기본: stdout)")
+	cmd.Flags().BoolVar(&relative, "relative", false, "RootDir 기준 상대 경로로 채움")
+	return cmd
+}
+
+// searchCmd 는 DataBlock 에서 조건에 맞는 FileBlock 과 행을 찾아 출력합니다.
+func searchCmd() *cobra.Command {
+	var q search.Query
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "DataBlock 검색 (block ID prefix, 컬럼, 파일 이름 패턴, 행 수)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := cliSvc.Search(cmd.Context(), q)
+			if err != nil {
+				return fmt.Errorf("검색 실패: %w", err)
+			}
+			out := cmd.OutOrStdout()
+			for _, fb := range result.Blocks {
+				fmt.Fprintf(out, "%s (%d rows) %v\n", fb.GetBlockId(), len(fb.GetRows()), fb.GetColumnHeaders())
+				for _, r := range fb.GetRows() {
+					fmt.Fprintf(out, "  #%d", r.GetRowNumber())
+					for _, h := range fb.GetColumnHeaders() {
+						fmt.Fprintf(out, " %s=%s", h, r.GetCells()[h])
+					}
+					fmt.Fprintln(out)
+				}
+			}
+			fmt.Fprintf(out, "%d of %d blocks\n", len(result.Blocks), result.TotalBlocks)
+			if result.NextPageToken != "" {
+				fmt.Fprintf(out, "next page: --page-token %s\n", result.NextPageToken)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&q.BlockIDPrefix, "prefix", "", "block ID(RootDir 기준 상대 경로) prefix, opaque 모드에서는 완전한 block ID")
+	f.StringSliceVar(&q.Headers, "header", nil, "FileBlock 이 모두 가져야 하는 컬럼")
+	f.StringVar(&q.CellGlob, "glob", "", "셀(파일 이름) glob 패턴")
+	f.StringVar(&q.CellRegex, "regex", "", "셀(파일 이름) 정규식")
+	f.StringVar(&q.Column, "column", "", "셀 조건을 적용할 컬럼")
+	f.IntVar(&q.MinRows, "min-rows", 0, "최소 행 수")
+	f.IntVar(&q.MaxRows, "max-rows", 0, "최대 행 수 (0 이면 제한 없음)")
+	f.IntVar(&q.PageSize, "limit", search.DefaultPageSize, "한 페이지의 FileBlock 수")
+	f.StringVar(&q.PageToken, "page-token", "", "이전 검색 결과의 next page 토큰")
+	return cmd
 }
 
 func resetCmd() *cobra.Command {
@@ -130,11 +269,12 @@ func snapshotCmd() *cobra.Command {
 
 // syncCmd 는 DB 스냅샷과 실제 폴더를 비교·동기화합니다.
 func syncCmd() *cobra.Command {
-	return &cobra.Command{
+	var force bool
+	cmd := &cobra.Command{
 		Use:   "sync",
 		Short: "스냅샷과 실제 폴더 비교 및 동기화",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			updated, err := cliSvc.SyncFolders(cmd.Context())
+			updated, err := cliSvc.SyncFoldersWithOptions(cmd.Context(), dbUtils.SyncOptions{Force: force})
 			if err != nil {
 				return fmt.Errorf("동기화 실패: %w", err)
 			}
@@ -146,4 +286,154 @@ func syncCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&force, "force", false, "변경 사항이 없어도 모든 FileBlock 과 DataBlock 을 다시 생성 (rule.json 수정 후 사용)")
+	return cmd
+}
+
+// watchCmd 는 RootDir 의 변경을 감시하다가, 변경이 quiet period 동안 멈추면 sync 를 실행합니다.
+// 설정은 config 의 watch 항목을 따르고, 플래그를 주면 해당 값만 덮어씀. SIGINT/SIGTERM 을 받으면 실행 중인 sync 를 마치고 종료함.
+func watchCmd() *cobra.Command {
+	var (
+		backend, statusAddress            string
+		quietPeriod, maxDelay, pollPeriod time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "폴더 변경을 감시하면서 자동으로 동기화",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			if flags.Changed("backend") {
+				cfg.Watch.Backend = backend
+			}
+			if flags.Changed("quiet-period") {
+				cfg.Watch.QuietPeriod = c.Duration(quietPeriod)
+			}
+			if flags.Changed("max-delay") {
+				cfg.Watch.MaxDelay = c.Duration(maxDelay)
+			}
+			if flags.Changed("poll-interval") {
+				cfg.Watch.PollInterval = c.Duration(pollPeriod)
+			}
+			if flags.Changed("status-addr") {
+				cfg.Watch.StatusAddress = statusAddress
+			}
+			cfg.Watch = cfg.Watch.WithDefaults()
+			if err := cfg.Watch.Validate(); err != nil {
+				return fmt.Errorf("watch 설정 오류: %w", err)
+			}
+			w, err := watch.New(cliSvc)
+			if err != nil {
+				return fmt.Errorf("watch 시작 실패: %w", err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			g, ctx := errgroup.WithContext(ctx)
+			g.Go(func() error { return w.Run(ctx) })
+			if cfg.Watch.StatusAddress != "" {
+				g.Go(func() error { return server.ServeStatus(ctx, cfg.Watch.StatusAddress, w.Handler(), cfg.Server) })
+			}
+			return g.Wait()
+		},
+	}
+	cmd.Flags().StringVar(&backend, "backend", "", "변경 감지 방식 \"poll\" 또는 \"inotify\" (config 의 watch.backend 를 덮어씀)")
+	cmd.Flags().DurationVar(&quietPeriod, "quiet-period", 0, "마지막 변경 후 sync 까지 기다리는 시간")
+	cmd.Flags().DurationVar(&maxDelay, "max-delay", 0, "변경이 계속되어도 첫 변경 후 이 시간 안에 sync")
+	cmd.Flags().DurationVar(&pollPeriod, "poll-interval", 0, "poll backend 의 스캔 간격")
+	cmd.Flags().StringVar(&statusAddress, "status-addr", "", "/status, /healthz, /metrics 를 노출할 listen 주소")
+	return cmd
+}
+
+// tokenCmd 는 설정된 hmacSecretFile 로 서명한 bearer 토큰을 발급합니다.
+func tokenCmd() *cobra.Command {
+	var (
+		subject string
+		roles   []string
+		ttl     time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "HMAC 서명 bearer 토큰 발급",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.Auth.HMACSecretFile == "" {
+				return fmt.Errorf("auth.hmacSecretFile 이 설정되어 있지 않음")
+			}
+			h, err := auth.LoadHMACSecretFile(cfg.Auth.HMACSecretFile)
+			if err != nil {
+				return fmt.Errorf("secret 로드 실패: %w", err)
+			}
+			claims := auth.Claims{Subject: subject, Roles: roles}
+			if ttl > 0 {
+				claims.ExpiresAt = time.Now().Add(ttl).Unix()
+			}
+			token, err := h.Sign(claims)
+			if err != nil {
+				return fmt.Errorf("토큰 서명 실패: %w", err)
+			}
+			fmt.Println(token)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&subject, "subject", "", "토큰 소유자 이름 (로그에 표시)")
+	cmd.Flags().StringSliceVar(&roles, "role", []string{"reader"}, "토큰에 부여할 role")
+	cmd.Flags().DurationVar(&ttl, "ttl", 24*time.Hour, "토큰 유효 기간 (0 이면 만료 없음)")
+	_ = cmd.MarkFlagRequired("subject")
+	return cmd
+}
+
+// certsCmd 는 로컬 개발/테스트용 CA 와 서버, 클라이언트 인증서를 만듦.
+// 만든 파일은 server.tls 와 auth.clientCerts 에 그대로 지정해서 사용할 수 있음.
+func certsCmd() *cobra.Command {
+	var (
+		outDir   string
+		hosts    []string
+		clients  []string
+		validFor time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "certs",
+		Short: "개발용 CA, 서버, 클라이언트(mTLS) 인증서 생성",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ca, err := tlsutil.GenerateCA("tori-dev-ca", validFor)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(outDir, 0o700); err != nil {
+				return fmt.Errorf("출력 폴더 생성 실패: %w", err)
+			}
+			caFile := filepath.Join(outDir, "ca.crt")
+			if err := os.WriteFile(caFile, ca.CertPEM, 0o644); err != nil {
+				return fmt.Errorf("CA 인증서 저장 실패: %w", err)
+			}
+			fmt.Println(caFile)
+
+			certPEM, keyPEM, err := ca.Issue("tori-server", hosts, false, validFor)
+			if err != nil {
+				return err
+			}
+			certFile, keyFile, err := tlsutil.WritePair(outDir, "server", certPEM, keyPEM)
+			if err != nil {
+				return err
+			}
+			fmt.Println(certFile, keyFile)
+
+			for _, name := range clients {
+				certPEM, keyPEM, err := ca.Issue(name, nil, true, validFor)
+				if err != nil {
+					return err
+				}
+				certFile, keyFile, err := tlsutil.WritePair(outDir, name, certPEM, keyPEM)
+				if err != nil {
+					return err
+				}
+				fmt.Println(certFile, keyFile)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&outDir, "out", "certs", "인증서를 저장할 폴더")
+	cmd.Flags().StringSliceVar(&hosts, "host", []string{"localhost", "127.0.0.1"}, "서버 인증서의 SAN (DNS 이름 또는 IP)")
+	cmd.Flags().StringSliceVar(&clients, "client", nil, "클라이언트 인증서 CN (auth.clientCerts 의 key 로 사용)")
+	cmd.Flags().DurationVar(&validFor, "valid-for", 365*24*time.Hour, "인증서 유효 기간")
+	return cmd
 }
@@ -3,18 +3,80 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/seoyhaein/tori/checksum"
 	globallog "github.com/seoyhaein/tori/log"
+	"github.com/seoyhaein/tori/protofile"
 	"os"
 	"path/filepath"
 	"runtime"
 )
 
 type Config struct {
-	RootDir           string   `json:"rootDir"`           // lustre-client 마운트된 폴더로 사용할 예정.
-	FoldersExclusions []string `json:"foldersExclusions"` // 제외할 폴더들.
-	FilesExclusions   []string `json:"filesExclusions"`   // ["*.json", "invalid_files", "*.csv", "*.pb"]
+	RootDir           string        `json:"rootDir"`           // lustre-client 마운트된 폴더로 사용할 예정.
+	FoldersExclusions []string      `json:"foldersExclusions"` // 제외할 폴더들.
+	FilesExclusions   []string      `json:"filesExclusions"`   // ["*.json", "invalid_files_*.txt", "*.csv", "*.pb"]. *, ?, [ 가 들어가면 glob 패턴.
+	DeltaHistory      int           `json:"deltaHistory"`      // delta 계산을 위해 메모리에 보관할 DataBlock 버전 수.
+	Auth              AuthConfig    `json:"auth"`              // gRPC 인증/인가 설정. 토큰 소스가 하나도 없으면 인증을 사용하지 않음.
+	BlockIDs          BlockIDConfig `json:"blockIds"`          // 클라이언트에게 보여줄 block ID 형식.
+	Server            ServerConfig  `json:"server"`            // serve 명령의 gRPC/HTTP 서버 설정.
+	Compression       string        `json:"compression"`       // *files.pb, datablock.pb 저장 시 압축 방식 ("none", "gzip"). 읽을 때는 자동 판별.
+	Watch             WatchConfig   `json:"watch"`             // watch 명령의 변경 감지와 자동 sync 설정.
+	MaxDepth          int           `json:"maxDepth"`          // RootDir 아래에서 폴더를 찾을 깊이. 0 이면 1 (RootDir 바로 아래 폴더만).
+	Checksum          string        `json:"checksum"`          // 파일 내용 checksum 알고리즘 ("none", "sha256", "xxhash"). 크기가 같은 수정도 찾음.
 }
 
+const (
+	// BlockIDModeRaw block_id 로 폴더 절대 경로를 그대로 내보냄. 기존 클라이언트 호환용.
+	BlockIDModeRaw = "raw"
+	// BlockIDModeOpaque RootDir 기준 상대 경로를 서버 키로 암호화한 토큰을 block_id 로 내보냄.
+	BlockIDModeOpaque = "opaque"
+)
+
+// BlockIDConfig block ID 코덱 설정. mode 를 비워 두면 keyFile 이 있을 때 opaque, 없으면 raw.
+type BlockIDConfig struct {
+	Mode    string `json:"mode"`    // "raw" 또는 "opaque"
+	KeyFile string `json:"keyFile"` // opaque 모드의 32 바이트 서버 키 파일 (hex 가능)
+}
+
+// AuthConfig gRPC API 의 인증(토큰)과 인가(role 별 허용 메서드) 설정.
+type AuthConfig struct {
+	// TokenFile 정적 토큰 파일 경로. 한 줄에 "<token> <subject> <role>[,<role>...]" 형식.
+	TokenFile string `json:"tokenFile"`
+	// HMACSecretFile HMAC 서명 bearer 토큰 검증에 쓸 secret 파일 경로.
+	HMACSecretFile string `json:"hmacSecretFile"`
+	// ClientCerts mTLS 클라이언트 인증서의 CN 또는 SAN(DNS, URI, email, IP) → role 목록.
+	// server.tls.clientCAFile 로 검증된 인증서에만 적용됨.
+	ClientCerts map[string][]string `json:"clientCerts"`
+	// Roles role 이름 → 호출 가능한 메서드 목록. 메서드는 "DataBlockService/GetDataBlock",
+	// "DataBlockService/*", "*" 형식. 비어 있으면 DefaultRoles 를 사용함.
+	Roles map[string][]string `json:"roles"`
+}
+
+// Enabled 토큰 소스나 클라이언트 인증서 매핑이 하나라도 설정되어 있으면 true.
+func (a AuthConfig) Enabled() bool {
+	return a.TokenFile != "" || a.HMACSecretFile != "" || len(a.ClientCerts) > 0
+}
+
+// DefaultRoles roles 가 설정되지 않았을 때 사용하는 기본 role. reader 는 조회용 RPC 만, admin 은 모든 RPC 를 호출할 수 있음.
+func DefaultRoles() map[string][]string {
+	return map[string][]string{
+		"reader": {
+			"DataBlockService/GetDataBlock",
+			"DataBlockService/WatchDataBlock",
+			"DataBlockService/GetDataBlockDelta",
+			"DataBlockService/ResolvePaths",
+			"DataBlockService/RenderScript",
+			"DataBlockService/Search",
+			"DataBlockService/GetUIDataBlock",
+			"DataBlockService/StreamDataBlock",
+		},
+		"admin": {"*"},
+	}
+}
+
+// DefaultDeltaHistory deltaHistory 가 설정되지 않았을 때 보관할 DataBlock 버전 수.
+const DefaultDeltaHistory = 16
+
 var (
 	GlobalConfig *Config
 	logger       = globallog.Log
@@ -64,14 +126,71 @@ func LoadConfig(filename string) (cfg *Config, err error) {
 		return nil, fmt.Errorf("missing 'rootDir' in configuration")
 	}
 
+	if config.DeltaHistory < 0 {
+		return nil, fmt.Errorf("invalid 'deltaHistory' %d: must not be negative", config.DeltaHistory)
+	}
+	if config.DeltaHistory == 0 {
+		config.DeltaHistory = DefaultDeltaHistory
+	}
+
+	if len(config.Auth.Roles) == 0 {
+		config.Auth.Roles = DefaultRoles()
+	}
+	for role, methods := range config.Auth.Roles {
+		if len(methods) == 0 {
+			return nil, fmt.Errorf("invalid 'auth.roles': role %q has no methods", role)
+		}
+	}
+
+	switch config.BlockIDs.Mode {
+	case "":
+		config.BlockIDs.Mode = BlockIDModeRaw
+		if config.BlockIDs.KeyFile != "" {
+			config.BlockIDs.Mode = BlockIDModeOpaque
+		}
+	case BlockIDModeRaw:
+	case BlockIDModeOpaque:
+		if config.BlockIDs.KeyFile == "" {
+			return nil, fmt.Errorf("missing 'blockIds.keyFile' for opaque block ids")
+		}
+	default:
+		return nil, fmt.Errorf("invalid 'blockIds.mode' %q: must be %q or %q", config.BlockIDs.Mode, BlockIDModeRaw, BlockIDModeOpaque)
+	}
+
+	config.Server = config.Server.WithDefaults()
+	if err := config.Server.Validate(); err != nil {
+		return nil, err
+	}
+	config.Watch = config.Watch.WithDefaults()
+	if err := config.Watch.Validate(); err != nil {
+		return nil, err
+	}
+	if config.MaxDepth < 0 {
+		return nil, fmt.Errorf("invalid 'maxDepth' %d: must not be negative", config.MaxDepth)
+	}
+	if err := checksum.Validate(config.Checksum); err != nil {
+		return nil, fmt.Errorf("invalid 'checksum': %w", err)
+	}
+	if err := protofile.Validate(config.Compression); err != nil {
+		return nil, fmt.Errorf("invalid 'compression': %w", err)
+	}
+	if len(config.Auth.ClientCerts) > 0 && config.Server.TLS.ClientCAFile == "" {
+		return nil, fmt.Errorf("'auth.clientCerts' requires 'server.tls.clientCAFile' to verify client certificates")
+	}
+
 	// Exclusions 가 비어있으면 기본값 설정
 	if len(config.FilesExclusions) == 0 {
-		config.FilesExclusions = []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+		config.FilesExclusions = DefaultFilesExclusions()
 	}
 
 	return &config, nil
 }
 
+// DefaultFilesExclusions filesExclusions 를 비워 두었을 때 쓰는 파일 제외 목록. sync 가 쓰는 파일도 포함함.
+func DefaultFilesExclusions() []string {
+	return []string{"*.json", "invalid_files_*.txt", "*.csv", "*.pb"}
+}
+
 // defaultConfigPath 는 config.go 파일 기준으로 config.json 파일의 경로를 유추한다.
 func defaultConfigPath() string {
 	_, filename, _, ok := runtime.Caller(0)
@@ -3,8 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func writeTempConfig(t *testing.T, data string) string {
@@ -37,8 +39,8 @@ func TestLoadConfig_DefaultExclusions(t *testing.T) {
 	if err != nil {
 		t.Fatalf("LoadConfig error: %v", err)
 	}
-	if len(cfg.FilesExclusions) == 0 {
-		t.Errorf("expected default exclusions")
+	if !slices.Equal(cfg.FilesExclusions, DefaultFilesExclusions()) {
+		t.Errorf("expected default exclusions, got %v", cfg.FilesExclusions)
 	}
 }
 
@@ -49,9 +51,180 @@ func TestLoadConfig_MissingRootDir(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_DeltaHistory(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp"}`))
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	if cfg.DeltaHistory != DefaultDeltaHistory {
+		t.Errorf("expected default delta history %d, got %d", DefaultDeltaHistory, cfg.DeltaHistory)
+	}
+	if _, err := LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","deltaHistory":-1}`)); err == nil {
+		t.Errorf("expected error for negative deltaHistory")
+	}
+}
+
+func TestLoadConfig_MaxDepth(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","maxDepth":3}`))
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	if cfg.MaxDepth != 3 {
+		t.Errorf("expected maxDepth 3, got %d", cfg.MaxDepth)
+	}
+	if _, err := LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","maxDepth":-1}`)); err == nil || !strings.Contains(err.Error(), "'maxDepth'") {
+		t.Errorf("expected error for negative maxDepth, got %v", err)
+	}
+}
+
 func TestDefaultConfigPath(t *testing.T) {
 	path := defaultConfigPath()
 	if !strings.HasSuffix(path, filepath.Join("config", "config.json")) {
 		t.Errorf("unexpected default path: %s", path)
 	}
 }
+
+func TestLoadConfig_Auth(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp"}`))
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	if cfg.Auth.Enabled() {
+		t.Errorf("auth should be disabled without token sources")
+	}
+	if _, ok := cfg.Auth.Roles["reader"]; !ok {
+		t.Errorf("expected default roles, got %v", cfg.Auth.Roles)
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","auth":{"tokenFile":"/etc/tori/tokens","roles":{"ops":["DBApisService/*"]}}}`))
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	if !cfg.Auth.Enabled() || len(cfg.Auth.Roles) != 1 {
+		t.Errorf("unexpected auth config: %+v", cfg.Auth)
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","auth":{"roles":{"empty":[]}}}`)); err == nil {
+		t.Errorf("expected error for role without methods")
+	}
+}
+
+func TestLoadConfig_BlockIDs(t *testing.T) {
+	tests := []struct {
+		data     string
+		wantMode string
+		wantErr  bool
+	}{
+		{`{"rootDir":"/tmp"}`, BlockIDModeRaw, false},
+		{`{"rootDir":"/tmp","blockIds":{"keyFile":"/etc/tori/blockid.key"}}`, BlockIDModeOpaque, false},
+		{`{"rootDir":"/tmp","blockIds":{"mode":"raw","keyFile":"/etc/tori/blockid.key"}}`, BlockIDModeRaw, false},
+		{`{"rootDir":"/tmp","blockIds":{"mode":"opaque"}}`, "", true},
+		{`{"rootDir":"/tmp","blockIds":{"mode":"secret"}}`, "", true},
+	}
+	for _, tt := range tests {
+		cfg, err := LoadConfig(writeTempConfig(t, tt.data))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", tt.data)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: LoadConfig error: %v", tt.data, err)
+		}
+		if cfg.BlockIDs.Mode != tt.wantMode {
+			t.Errorf("%s: expected mode %q, got %q", tt.data, tt.wantMode, cfg.BlockIDs.Mode)
+		}
+	}
+}
+
+func TestLoadConfig_Server(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp"}`))
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	if cfg.Server.Address != DefaultServerAddress || cfg.Server.ShutdownGracePeriod.D() != DefaultShutdownGracePeriod ||
+		cfg.Server.MaxRecvMsgSize != DefaultMaxRecvMsgSize || cfg.Server.TLS.Enabled() {
+		t.Errorf("unexpected server defaults: %+v", cfg.Server)
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","server":{"unixSocket":"/run/tori.sock",
+		"maxRecvMsgSize":1024,"keepalive":{"time":"1m","minTime":"10s","permitWithoutStream":true},"shutdownGracePeriod":"5s"}}`))
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	if cfg.Server.Address != "" || cfg.Server.UnixSocket != "/run/tori.sock" || cfg.Server.MaxRecvMsgSize != 1024 ||
+		cfg.Server.Keepalive.Time.D() != time.Minute || cfg.Server.ShutdownGracePeriod.D() != 5*time.Second {
+		t.Errorf("unexpected server config: %+v", cfg.Server)
+	}
+
+	certFile := filepath.Join(t.TempDir(), "server.crt")
+	if err := os.WriteFile(certFile, []byte("cert"), 0o600); err != nil {
+		t.Fatalf("failed to write cert: %v", err)
+	}
+	tests := []struct {
+		server  string
+		wantErr string
+	}{
+		{`{"address":":1","unixSocket":"/run/tori.sock"}`, "mutually exclusive"},
+		{`{"address":"localhost"}`, "server.address"},
+		{`{"httpAddress":"8080"}`, "server.httpAddress"},
+		{`{"maxSendMsgSize":-1}`, "server.maxSendMsgSize"},
+		{`{"shutdownGracePeriod":"-1s"}`, "server.shutdownGracePeriod"},
+		{`{"keepalive":{"timeout":"soon"}}`, "invalid duration"},
+		{`{"keepalive":{"timeout":20}}`, "duration must be a string"},
+		{`{"tls":{"certFile":"` + certFile + `"}}`, "must be set together"},
+		{`{"tls":{"clientCAFile":"` + certFile + `"}}`, "requires"},
+		{`{"compression":"br"}`, "server.compression"},
+	}
+	for _, tt := range tests {
+		_, err := LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","server":`+tt.server+`}`))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.server, tt.wantErr, err)
+		}
+	}
+
+	// 파일이 있는지는 serve 를 시작할 때 확인하므로 설정을 읽을 때는 문법만 봄.
+	cfg, err = LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","server":{"tls":{"certFile":"`+certFile+`","keyFile":"/nonexistent/server.key"}}}`))
+	if err != nil || cfg.Server.TLS.KeyFile != "/nonexistent/server.key" {
+		t.Errorf("expected missing TLS files to be accepted at load time, got %v", err)
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","watch":{"backend":"poll","quietPeriod":"2s"}}`))
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	if cfg.Watch.QuietPeriod.D() != 2*time.Second || cfg.Watch.MaxDelay.D() != DefaultWatchMaxDelay || cfg.Watch.PollInterval.D() != DefaultWatchPollInterval {
+		t.Errorf("unexpected watch config: %+v", cfg.Watch)
+	}
+	for _, tt := range []struct {
+		watch   string
+		wantErr string
+	}{
+		{`{"backend":"fanotify"}`, "watch.backend"},
+		{`{"quietPeriod":"-1s"}`, "watch.quietPeriod"},
+		{`{"quietPeriod":"1m","maxDelay":"30s"}`, "watch.maxDelay"},
+		{`{"statusAddress":"9090"}`, "watch.statusAddress"},
+	} {
+		_, err := LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","watch":`+tt.watch+`}`))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.watch, tt.wantErr, err)
+		}
+	}
+
+	_, err = LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","checksum":"md5"}`))
+	if err == nil || !strings.Contains(err.Error(), "'checksum'") {
+		t.Errorf("expected unsupported checksum to fail, got %v", err)
+	}
+
+	_, err = LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","compression":"zstd"}`))
+	if err == nil || !strings.Contains(err.Error(), "'compression'") {
+		t.Errorf("expected unsupported compression to fail, got %v", err)
+	}
+
+	// 클라이언트 인증서 매핑은 인증서를 검증할 CA 가 있어야 의미가 있음.
+	_, err = LoadConfig(writeTempConfig(t, `{"rootDir":"/tmp","auth":{"clientCerts":{"pipeline":["reader"]}}}`))
+	if err == nil || !strings.Contains(err.Error(), "auth.clientCerts") {
+		t.Errorf("expected clientCerts without clientCAFile to fail, got %v", err)
+	}
+}
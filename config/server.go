@@ -0,0 +1,154 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// DefaultServerAddress server.address 와 server.unixSocket 이 모두 비어 있을 때 사용하는 gRPC listen 주소.
+	DefaultServerAddress = ":50052"
+	// DefaultMaxRecvMsgSize 요청 최대 크기(1.5MiB) + gRPC 오버헤드(512KiB).
+	DefaultMaxRecvMsgSize = 2 * 1024 * 1024
+	// DefaultMaxSendMsgSize 응답 최대 크기. DataBlock 전체를 한 번에 보내므로 math.MaxInt32 로 둠.
+	DefaultMaxSendMsgSize = 1<<31 - 1
+	// DefaultMaxConcurrentStreams 연결당 동시 스트림 수. math.MaxUint32 와 같음.
+	DefaultMaxConcurrentStreams = 1<<32 - 1
+	// DefaultShutdownGracePeriod 종료 시 처리 중인 요청을 기다리는 최대 시간.
+	DefaultShutdownGracePeriod = 30 * time.Second
+)
+
+// ServerConfig serve 명령의 서버 설정. 비어 있는 값은 WithDefaults 에서 기본값으로 채움.
+type ServerConfig struct {
+	Address              string          `json:"address"`              // gRPC TCP listen 주소 (예: ":50052")
+	UnixSocket           string          `json:"unixSocket"`           // 설정하면 address 대신 이 Unix socket 에서 listen
+	HTTPAddress          string          `json:"httpAddress"`          // HTTP/JSON 게이트웨이 주소 (비어 있으면 사용 안 함)
+	MetricsAddress       string          `json:"metricsAddress"`       // /metrics 만 노출할 주소 (비어 있으면 사용 안 함). 인증이 없으므로 내부 주소에 둠
+	MaxRecvMsgSize       int             `json:"maxRecvMsgSize"`       // 바이트
+	MaxSendMsgSize       int             `json:"maxSendMsgSize"`       // 바이트
+	MaxConcurrentStreams uint32          `json:"maxConcurrentStreams"` // 연결당 동시 스트림 수
+	Keepalive            KeepaliveConfig `json:"keepalive"`
+	TLS                  TLSConfig       `json:"tls"`
+	ShutdownGracePeriod  Duration        `json:"shutdownGracePeriod"` // 예: "30s". 지나면 남은 요청을 강제로 끊음
+	Compression          string          `json:"compression"`         // "gzip" 이면 gzip 을 지원하는 클라이언트에게 응답을 압축해서 보냄
+}
+
+// KeepaliveConfig gRPC keepalive 설정. 0 이면 gRPC 기본값을 사용함.
+type KeepaliveConfig struct {
+	Time                Duration `json:"time"`                // 이 시간 동안 활동이 없으면 서버가 ping 을 보냄 (gRPC 기본 2h)
+	Timeout             Duration `json:"timeout"`             // ping 응답을 기다리는 시간 (gRPC 기본 20s)
+	MaxConnectionIdle   Duration `json:"maxConnectionIdle"`   // 이 시간 동안 RPC 가 없으면 연결을 닫음 (기본 무제한)
+	MinTime             Duration `json:"minTime"`             // 클라이언트 ping 의 최소 간격. 더 자주 보내면 연결을 끊음 (gRPC 기본 5m)
+	PermitWithoutStream bool     `json:"permitWithoutStream"` // 진행 중인 스트림이 없어도 클라이언트 ping 을 허용함
+}
+
+// TLSConfig TLS 인증서 경로. certFile/keyFile 이 없으면 평문으로 서비스함. 파일은 serve 를 시작할 때 읽음.
+type TLSConfig struct {
+	CertFile     string `json:"certFile"`
+	KeyFile      string `json:"keyFile"`
+	ClientCAFile string `json:"clientCAFile"` // 설정하면 이 CA 가 서명한 클라이언트 인증서를 요구함 (mTLS)
+}
+
+// Enabled certFile 과 keyFile 이 설정되어 있으면 true.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// WithDefaults 비어 있는 값을 기본값으로 채운 복사본을 반환함.
+func (s ServerConfig) WithDefaults() ServerConfig {
+	if s.Address == "" && s.UnixSocket == "" {
+		s.Address = DefaultServerAddress
+	}
+	if s.MaxRecvMsgSize == 0 {
+		s.MaxRecvMsgSize = DefaultMaxRecvMsgSize
+	}
+	if s.MaxSendMsgSize == 0 {
+		s.MaxSendMsgSize = DefaultMaxSendMsgSize
+	}
+	if s.MaxConcurrentStreams == 0 {
+		s.MaxConcurrentStreams = DefaultMaxConcurrentStreams
+	}
+	if s.ShutdownGracePeriod == 0 {
+		s.ShutdownGracePeriod = Duration(DefaultShutdownGracePeriod)
+	}
+	return s
+}
+
+// Validate 설정 값이 올바른지 확인함. 에러 메시지에는 설정 파일의 키 이름을 씀.
+func (s ServerConfig) Validate() error {
+	if s.Address != "" && s.UnixSocket != "" {
+		return fmt.Errorf("'server.address' and 'server.unixSocket' are mutually exclusive")
+	}
+	for key, addr := range map[string]string{
+		"server.address":        s.Address,
+		"server.httpAddress":    s.HTTPAddress,
+		"server.metricsAddress": s.MetricsAddress,
+	} {
+		if addr == "" {
+			continue
+		}
+		if _, port, err := net.SplitHostPort(addr); err != nil || port == "" {
+			return fmt.Errorf("invalid '%s' %q: must be host:port", key, addr)
+		}
+	}
+	if s.MaxRecvMsgSize < 0 {
+		return fmt.Errorf("invalid 'server.maxRecvMsgSize' %d: must not be negative", s.MaxRecvMsgSize)
+	}
+	if s.MaxSendMsgSize < 0 {
+		return fmt.Errorf("invalid 'server.maxSendMsgSize' %d: must not be negative", s.MaxSendMsgSize)
+	}
+	for key, d := range map[string]Duration{
+		"server.shutdownGracePeriod":         s.ShutdownGracePeriod,
+		"server.keepalive.time":              s.Keepalive.Time,
+		"server.keepalive.timeout":           s.Keepalive.Timeout,
+		"server.keepalive.maxConnectionIdle": s.Keepalive.MaxConnectionIdle,
+		"server.keepalive.minTime":           s.Keepalive.MinTime,
+	} {
+		if d < 0 {
+			return fmt.Errorf("invalid '%s' %s: must not be negative", key, d)
+		}
+	}
+	switch s.Compression {
+	case "", "none", "gzip":
+	default:
+		return fmt.Errorf("invalid 'server.compression' %q: must be \"none\" or \"gzip\"", s.Compression)
+	}
+	return s.TLS.validate()
+}
+
+func (t TLSConfig) validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("'server.tls.certFile' and 'server.tls.keyFile' must be set together")
+	}
+	if t.ClientCAFile != "" && !t.Enabled() {
+		return fmt.Errorf("'server.tls.clientCAFile' requires 'server.tls.certFile' and 'server.tls.keyFile'")
+	}
+	return nil
+}
+
+// Duration JSON 에서 "30s", "5m" 같은 문자열로 쓰는 time.Duration.
+type Duration time.Duration
+
+// D time.Duration 으로 변환함.
+func (d Duration) D() time.Duration { return time.Duration(d) }
+
+func (d Duration) String() string { return time.Duration(d).String() }
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %s", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(v)
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// WatchBackendPoll 일정 간격으로 RootDir 을 다시 읽어서 변경을 찾음. NFS, lustre 처럼 inotify 가 다른 노드의 변경을 알려주지 않는 파일 시스템용.
+	WatchBackendPoll = "poll"
+	// WatchBackendInotify Linux inotify 로 변경을 바로 받음.
+	WatchBackendInotify = "inotify"
+
+	// DefaultWatchQuietPeriod 마지막 변경 후 이 시간 동안 새 변경이 없으면 sync 함.
+	DefaultWatchQuietPeriod = 10 * time.Second
+	// DefaultWatchMaxDelay 변경이 계속 들어와도 첫 변경 후 이 시간이 지나면 sync 함.
+	DefaultWatchMaxDelay = 5 * time.Minute
+	// DefaultWatchPollInterval poll backend 의 스캔 간격.
+	DefaultWatchPollInterval = 30 * time.Second
+)
+
+// WatchConfig watch 명령의 설정. 비어 있는 값은 WithDefaults 에서 기본값으로 채움.
+type WatchConfig struct {
+	Backend       string   `json:"backend"`       // "poll" 또는 "inotify". 비어 있으면 Linux 에서는 inotify, 그 외에는 poll
+	QuietPeriod   Duration `json:"quietPeriod"`   // 예: "10s". 변경이 멈춘 뒤 sync 까지 기다리는 시간
+	MaxDelay      Duration `json:"maxDelay"`      // 예: "5m". 변경이 멈추지 않아도 첫 변경 후 이 시간 안에 sync 함
+	PollInterval  Duration `json:"pollInterval"`  // poll backend 의 스캔 간격
+	StatusAddress string   `json:"statusAddress"` // /status, /metrics 를 노출할 주소 (비어 있으면 사용 안 함)
+}
+
+// WithDefaults 비어 있는 값을 기본값으로 채운 복사본을 반환함. backend 는 실행 환경에 따라 정해지므로 채우지 않음.
+func (w WatchConfig) WithDefaults() WatchConfig {
+	if w.QuietPeriod == 0 {
+		w.QuietPeriod = Duration(DefaultWatchQuietPeriod)
+	}
+	if w.MaxDelay == 0 {
+		w.MaxDelay = Duration(DefaultWatchMaxDelay)
+	}
+	if w.PollInterval == 0 {
+		w.PollInterval = Duration(DefaultWatchPollInterval)
+	}
+	return w
+}
+
+// Validate 설정 값이 올바른지 확인함. 에러 메시지에는 설정 파일의 키 이름을 씀.
+func (w WatchConfig) Validate() error {
+	switch w.Backend {
+	case "", WatchBackendPoll, WatchBackendInotify:
+	default:
+		return fmt.Errorf("invalid 'watch.backend' %q: must be %q or %q", w.Backend, WatchBackendPoll, WatchBackendInotify)
+	}
+	for key, d := range map[string]Duration{
+		"watch.quietPeriod":  w.QuietPeriod,
+		"watch.maxDelay":     w.MaxDelay,
+		"watch.pollInterval": w.PollInterval,
+	} {
+		if d < 0 {
+			return fmt.Errorf("invalid '%s' %s: must not be negative", key, d)
+		}
+	}
+	if w.MaxDelay != 0 && w.MaxDelay < w.QuietPeriod {
+		return fmt.Errorf("invalid 'watch.maxDelay' %s: must not be shorter than 'watch.quietPeriod' %s", w.MaxDelay, w.QuietPeriod)
+	}
+	if w.StatusAddress != "" {
+		if _, port, err := net.SplitHostPort(w.StatusAddress); err != nil || port == "" {
+			return fmt.Errorf("invalid 'watch.statusAddress' %q: must be host:port", w.StatusAddress)
+		}
+	}
+	return nil
+}
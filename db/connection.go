@@ -41,9 +41,55 @@ func InitializeDatabase(db *sql.DB) error {
 	} else {
 		logger.Info("DB already initialized. Skipping init.sql execution.")
 	}
+	// 이후에 추가된 테이블. 이미 만들어진 DB 에도 적용되도록 매번 실행함 (IF NOT EXISTS).
+	for _, migration := range migrations {
+		if err := execSQLNoCtx(db, migration); err != nil {
+			return fmt.Errorf("DB migration failed: %w", err)
+		}
+	}
+	// SQLite 의 ADD COLUMN 은 IF NOT EXISTS 가 없으므로 컬럼이 없을 때만 실행함.
+	for _, migration := range columnMigrations {
+		exists, err := columnExists(db, migration.table, migration.column)
+		if err != nil {
+			return fmt.Errorf("DB migration failed: %w", err)
+		}
+		if exists {
+			continue
+		}
+		if err := execSQLNoCtx(db, migration.file); err != nil {
+			return fmt.Errorf("DB migration failed: %w", err)
+		}
+	}
 	return nil
 }
 
+// migrations init.sql 이후에 추가된 스키마. 여러 번 실행해도 결과가 같아야 함.
+var migrations = []string{
+	"create_datablock_versions.sql",
+}
+
+// columnMigration table 에 column 이 없으면 file 을 실행해서 컬럼을 추가함.
+type columnMigration struct {
+	table, column, file string
+}
+
+// columnMigrations 이미 있는 테이블에 나중에 추가된 컬럼. file 이 여러 컬럼을 추가하면 column 은 그중 첫 번째.
+// 새 DB 는 init.sql 에서 컬럼을 모두 만들므로, 컬럼을 추가할 때는 init.sql 에도 같이 넣어야 함.
+var columnMigrations = []columnMigration{
+	{table: "files", column: "checksum", file: "add_files_checksum.sql"},
+	{table: "files", column: "mtime", file: "add_mtime.sql"},
+	{table: "files", column: "inode", file: "add_files_inode.sql"},
+	{table: "folders", column: "dir_mtime", file: "add_folders_dir_stat.sql"},
+}
+
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check column %s.%s: %w", table, column, err)
+	}
+	return count > 0, nil
+}
+
 func isDBInitialized(db *sql.DB) bool {
 	var count int
 	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name IN ('folders', 'files')").Scan(&count)
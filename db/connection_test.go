@@ -6,6 +6,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -114,8 +115,8 @@ func createTestFolder(root, folderName string) (string, error) {
 }
 
 func insertFolderInfo(db *sql.DB, folder Folder) error {
-	query := "INSERT INTO folders (path, total_size, file_count, created_time) VALUES (?, ?, ?, ?)"
-	_, err := db.Exec(query, folder.Path, folder.TotalSize, folder.FileCount, folder.CreatedTime)
+	query := "INSERT INTO folders (path, total_size, file_count, created_time, newest_mtime) VALUES (?, ?, ?, ?, ?)"
+	_, err := db.Exec(query, folder.Path, folder.TotalSize, folder.FileCount, folder.CreatedTime, dbTime(folder.NewestModTime))
 	return err
 }
 
@@ -197,3 +198,85 @@ func TestCompareFolders(t *testing.T) {
 		}
 	})
 }
+
+// TestInitializeDatabase_ColumnMigration checksum 컬럼이 없는 이전 DB 에 컬럼이 추가되고, 다시 실행해도 실패하지 않는지 확인함.
+func TestInitializeDatabase_ColumnMigration(t *testing.T) {
+	// exec_sqlmock_test 에서 sqlFiles 를 바꿔 놓을 수 있으므로 embed 된 쿼리로 되돌림.
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
+	db, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer db.Close()
+	// checksum 컬럼이 생기기 전의 files 테이블.
+	for _, q := range []string{
+		"CREATE TABLE folders (id INTEGER PRIMARY KEY AUTOINCREMENT, path TEXT NOT NULL UNIQUE, total_size INTEGER DEFAULT 0, file_count INTEGER DEFAULT 0, created_time DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL)",
+		"CREATE TABLE files (id INTEGER PRIMARY KEY AUTOINCREMENT, folder_id INTEGER NOT NULL, name TEXT NOT NULL, size INTEGER NOT NULL, created_time DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL, UNIQUE(folder_id, name))",
+		"INSERT INTO folders (path) VALUES ('/data/run1')",
+		"INSERT INTO files (folder_id, name, size) VALUES (1, 's1_R1.fastq', 10)",
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("create legacy schema: %v", err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := InitializeDatabase(db); err != nil {
+			t.Fatalf("InitializeDatabase run %d: %v", i+1, err)
+		}
+	}
+	files, err := GetFilesByPathFromDB(db, "/data/run1")
+	if err != nil {
+		t.Fatalf("GetFilesByPathFromDB: %v", err)
+	}
+	if len(files) != 1 || files[0].Checksum != "" || files[0].ChecksumModTime != 0 || !files[0].ModTime.IsZero() {
+		t.Errorf("expected existing row with empty checksum and mtime, got %+v", files)
+	}
+	folders, err := GetFoldersFromDB(db)
+	if err != nil {
+		t.Fatalf("GetFoldersFromDB: %v", err)
+	}
+	if len(folders) != 1 || !folders[0].NewestModTime.IsZero() {
+		t.Errorf("expected existing folder with empty newest_mtime, got %+v", folders)
+	}
+
+	// 마이그레이션한 DB 와 init.sql 로 새로 만든 DB 의 컬럼이 같아야 함.
+	fresh, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer fresh.Close()
+	if err := InitializeDatabase(fresh); err != nil {
+		t.Fatalf("InitializeDatabase fresh: %v", err)
+	}
+	for _, table := range []string{"folders", "files"} {
+		if got, want := tableColumns(t, db, table), tableColumns(t, fresh, table); !slices.Equal(got, want) {
+			t.Errorf("%s columns after migration %v, fresh %v", table, got, want)
+		}
+	}
+}
+
+// tableColumns table 의 컬럼 이름을 이름 순서로 반환함.
+func tableColumns(t *testing.T, db *sql.DB, table string) []string {
+	t.Helper()
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?) ORDER BY name", table)
+	if err != nil {
+		t.Fatalf("table_info %s: %v", table, err)
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("scan column: %v", err)
+		}
+		columns = append(columns, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("table_info %s: %v", table, err)
+	}
+	return columns
+}
@@ -23,9 +23,11 @@ var testFS = fstest.MapFS{
 	"queries/test_select_fail.sql":  &fstest.MapFile{Data: []byte("SELECT * FROM non_existing_table;")},
 }
 
-// 각 테스트 시작 전에 sqlFiles 를 테스트용 파일 시스템으로 재정의
-func initTestFS() {
+// 각 테스트 시작 전에 sqlFiles 를 테스트용 파일 시스템으로 재정의하고, 테스트가 끝나면 되돌림
+func initTestFS(t *testing.T) {
+	origFS := sqlFiles
 	sqlFiles = testFS
+	t.Cleanup(func() { sqlFiles = origFS })
 }
 
 // -------------------
@@ -33,7 +35,7 @@ func initTestFS() {
 // -------------------
 
 func TestExecSQLTx_FileNotFound(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -73,7 +75,7 @@ func TestExecSQLTx_FileNotFound(t *testing.T) {
 }
 
 func TestExecSQLTx_EmptyFile(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -112,7 +114,7 @@ func TestExecSQLTx_EmptyFile(t *testing.T) {
 }
 
 func TestExecSQLTx_Success(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -164,7 +166,7 @@ func TestExecSQLTx_Success(t *testing.T) {
 }
 
 func TestExecSQLTx_QueryExecutionError(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -216,7 +218,7 @@ func TestExecSQLTx_QueryExecutionError(t *testing.T) {
 }
 
 func TestExecSQLTxNoCtx_Success(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -269,7 +271,7 @@ func TestExecSQLTxNoCtx_Success(t *testing.T) {
 // -------------------
 
 func TestExecSQL_FileNotFound(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -293,7 +295,7 @@ func TestExecSQL_FileNotFound(t *testing.T) {
 }
 
 func TestExecSQL_EmptyFile(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -317,7 +319,7 @@ func TestExecSQL_EmptyFile(t *testing.T) {
 }
 
 func TestExecSQL_Success(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -349,7 +351,7 @@ func TestExecSQL_Success(t *testing.T) {
 }
 
 func TestExecSQL_QueryExecutionError(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -386,7 +388,7 @@ func TestExecSQL_QueryExecutionError(t *testing.T) {
 }
 
 func TestExecSQLNoCtx_Success(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -422,7 +424,7 @@ func TestExecSQLNoCtx_Success(t *testing.T) {
 // -------------------
 
 func TestQuerySQL_FileNotFound(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -446,7 +448,7 @@ func TestQuerySQL_FileNotFound(t *testing.T) {
 }
 
 func TestQuerySQL_EmptyFile(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -470,7 +472,7 @@ func TestQuerySQL_EmptyFile(t *testing.T) {
 }
 
 func TestQuerySQL_Success(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -510,7 +512,7 @@ func TestQuerySQL_Success(t *testing.T) {
 }
 
 func TestQuerySQL_QueryError(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -547,7 +549,7 @@ func TestQuerySQL_QueryError(t *testing.T) {
 }
 
 func TestQuerySQLNoCtx_Success(t *testing.T) {
-	initTestFS()
+	initTestFS(t)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to open sqlmock database: %v", err)
@@ -3,19 +3,27 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"github.com/seoyhaein/tori/checksum"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
+// readDir 폴더 목록을 읽는 함수. 테스트에서 폴더를 몇 번 읽었는지 세기 위해 바꿔 쓸 수 있게 변수로 둠.
+var readDir = os.ReadDir
+
+// racyDirWindow 폴더의 수정 시각이 지금부터 이 시간 안이면 믿지 않음.
+// 파일 시스템의 시각 단위가 거칠면, 폴더를 읽은 뒤 같은 시각 안에 생긴 항목이 수정 시각을 바꾸지 못할 수 있음.
+const racyDirWindow = 2 * time.Second
+
 // GetSubFolders 특정 디렉토리 내의 서브 폴더(디렉토리)들을 읽어 Folder 구조체 슬라이스로 반환함.
 // IMPORTANT: exclusions 목록에 포함된 이름과 정확히 일치하거나 접두어로 시작하는 폴더는 제외함.
 func GetSubFolders(rootPath string, exclusions []string) ([]Folder, error) {
 	var folders []Folder
 
 	// 지정된 디렉토리 내의 항목들을 읽음 (Go 1.16 이상: os.ReadDir 사용)
-	entries, err := os.ReadDir(rootPath)
+	entries, err := readDir(rootPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory %s: %w", rootPath, err)
 	}
@@ -67,40 +75,52 @@ func GetSubFolders(rootPath string, exclusions []string) ([]Folder, error) {
 	return folders, nil
 }
 
+// ExcludedFile fileName 이 exclusions 목록에 있는 항목과 정확히 일치하거나,
+// 만약 exclusions 항목이 "*.확장자" 형태이면, fileName 에 해당 확장자가 포함되어 있으면 true 를 반환함.
+// 그 밖에 *, ?, [ 가 들어간 항목(예: "invalid_files_*.txt")은 filepath.Match 패턴으로 비교함.
+func ExcludedFile(fileName string, exclusions []string) bool {
+	for _, ex := range exclusions {
+		// 패턴이 "*.<ext>" 형식이면, 해당 확장자가 fileName 내에 존재하는지 확인함.
+		if strings.HasPrefix(ex, "*.") && !strings.ContainsAny(ex[2:], "*?[") {
+			ext := ex[1:] // 예: "*.pb" -> ext 는 ".pb"
+			if strings.Contains(fileName, ext) {
+				return true
+			}
+		} else if strings.ContainsAny(ex, "*?[") {
+			if ok, _ := filepath.Match(ex, fileName); ok {
+				return true
+			}
+		} else {
+			// 일반적인 정확한 비교
+			if fileName == ex {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GetCurrentFolderFileInfo 특정 디렉토리 내의 파일들을 읽어 전체 파일 개수, 총 크기와 각 파일의 메타데이터를 수집.
 // Go 1.16부터 도입된 os.ReadDir, DirEntry.Info()를 사용하여 시스템 콜을 최소화함. dirPath 여기서 이 폴더는 조사하고자 하는 자신의 폴더 path 임.
 func GetCurrentFolderFileInfo(dirPath string, exclusions []string) (Folder, []File, error) {
 	var folder Folder
 	var files []File
 
+	// 폴더 자체의 수정 시각은 목록을 읽기 전에 구해야, 읽는 동안 생긴 변경을 다음에 다시 읽어서 찾음.
+	dirModTime, dirInode, err := dirStat(dirPath)
+	if err != nil {
+		return folder, nil, err
+	}
+
 	// 디렉토리 내 파일 목록 읽기 (Go 1.16 이상에서는 os.ReadDir 사용)
-	entries, err := os.ReadDir(dirPath)
+	entries, err := readDir(dirPath)
 	if err != nil {
 		return folder, nil, fmt.Errorf("failed to read directory %s: %w", dirPath, err)
 	}
 
 	totalSize := int64(0)
 	fileCount := int64(0)
-
-	// excludeFiles 는 dirName 이 exclusions 목록에 있는 항목과 정확히 일치하거나,
-	// 만약 exclusions 항목이 "*.확장자" 형태이면, dirName 에 해당 확장자가 포함되어 있으면 true 를 반환함.
-	excludeFiles := func(fileName string, exclusions []string) bool {
-		for _, ex := range exclusions {
-			// 패턴이 "*.<ext>" 형식이면, 해당 확장자가 dirName 내에 존재하는지 확인함.
-			if strings.HasPrefix(ex, "*.") {
-				ext := ex[1:] // 예: "*.pb" -> ext 는 ".pb"
-				if strings.Contains(fileName, ext) {
-					return true
-				}
-			} else {
-				// 일반적인 정확한 비교
-				if fileName == ex {
-					return true
-				}
-			}
-		}
-		return false
-	}
+	var newest time.Time
 
 	// 각 엔트리(파일)에 대해 처리
 	for _, entry := range entries {
@@ -110,7 +130,7 @@ func GetCurrentFolderFileInfo(dirPath string, exclusions []string) (Folder, []Fi
 		fileName := entry.Name()
 
 		// 제외 목록에 있는 파일이면 건너뛰기
-		if excludeFiles(fileName, exclusions) {
+		if ExcludedFile(fileName, exclusions) {
 			continue
 		}
 
@@ -123,49 +143,80 @@ func GetCurrentFolderFileInfo(dirPath string, exclusions []string) (Folder, []Fi
 			return folder, nil, fmt.Errorf("failed to get file info for %s: %w", filePath, err)
 		}
 
-		size := info.Size()
-		totalSize += size
+		fileRecord := newDiskFile(dirPath, info)
+		totalSize += fileRecord.Size
 		fileCount++
-
-		// File 구조체 생성 (ID 및 FolderID는 DB 삽입 후 업데이트 가능)
-		// IMPORTANT sqlite 에서 AUTOINCREMENT 로 시작하도록 하였음. 따라서 0 이 들어간것은 DB 에 들어가기 전 데이터임.
-		fileRecord := File{
-			ID:          0, // 아직 DB에 저장되지 않았으므로 0 또는 추후 채움
-			FolderID:    0, // folder 삽입 후 업데이트
-			Name:        fileName,
-			Size:        size,
-			CreatedTime: info.ModTime().Format("2006-01-02 15:04:05"),
-			Path:        dirPath, // Path 필드에 실제 파일 경로를 채움
+		if fileRecord.ModTime.After(newest) {
+			newest = fileRecord.ModTime
 		}
 		files = append(files, fileRecord)
 	}
 
 	// IMPORTANT Folder 구조체 생성 (ID는 DB 삽입 후 업데이트)
 	folder = Folder{
-		ID:          0,
-		Path:        dirPath,
-		TotalSize:   totalSize,
-		FileCount:   fileCount,
-		CreatedTime: time.Now().Format("2006-01-02 15:04:05"),
+		ID:            0,
+		Path:          dirPath,
+		TotalSize:     totalSize,
+		FileCount:     fileCount,
+		CreatedTime:   time.Now().Format("2006-01-02 15:04:05"),
+		NewestModTime: newest,
+		DirModTime:    dirModTime,
+		DirInode:      dirInode,
 	}
 
 	return folder, files, nil
 }
 
-// CompareFolders 디스크와 DB의 폴더 정보를 비교하여 변경 사항이 있는지 확인함.
+// newDiskFile dirPath 폴더에 있는 파일의 info 로 File 을 만듦.
+func newDiskFile(dirPath string, info os.FileInfo) File {
+	// IMPORTANT sqlite 에서 AUTOINCREMENT 로 시작하도록 하였음. 따라서 ID, FolderID 가 0 인 것은 DB 에 들어가기 전 데이터임.
+	return File{
+		Name:        info.Name(),
+		Size:        info.Size(),
+		CreatedTime: info.ModTime().Format("2006-01-02 15:04:05"),
+		ModTime:     info.ModTime().UTC(),
+		Inode:       fileInode(info),
+		Path:        dirPath, // Path 필드에 실제 파일 경로를 채움
+	}
+}
+
+// dirStat 폴더 자체의 수정 시각(UTC)과 inode. 수정 시각이 racyDirWindow 안이면 zero 를 반환해서, 다음에도 폴더를 다시 읽게 함.
+func dirStat(dirPath string) (time.Time, uint64, error) {
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to stat directory %s: %w", dirPath, err)
+	}
+	modTime := info.ModTime().UTC()
+	if time.Since(modTime) < racyDirWindow {
+		modTime = time.Time{}
+	}
+	return modTime, fileInode(info), nil
+}
+
+// CompareFolders 디스크와 DB의 폴더 정보를 비교하여 변경 사항이 있는지 확인함. RootDir 바로 아래 폴더만 비교함.
 func CompareFolders(db *sql.DB, rootPath string, foldersExclusions, filesExclusions []string) (bool, []Folder, []FolderDiff, error) {
-	// 디스크에서 서브 폴더 목록 조회
-	diskFolders, err := GetFoldersInfo(rootPath, foldersExclusions)
+	return CompareFolderTree(db, rootPath, DefaultMaxDepth, foldersExclusions, filesExclusions)
+}
+
+// CompareFolderTree CompareFolders 와 같지만 maxDepth 깊이까지의 모든 폴더를 비교함. 폴더 통계는 하위 폴더를 포함한 합계로 비교함.
+func CompareFolderTree(db *sql.DB, rootPath string, maxDepth int, foldersExclusions, filesExclusions []string) (bool, []Folder, []FolderDiff, error) {
+	// 디스크에서 폴더 트리 조회
+	diskFolders, err := GetFolderTree(rootPath, maxDepth, foldersExclusions, filesExclusions)
 	if err != nil {
 		return false, nil, nil, fmt.Errorf("failed to get subfolders from disk: %w", err)
 	}
-
-	// DB 에서 폴더 정보 조회
 	dbFolders, err := GetFoldersFromDB(db)
 	if err != nil {
 		return false, nil, nil, fmt.Errorf("failed to get folders from DB: %w", err)
 	}
+	diffs := compareFolderStats(dbFolders, diskFolders, false)
+	unchanged := len(diffs) == 0
+	return unchanged, diskFolders, diffs, nil
+}
 
+// compareFolderStats diskFolders 의 크기, 파일 수, 가장 최근 수정 시각을 dbFolders 와 비교해서 다른 폴더만 반환함.
+// dirStat 이 true 면 폴더 자체의 수정 시각과 inode 만 다른 폴더도 반환해서, 새 값을 DB 에 저장하게 함.
+func compareFolderStats(dbFolders, diskFolders []Folder, dirStat bool) []FolderDiff {
 	// DB 폴더 정보를 경로 기준으로 맵으로 구성 (폴더 경로를 키로 사용)
 	dbFolderMap := make(map[string]Folder)
 	for _, folder := range dbFolders {
@@ -173,46 +224,41 @@ func CompareFolders(db *sql.DB, rootPath string, foldersExclusions, filesExclusi
 	}
 
 	var diffs []FolderDiff
-	// 디스크의 각 폴더에 대해 파일 통계를 갱신한 후 DB와 비교
+	// 디스크의 각 폴더 통계를 DB와 비교
 	for _, diskFolder := range diskFolders {
-		updatedFolder, _, err := GetCurrentFolderFileInfo(diskFolder.Path, filesExclusions)
-		if err != nil {
-			return false, nil, nil, fmt.Errorf("failed to get folder details for %s: %w", diskFolder.Path, err)
-		}
-
-		if dbFolder, ok := dbFolderMap[diskFolder.Path]; !ok {
-			// DB에 해당 폴더 정보가 없는 경우 FolderID를 0으로 처리
-
-			diffs = append(diffs, FolderDiff{
-				FolderID:      0,
-				Path:          diskFolder.Path,
-				DiskTotalSize: updatedFolder.TotalSize,
-				DBTotalSize:   0,
-				DiskFileCount: updatedFolder.FileCount,
-				DBFileCount:   0,
-			})
-		} else {
-			// DB에 해당 폴더 정보가 있는 경우
-			if updatedFolder.TotalSize != dbFolder.TotalSize || updatedFolder.FileCount != dbFolder.FileCount {
-				diffs = append(diffs, FolderDiff{
-					FolderID:      dbFolder.ID,
-					Path:          diskFolder.Path,
-					DiskTotalSize: updatedFolder.TotalSize,
-					DBTotalSize:   dbFolder.TotalSize,
-					DiskFileCount: updatedFolder.FileCount,
-					DBFileCount:   dbFolder.FileCount,
-				})
-
-			}
+		dbFolder, ok := dbFolderMap[diskFolder.Path]
+		if ok && diskFolder.TotalSize == dbFolder.TotalSize && diskFolder.FileCount == dbFolder.FileCount &&
+			diskFolder.NewestModTime.Equal(dbFolder.NewestModTime) &&
+			(!dirStat || diskFolder.DirModTime.Equal(dbFolder.DirModTime) && diskFolder.DirInode == dbFolder.DirInode) {
+			continue
 		}
+		// DB에 해당 폴더 정보가 없는 경우 dbFolder 는 zero 값이라 FolderID 가 0 이 됨.
+		diffs = append(diffs, FolderDiff{
+			FolderID:          dbFolder.ID,
+			Path:              diskFolder.Path,
+			DiskTotalSize:     diskFolder.TotalSize,
+			DBTotalSize:       dbFolder.TotalSize,
+			DiskFileCount:     diskFolder.FileCount,
+			DBFileCount:       dbFolder.FileCount,
+			DiskNewestModTime: diskFolder.NewestModTime,
+			DBNewestModTime:   dbFolder.NewestModTime,
+			DiskDirModTime:    diskFolder.DirModTime,
+			DBDirModTime:      dbFolder.DirModTime,
+			DiskDirInode:      diskFolder.DirInode,
+			DBDirInode:        dbFolder.DirInode,
+		})
 	}
-
-	unchanged := len(diffs) == 0
-	return unchanged, diskFolders, diffs, nil
+	return diffs
 }
 
 // CompareFiles  파일 비교.
 func CompareFiles(db *sql.DB, folderPath string, filesExclusions []string) (bool, []File, []FileChange, error) {
+	return CompareFilesWithChecksum(db, folderPath, filesExclusions, "")
+}
+
+// CompareFilesWithChecksum CompareFiles 와 같지만, algorithm 이 checksum.None 이 아니면 크기가 같은 파일도 내용 checksum 으로 비교함.
+// 비교 규칙은 compareFileLists 참고.
+func CompareFilesWithChecksum(db *sql.DB, folderPath string, filesExclusions []string, algorithm string) (bool, []File, []FileChange, error) {
 	// 디스크의 파일 정보 조회
 	_, diskFiles, err := GetCurrentFolderFileInfo(folderPath, filesExclusions)
 	if err != nil {
@@ -223,7 +269,21 @@ func CompareFiles(db *sql.DB, folderPath string, filesExclusions []string) (bool
 	if err != nil {
 		return false, nil, nil, fmt.Errorf("failed to get DB files for folder %s: %w", folderPath, err)
 	}
+	changes, err := compareFileLists(diskFiles, dbFiles, algorithm)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	changes = detectRenames(changes)
+	unchanged := len(changes) == 0
+	return unchanged, diskFiles, changes, nil
+}
 
+// compareFileLists 한 폴더의 디스크 파일과 DB 파일을 이름으로 맞춰서 변경 목록을 만듦.
+// 크기나 수정 시각이 다르면 "modified" 임. algorithm 이 checksum.None 이 아니면 크기가 같은 파일은 내용 checksum 으로 판단하고,
+// checksum 은 크기나 수정 시각이 DB 에 저장된 값과 다를 때만 다시 계산함. 내용이 같으면 ChangeChecksum,
+// 수정 시각을 저장하기 전의 DB 라서 수정 시각이 없거나, 수정 시각은 같은데 inode 만 다르면 ChangeModTime 을 반환함.
+// 이름이 바뀐 파일은 "removed" 와 "added" 로 나오고, detectRenames 가 하나로 합침.
+func compareFileLists(diskFiles, dbFiles []File, algorithm string) ([]FileChange, error) {
 	// 파일 이름을 키로 하는 맵 생성 (디스크와 DB 각각)
 	diskMap := make(map[string]File)
 	for _, f := range diskFiles {
@@ -238,31 +298,76 @@ func CompareFiles(db *sql.DB, folderPath string, filesExclusions []string) (bool
 	// 디스크에만 있는 파일 (추가된 파일)
 	for name, diskF := range diskMap {
 		if dbF, ok := dbMap[name]; !ok {
-			changes = append(changes, FileChange{
-				ChangeType: "added",
-				FileID:     0,              // 신규 추가이므로 ID 없음
-				FolderID:   diskF.FolderID, // 폴더 정보는 디스크 정보에서 가져옴 (또는 상위 로직에서 결정)
-				Name:       name,
-				DiskSize:   diskF.Size,
-				DBSize:     0,
-				Path:       diskF.Path,
-			})
+			change := FileChange{
+				ChangeType:  "added",
+				FileID:      0,              // 신규 추가이므로 ID 없음
+				FolderID:    diskF.FolderID, // 폴더 정보는 디스크 정보에서 가져옴 (또는 상위 로직에서 결정)
+				Name:        name,
+				DiskSize:    diskF.Size,
+				DBSize:      0,
+				Path:        diskF.Path,
+				DiskModTime: diskF.ModTime,
+				DiskInode:   diskF.Inode,
+			}
+			if checksum.Enabled(algorithm) {
+				if err := fillDiskChecksum(&change, diskF, algorithm); err != nil {
+					return nil, err
+				}
+			}
+			changes = append(changes, change)
 		} else {
-			// 파일 이름은 동일하지만 크기가 다른 경우 (수정된 파일)
-			if diskF.Size != dbF.Size {
-				changes = append(changes, FileChange{
-					ChangeType: "modified",
-					FileID:     dbF.ID,
-					FolderID:   dbF.FolderID,
-					Name:       name,
-					DiskSize:   diskF.Size,
-					DBSize:     dbF.Size,
-					Path:       diskF.Path,
-				})
+			change := FileChange{
+				ChangeType:  "modified",
+				FileID:      dbF.ID,
+				FolderID:    dbF.FolderID,
+				Name:        name,
+				DiskSize:    diskF.Size,
+				DBSize:      dbF.Size,
+				Path:        diskF.Path,
+				DiskModTime: diskF.ModTime,
+				DBModTime:   dbF.ModTime,
+				DBChecksum:  dbF.Checksum,
+				DiskInode:   diskF.Inode,
+				DBInode:     dbF.Inode,
+			}
+			modTimeKnown := !dbF.ModTime.IsZero()
+			modTimeSame := dbF.ModTime.Equal(diskF.ModTime)
+			// 디스크 inode 를 알 수 없으면 비교하지 않음.
+			inodeSame := diskF.Inode == 0 || diskF.Inode == dbF.Inode
+			switch {
+			case diskF.Size != dbF.Size:
+				// 파일 이름은 동일하지만 크기가 다른 경우 (수정된 파일)
+				if checksum.Enabled(algorithm) {
+					if err := fillDiskChecksum(&change, diskF, algorithm); err != nil {
+						return nil, err
+					}
+				}
+			case checksum.Enabled(algorithm) && checksumFresh(dbF, diskF, algorithm):
+				// 저장된 checksum 을 계산한 뒤로 파일이 바뀌지 않았음.
+				if modTimeSame && inodeSame {
+					continue
+				}
+				change.ChangeType = ChangeModTime
+			case checksum.Enabled(algorithm):
+				// 크기는 같지만 수정 시각이 바뀌었거나 저장된 checksum 을 쓸 수 없으므로 내용을 다시 계산함.
+				if err := fillDiskChecksum(&change, diskF, algorithm); err != nil {
+					return nil, err
+				}
+				sameAlgorithm := checksum.Algorithm(dbF.Checksum) == algorithm
+				if (sameAlgorithm && change.DiskChecksum == dbF.Checksum) || (!sameAlgorithm && (modTimeSame || !modTimeKnown)) {
+					change.ChangeType = ChangeChecksum
+				}
+			case !modTimeKnown:
+				change.ChangeType = ChangeModTime
+			case modTimeSame && inodeSame:
+				continue
+			case modTimeSame:
+				change.ChangeType = ChangeModTime
 			}
+			changes = append(changes, change)
 		}
 	}
-	// DB 에만 있는 파일 (삭제된 파일), Path 가 없음. 실제로 없으므로.
+	// DB 에만 있는 파일 (삭제된 파일), Path 는 DB 에 기록된 폴더 경로.
 	for name, dbF := range dbMap {
 		if _, ok := diskMap[name]; !ok {
 			changes = append(changes, FileChange{
@@ -272,12 +377,30 @@ func CompareFiles(db *sql.DB, folderPath string, filesExclusions []string) (bool
 				Name:       name,
 				DiskSize:   0,
 				DBSize:     dbF.Size,
-				Path:       "",
+				Path:       dbF.Path,
+				DBModTime:  dbF.ModTime,
+				DBChecksum: dbF.Checksum,
+				DBInode:    dbF.Inode,
 			})
 		}
 	}
-	unchanged := len(changes) == 0
-	return unchanged, diskFiles, changes, nil
+	return changes, nil
+}
+
+// checksumFresh DB 에 저장된 checksum 을 디스크 파일에 그대로 쓸 수 있는지. 크기는 이미 같다고 확인한 뒤에 호출함.
+func checksumFresh(dbF, diskF File, algorithm string) bool {
+	return checksum.Algorithm(dbF.Checksum) == algorithm && dbF.ChecksumModTime == diskF.ModTime.UnixNano()
+}
+
+// fillDiskChecksum diskF 의 내용 checksum 을 계산해서 change 에 채움.
+func fillDiskChecksum(change *FileChange, diskF File, algorithm string) error {
+	sum, err := checksum.File(filepath.Join(diskF.Path, diskF.Name), algorithm)
+	if err != nil {
+		return fmt.Errorf("failed to compute checksum: %w", err)
+	}
+	change.DiskChecksum = sum
+	change.DiskChecksumModTime = diskF.ModTime.UnixNano()
+	return nil
 }
 
 // GetFoldersInfo 지정한 Folder 배열에 대해, 각 Folder 의 TotalSize 와 FileCount 값을 계산하여 업데이트함.
@@ -295,9 +418,10 @@ func GetFoldersInfo(rootPath string, exclusions []string) ([]Folder, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to compute stats for folder %s: %w", folder.Path, err)
 		}
-		// 계산된 TotalSize 와 FileCount 로 업데이트
+		// 계산된 TotalSize, FileCount, NewestModTime 으로 업데이트
 		folders[i].TotalSize = updatedFolder.TotalSize
 		folders[i].FileCount = updatedFolder.FileCount
+		folders[i].NewestModTime = updatedFolder.NewestModTime
 		// CreatedTime 등 다른 값도 필요하면 업데이트 가능 (옵션)
 		folders[i].CreatedTime = updatedFolder.CreatedTime
 	}
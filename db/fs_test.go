@@ -84,8 +84,12 @@ func TestCompareFoldersMatch(t *testing.T) {
 	os.Mkdir(sub, 0755)
 	// create file to give size
 	os.WriteFile(filepath.Join(sub, "f1.txt"), []byte("hi"), 0644)
+	info, err := os.Stat(filepath.Join(sub, "f1.txt"))
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
 	// insert folder info in DB matching disk
-	_, err := db.Exec("INSERT INTO folders(path,total_size,file_count) VALUES(?,?,?)", sub, int64(2), int64(1))
+	_, err = db.Exec("INSERT INTO folders(path,total_size,file_count,newest_mtime) VALUES(?,?,?,?)", sub, int64(2), int64(1), dbTime(info.ModTime()))
 	if err != nil {
 		t.Fatalf("insert: %v", err)
 	}
@@ -106,13 +110,17 @@ func TestCompareFilesMatch(t *testing.T) {
 	folder := root
 	// create file on disk
 	os.WriteFile(filepath.Join(folder, "f1.txt"), []byte("abc"), 0644)
+	info, err := os.Stat(filepath.Join(folder, "f1.txt"))
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
 	// insert folder and file in DB
 	res, err := db.Exec("INSERT INTO folders(path,total_size,file_count) VALUES(?,?,?)", folder, int64(3), int64(1))
 	if err != nil {
 		t.Fatalf("insert folder: %v", err)
 	}
 	fid, _ := res.LastInsertId()
-	_, err = db.Exec("INSERT INTO files(folder_id,name,size) VALUES(?,?,?)", fid, "f1.txt", int64(3))
+	_, err = db.Exec("INSERT INTO files(folder_id,name,size,mtime,inode) VALUES(?,?,?,?,?)", fid, "f1.txt", int64(3), dbTime(info.ModTime()), int64(fileInode(info)))
 	if err != nil {
 		t.Fatalf("insert file: %v", err)
 	}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package db
+
+import "os"
+
+// fileInode unix 가 아니면 inode 를 쓰지 않음. 이름이 바뀐 파일은 checksum 으로만 찾음.
+func fileInode(info os.FileInfo) uint64 {
+	return 0
+}
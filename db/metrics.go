@@ -0,0 +1,61 @@
+package db
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/seoyhaein/tori/block"
+	"github.com/seoyhaein/tori/metrics"
+	"time"
+)
+
+// SyncFolders 단계 이름. tori_sync_phase_duration_seconds 의 phase label 로 사용함.
+const (
+	phaseDiff           = "diff"
+	phaseDBUpdate       = "db_update"
+	phaseFileBlock      = "fileblock"
+	phaseDataBlockWrite = "datablock_write"
+)
+
+var (
+	syncDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tori_sync_duration_seconds",
+		Help:    "Total SyncFolders duration by result (updated, unchanged, error).",
+		Buckets: metrics.DurationBuckets,
+	}, []string{"result"})
+	syncPhaseDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tori_sync_phase_duration_seconds",
+		Help:    "SyncFolders duration per phase (diff, db_update, fileblock, datablock_write).",
+		Buckets: metrics.DurationBuckets,
+	}, []string{"phase"})
+	syncScanned = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "tori_sync_last_scanned",
+		Help: "Folders and files scanned by the last sync.",
+	}, []string{"kind"})
+	syncChanged = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "tori_sync_last_changed",
+		Help: "Folders and files that differed from the DB snapshot in the last sync.",
+	}, []string{"kind"})
+	syncChangedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tori_sync_changed_total",
+		Help: "Folders and files that differed from the DB snapshot, summed over all syncs.",
+	}, []string{"kind"})
+)
+
+// observePhase start 부터 지금까지를 phase 의 소요 시간으로 기록함.
+func observePhase(phase string, start time.Time) {
+	syncPhaseDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
+
+// recordScan DiffFolders 결과로 스캔/변경 수를 기록함.
+func recordScan(folderFiles []block.FolderFiles, fDiff []FolderDiff, fChange []FileChange) {
+	files := 0
+	for _, ff := range folderFiles {
+		files += len(ff.Files)
+	}
+	syncScanned.WithLabelValues("folder").Set(float64(len(folderFiles)))
+	syncScanned.WithLabelValues("file").Set(float64(files))
+	syncChanged.WithLabelValues("folder").Set(float64(len(fDiff)))
+	syncChanged.WithLabelValues("file").Set(float64(len(fChange)))
+	syncChangedTotal.WithLabelValues("folder").Add(float64(len(fDiff)))
+	syncChangedTotal.WithLabelValues("file").Add(float64(len(fChange)))
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"path/filepath"
+	"time"
 )
 
 // structures
@@ -14,15 +16,32 @@ type File struct {
 	Name        string `db:"name"`
 	Size        int64  `db:"size"`
 	CreatedTime string `db:"created_time"` // sting 으로 해도 충분
-	Path        string `db:"-"`            // DB 매핑에서 완전히 제외
+	// Checksum "<algorithm>:<hex>" 형식의 내용 checksum. checksum 을 쓰지 않거나 아직 계산하지 않았으면 빈 문자열.
+	Checksum string `db:"checksum"`
+	// ChecksumModTime Checksum 을 계산할 때 파일의 수정 시각 (UnixNano). 크기와 이 값이 같으면 다시 계산하지 않음.
+	ChecksumModTime int64 `db:"checksum_mtime"`
+	// ModTime 파일의 수정 시각 (UTC). 수정 시각을 저장하기 전의 DB 에서 읽으면 zero.
+	ModTime time.Time `db:"mtime"`
+	// Inode 파일의 inode 번호. 이름이 바뀌거나 다른 폴더로 옮겨진 파일을 찾을 때 씀. 알 수 없으면 (unix 가 아니거나 이전 DB) 0.
+	Inode uint64 `db:"inode"`
+	Path  string `db:"-"` // DB 매핑에서 완전히 제외
 }
 
 type Folder struct {
 	ID          int64  `db:"id"`
-	Path        string `db:"path"`
-	TotalSize   int64  `db:"total_size"`
-	FileCount   int64  `db:"file_count"`
+	Path        string `db:"path"`         // 정리된(filepath.Clean) 전체 경로. 깊이가 달라도 겹치지 않음
+	TotalSize   int64  `db:"total_size"`   // GetFolderTree 로 찾은 경우 하위 폴더의 파일까지 포함
+	FileCount   int64  `db:"file_count"`   // GetFolderTree 로 찾은 경우 하위 폴더의 파일까지 포함
 	CreatedTime string `db:"created_time"` // string 으로 해도 충분
+	Depth       int    `db:"-"`            // RootDir 바로 아래가 1
+	Block       bool   `db:"-"`            // FileBlock 으로 만들 폴더인지
+	RuleDir     string `db:"-"`            // FileBlock 을 만들 때 읽을 rule.json 의 폴더 (없으면 빈 문자열)
+	// NewestModTime 폴더 안(GetFolderTree 로 찾은 경우 하위 폴더 포함) 파일 중 가장 최근 수정 시각 (UTC). 파일이 없으면 zero.
+	NewestModTime time.Time `db:"newest_mtime"`
+	// DirModTime, DirInode 폴더 자체의 수정 시각(UTC)과 inode. 폴더에 항목이 생기거나 지워지거나 이름이 바뀌면 바뀜.
+	// 다시 읽지 않아도 되는 폴더를 찾는 데 씀 (readFolder 참고). 믿을 수 없는 수정 시각이면 zero.
+	DirModTime time.Time `db:"dir_mtime"`
+	DirInode   uint64    `db:"dir_inode"`
 }
 
 // FolderDiff 는 디스크와 DB의 Folder 통계가 다른 경우의 차이를 나타냄.
@@ -33,6 +52,22 @@ type FolderDiff struct {
 	DBTotalSize   int64  // DB에 저장된 총 크기
 	DiskFileCount int64  // 디스크상의 파일 개수
 	DBFileCount   int64  // DB에 저장된 파일 개수
+	// DiskNewestModTime, DBNewestModTime 폴더 안 파일 중 가장 최근 수정 시각.
+	DiskNewestModTime time.Time
+	DBNewestModTime   time.Time
+	// DiskDirModTime, DBDirModTime, DiskDirInode, DBDirInode 폴더 자체의 수정 시각과 inode.
+	DiskDirModTime time.Time
+	DBDirModTime   time.Time
+	DiskDirInode   uint64
+	DBDirInode     uint64
+	// Removed DB 에는 있지만 디스크에서 없어진 폴더. Disk 값은 zero 임.
+	Removed bool
+}
+
+// StatsChanged 폴더가 새로 생겼거나 없어졌거나 크기나 파일 수가 바뀌었는지. 가장 최근 수정 시각만 다르면 false.
+// 수정 시각만 바뀐 파일은 FileChange 로 따로 판단하므로, 이 값으로 FileBlock 을 다시 만들지 정함.
+func (fd *FolderDiff) StatsChanged() bool {
+	return fd.FolderID == 0 || fd.Removed || fd.DiskTotalSize != fd.DBTotalSize || fd.DiskFileCount != fd.DBFileCount
 }
 
 // FileChange 는 특정 Folder 내에서 디스크와 DB의 파일 정보가 다를 경우 그 차이를 나타냄.
@@ -41,7 +76,7 @@ type FolderDiff struct {
 // 지금 키가 되는 FileId, FolderId 자체가 들어가지 않으니, 이건 FileChange 만들때 그냥 빈공가느로 남겨두자, 향후 쓰일 수도 있으니. Ptah 를 넣자.
 // DB 자체를 건드는게 아님. 중요.
 type FileChange struct {
-	ChangeType string // "added", "removed", "modified"
+	ChangeType string // "added", "removed", "modified", "renamed", "checksum", "mtime"
 	// DB에 이미 존재하는 파일의 경우 FileID와 FolderID를 기록합니다.
 	FileID   int64
 	FolderID int64
@@ -49,18 +84,76 @@ type FileChange struct {
 	DiskSize int64  // 디스크상의 파일 크기
 	DBSize   int64  // DB에 저장된 파일 크기 (추가된 경우 0)
 	Path     string // 파일이 속한 폴더의 경로
+	// DiskChecksum, DiskChecksumModTime 디스크 파일의 checksum 과 계산 시점의 수정 시각. checksum 을 쓰지 않으면 빈 값.
+	DiskChecksum        string
+	DiskChecksumModTime int64
+	DBChecksum          string    // DB에 저장된 checksum
+	DiskModTime         time.Time // 디스크상의 수정 시각 (삭제된 경우 zero)
+	DBModTime           time.Time // DB에 저장된 수정 시각 (추가된 경우 zero)
+	DiskInode           uint64    // 디스크상의 inode (삭제된 경우 0)
+	DBInode             uint64    // DB에 저장된 inode (추가된 경우 0)
+	// OldPath, OldName "renamed" 인 경우 옮기기 전의 폴더 경로와 파일 이름. Path, Name 은 옮긴 뒤의 값.
+	OldPath string
+	OldName string
 }
 
-// UpsertFolder FolderDiff 정보를 기반으로 DB의 폴더 정보를 업데이트하거나, 없으면 삽입
+// ChangeRenamed 이름이 바뀌었거나 다른 폴더로 옮겨진 파일. DB 의 파일 레코드(ID)는 그대로 두고 위치만 바꿈.
+const ChangeRenamed = "renamed"
+
+// FileChange.ChangeType 값 중 FileBlock 을 다시 만들 필요가 없는 것.
+const (
+	// ChangeChecksum 내용은 그대로이고 DB 의 checksum 만 새로 계산한 값으로 바꿈.
+	// checksum 을 처음 켰거나 알고리즘을 바꿨을 때, 또는 내용은 같은데 수정 시각만 바뀌었을 때 생김.
+	ChangeChecksum = "checksum"
+	// ChangeModTime 크기(와 checksum)가 같은 파일의 수정 시각과 inode 만 DB 에 채움.
+	// 수정 시각이나 inode 를 저장하기 전의 DB 이거나, 내용은 그대로인데 inode 만 바뀌었을 때 생김.
+	ChangeModTime = "mtime"
+)
+
+// ContentChanged changes 중에 FileBlock 을 다시 만들어야 하는 변경이 있는지.
+func ContentChanged(changes []FileChange) bool {
+	for _, c := range changes {
+		if c.ChangeType != ChangeChecksum && c.ChangeType != ChangeModTime {
+			return true
+		}
+	}
+	return false
+}
+
+// FolderStatsChanged diffs 중에 StatsChanged 인 것이 있는지.
+func FolderStatsChanged(diffs []FolderDiff) bool {
+	for i := range diffs {
+		if diffs[i].StatsChanged() {
+			return true
+		}
+	}
+	return false
+}
+
+// dbTime t 를 UTC 로 DB 에 저장할 값. zero 면 NULL.
+func dbTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
+
+// UpsertFolder FolderDiff 정보를 기반으로 DB의 폴더 정보를 업데이트하거나, 없으면 삽입. Removed 면 폴더를 지움
 func (fd *FolderDiff) UpsertFolder(ctx context.Context, db *sql.DB) error {
+	if fd.Removed {
+		// 폴더를 지우면 남은 파일 레코드도 ON DELETE CASCADE 로 같이 지워짐.
+		if err := execSQL(ctx, db, "delete_folder.sql", fd.FolderID); err != nil {
+			return fmt.Errorf("failed to delete folder id %d, path %s: %w", fd.FolderID, fd.Path, err)
+		}
+		return nil
+	}
 	if fd.FolderID == 0 {
 		// DB에 해당 폴더 정보가 없는 경우: 새 레코드 삽입 (FolderID는 추후 별도 조회로 반영 가능)
-		if err := execSQL(ctx, db, "insert_folder.sql", fd.Path, fd.DiskTotalSize, fd.DiskFileCount); err != nil {
+		if err := execSQL(ctx, db, "insert_folder.sql", fd.Path, fd.DiskTotalSize, fd.DiskFileCount, dbTime(fd.DiskNewestModTime),
+			dbTime(fd.DiskDirModTime), int64(fd.DiskDirInode)); err != nil {
 			return fmt.Errorf("failed to insert folder for path %s: %w", fd.Path, err)
 		}
 	} else {
 		// DB에 해당 폴더 정보가 있는 경우: 업데이트
-		if err := execSQL(ctx, db, "update_folder.sql", fd.DiskTotalSize, fd.DiskFileCount, fd.FolderID); err != nil {
+		if err := execSQL(ctx, db, "update_folder.sql", fd.DiskTotalSize, fd.DiskFileCount, dbTime(fd.DiskNewestModTime),
+			dbTime(fd.DiskDirModTime), int64(fd.DiskDirInode), fd.FolderID); err != nil {
 			return fmt.Errorf("failed to update folder id %d, path %s: %w", fd.FolderID, fd.Path, err)
 		}
 	}
@@ -71,13 +164,31 @@ func (fd *FolderDiff) UpsertFolder(ctx context.Context, db *sql.DB) error {
 func (fc *FileChange) UpsertDelFile(ctx context.Context, db *sql.DB) error {
 	switch fc.ChangeType {
 	case "added":
-		if err := execSQL(ctx, db, "insert_file.sql", fc.FolderID, fc.Name, fc.DiskSize); err != nil {
+		if err := execSQL(ctx, db, "insert_file.sql", fc.FolderID, fc.Name, fc.DiskSize, fc.DiskChecksum, fc.DiskChecksumModTime, dbTime(fc.DiskModTime), int64(fc.DiskInode)); err != nil {
 			return fmt.Errorf("failed to insert file %s: %w", fc.Name, err)
 		}
 	case "modified":
-		if err := execSQL(ctx, db, "update_file.sql", fc.DiskSize, fc.FileID); err != nil {
+		if err := execSQL(ctx, db, "update_file.sql", fc.DiskSize, fc.DiskChecksum, fc.DiskChecksumModTime, dbTime(fc.DiskModTime), int64(fc.DiskInode), fc.FileID); err != nil {
 			return fmt.Errorf("failed to update file %s: %w", fc.Name, err)
 		}
+	case ChangeChecksum:
+		if err := execSQL(ctx, db, "update_file_checksum.sql", fc.DiskChecksum, fc.DiskChecksumModTime, dbTime(fc.DiskModTime), int64(fc.DiskInode), fc.FileID); err != nil {
+			return fmt.Errorf("failed to update checksum of file %s: %w", fc.Name, err)
+		}
+	case ChangeModTime:
+		if err := execSQL(ctx, db, "update_file_mtime.sql", dbTime(fc.DiskModTime), int64(fc.DiskInode), fc.FileID); err != nil {
+			return fmt.Errorf("failed to update mtime of file %s: %w", fc.Name, err)
+		}
+	case ChangeRenamed:
+		if err := execSQL(ctx, db, "rename_file.sql", fc.FolderID, fc.Name, dbTime(fc.DiskModTime), int64(fc.DiskInode), fc.FileID); err != nil {
+			return fmt.Errorf("failed to rename file %s to %s: %w", filepath.Join(fc.OldPath, fc.OldName), filepath.Join(fc.Path, fc.Name), err)
+		}
+		// 옮긴 뒤 checksum 을 새로 계산했으면 같이 저장함. 계산하지 않았으면 이전 checksum 을 그대로 둠.
+		if fc.DiskChecksum != "" {
+			if err := execSQL(ctx, db, "update_file_checksum.sql", fc.DiskChecksum, fc.DiskChecksumModTime, dbTime(fc.DiskModTime), int64(fc.DiskInode), fc.FileID); err != nil {
+				return fmt.Errorf("failed to update checksum of file %s: %w", fc.Name, err)
+			}
+		}
 	case "removed":
 		if err := execSQL(ctx, db, "delete_file.sql", fc.FileID); err != nil {
 			return fmt.Errorf("failed to delete file %s: %w", fc.Name, err)
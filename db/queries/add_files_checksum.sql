@@ -0,0 +1,2 @@
+ALTER TABLE files ADD COLUMN checksum TEXT NOT NULL DEFAULT '';
+ALTER TABLE files ADD COLUMN checksum_mtime INTEGER NOT NULL DEFAULT 0;
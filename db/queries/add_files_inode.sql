@@ -0,0 +1,3 @@
+ALTER TABLE files ADD COLUMN inode INTEGER NOT NULL DEFAULT 0;
+-- 다음 sync 에서 모든 폴더를 한 번 비교해서 이미 있는 파일의 inode 를 채우도록 함.
+UPDATE folders SET newest_mtime = NULL;
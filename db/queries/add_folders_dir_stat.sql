@@ -0,0 +1,2 @@
+ALTER TABLE folders ADD COLUMN dir_mtime DATETIME;
+ALTER TABLE folders ADD COLUMN dir_inode INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE files ADD COLUMN mtime DATETIME;
+ALTER TABLE folders ADD COLUMN newest_mtime DATETIME;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS datablock_versions (
+                                                  generation INTEGER PRIMARY KEY,
+                                                  content_hash TEXT NOT NULL,
+                                                  updated_at TEXT NOT NULL,
+                                                  created_time DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_datablock_versions_hash ON datablock_versions(content_hash);
@@ -0,0 +1,2 @@
+DELETE FROM folders
+WHERE id = ?;
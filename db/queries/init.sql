@@ -3,7 +3,10 @@ CREATE TABLE IF NOT EXISTS folders (
                                        path TEXT NOT NULL UNIQUE,
                                        total_size INTEGER DEFAULT 0,
                                        file_count INTEGER DEFAULT 0,
-                                       created_time DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+                                       created_time DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+                                       newest_mtime DATETIME,
+                                       dir_mtime DATETIME,
+                                       dir_inode INTEGER NOT NULL DEFAULT 0
 );
 
 CREATE TABLE IF NOT EXISTS files (
@@ -12,6 +15,10 @@ CREATE TABLE IF NOT EXISTS files (
                                      name TEXT NOT NULL,
                                      size INTEGER NOT NULL,
                                      created_time DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+                                     checksum TEXT NOT NULL DEFAULT '',
+                                     checksum_mtime INTEGER NOT NULL DEFAULT 0,
+                                     mtime DATETIME,
+                                     inode INTEGER NOT NULL DEFAULT 0,
                                      FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE,
     UNIQUE(folder_id, name)
 );
@@ -0,0 +1,2 @@
+INSERT INTO datablock_versions (generation, content_hash, updated_at)
+VALUES (?, ?, ?);
@@ -1,4 +1,3 @@
-INSERT INTO files (folder_id, name, size)
-VALUES (?, ?, ?)
+INSERT INTO files (folder_id, name, size, checksum, checksum_mtime, mtime, inode)
+VALUES (?, ?, ?, ?, ?, ?, ?)
     ON CONFLICT(folder_id, name) DO NOTHING;
-
@@ -1,3 +1,3 @@
-INSERT INTO folders (path, total_size, file_count)
-VALUES (?, ?, ?)
+INSERT INTO folders (path, total_size, file_count, newest_mtime, dir_mtime, dir_inode)
+VALUES (?, ?, ?, ?, ?, ?)
     ON CONFLICT(path) DO NOTHING;
@@ -0,0 +1,3 @@
+UPDATE files
+SET folder_id = ?, name = ?, mtime = ?, inode = ?
+WHERE id = ?;
\ No newline at end of file
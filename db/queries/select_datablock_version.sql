@@ -0,0 +1,5 @@
+SELECT generation, content_hash, updated_at
+FROM datablock_versions
+WHERE (?1 <> 0 AND generation = ?1) OR (?2 <> '' AND content_hash = ?2)
+ORDER BY generation DESC
+LIMIT 1;
@@ -0,0 +1,4 @@
+SELECT f.checksum, f.checksum_mtime
+FROM files f
+         JOIN folders fo ON f.folder_id = fo.id
+WHERE fo.path = ? AND f.name = ?
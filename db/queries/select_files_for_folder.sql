@@ -1,4 +1,4 @@
-SELECT f.id, f.folder_id, f.name, f.size, f.created_time
+SELECT f.id, f.folder_id, f.name, f.size, f.created_time, f.checksum, f.checksum_mtime, f.mtime, f.inode
 FROM files f
          JOIN folders fo ON f.folder_id = fo.id
 WHERE fo.path = ?
@@ -0,0 +1,4 @@
+SELECT f.id, f.folder_id, fo.path, f.name, f.size, f.created_time, f.checksum, f.checksum_mtime, f.mtime, f.inode
+FROM files f
+         JOIN folders fo ON f.folder_id = fo.id
+ORDER BY fo.path, f.name
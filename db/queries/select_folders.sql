@@ -1,2 +1,2 @@
-SELECT id, path, total_size, file_count, created_time
-FROM folders;
\ No newline at end of file
+SELECT id, path, total_size, file_count, created_time, newest_mtime, dir_mtime, dir_inode
+FROM folders;
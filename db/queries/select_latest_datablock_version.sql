@@ -0,0 +1,4 @@
+SELECT generation, content_hash, updated_at
+FROM datablock_versions
+ORDER BY generation DESC
+LIMIT 1;
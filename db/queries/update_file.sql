@@ -1,3 +1,3 @@
 UPDATE files
-SET size = ?
+SET size = ?, checksum = ?, checksum_mtime = ?, mtime = ?, inode = ?
 WHERE id = ?;
\ No newline at end of file
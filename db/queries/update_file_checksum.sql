@@ -0,0 +1,3 @@
+UPDATE files
+SET checksum = ?, checksum_mtime = ?, mtime = ?, inode = ?
+WHERE id = ?;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE files
+SET mtime = ?, inode = ?
+WHERE id = ?;
\ No newline at end of file
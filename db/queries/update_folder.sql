@@ -1,3 +1,3 @@
 UPDATE folders
-SET total_size = ?, file_count = ?
+SET total_size = ?, file_count = ?, newest_mtime = ?, dir_mtime = ?, dir_inode = ?
 WHERE id = ?
\ No newline at end of file
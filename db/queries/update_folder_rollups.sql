@@ -0,0 +1,17 @@
+UPDATE folders
+SET total_size = (SELECT IFNULL(SUM(f.size), 0)
+                  FROM files f
+                           JOIN folders d ON f.folder_id = d.id
+                  WHERE d.path = folders.path
+                     OR substr(d.path, 1, length(folders.path) + 1) = folders.path || '/'),
+    file_count = (SELECT COUNT(*)
+                  FROM files f
+                           JOIN folders d ON f.folder_id = d.id
+                  WHERE d.path = folders.path
+                     OR substr(d.path, 1, length(folders.path) + 1) = folders.path || '/'),
+    -- mtime 은 모두 UTC 로 같은 형식이므로 문자열 MAX 가 가장 최근 시각임.
+    newest_mtime = (SELECT MAX(f.mtime)
+                    FROM files f
+                             JOIN folders d ON f.folder_id = d.id
+                    WHERE d.path = folders.path
+                       OR substr(d.path, 1, length(folders.path) + 1) = folders.path || '/');
@@ -1,4 +1,5 @@
 UPDATE folders
 SET total_size = (SELECT IFNULL(SUM(files.size), 0) FROM files WHERE files.folder_id = folders.id),
-    file_count = (SELECT COUNT(*) FROM files WHERE files.folder_id = folders.id)
+    file_count = (SELECT COUNT(*) FROM files WHERE files.folder_id = folders.id),
+    newest_mtime = (SELECT MAX(files.mtime) FROM files WHERE files.folder_id = folders.id)
 WHERE id = ?;
@@ -0,0 +1,93 @@
+package db
+
+// detectRenames changes 에서 같은 파일로 보이는 "removed" 와 "added" 를 하나의 ChangeRenamed 로 합침.
+// 여러 폴더의 변경을 같이 넘기면 다른 폴더로 옮겨진 파일도 찾음. 크기가 같고, inode 와 수정 시각이 같거나
+// checksum 이 같으면 같은 파일로 봄. inode 로 먼저 맞추고, 남은 것을 checksum 으로 맞춤.
+func detectRenames(changes []FileChange) []FileChange {
+	// 크기별 "removed" 변경의 위치
+	removedBySize := make(map[int64][]int)
+	for i, c := range changes {
+		if c.ChangeType == "removed" {
+			removedBySize[c.DBSize] = append(removedBySize[c.DBSize], i)
+		}
+	}
+	if len(removedBySize) == 0 {
+		return changes
+	}
+
+	matched := make(map[int]bool) // 짝을 찾은 "removed" 의 위치
+	renamed := make(map[int]int)  // "added" 의 위치 -> 짝이 된 "removed" 의 위치
+	for _, same := range []func(removed, added FileChange) bool{sameInode, sameChecksum} {
+		for i, c := range changes {
+			if c.ChangeType != "added" {
+				continue
+			}
+			if _, ok := renamed[i]; ok {
+				continue
+			}
+			for _, r := range removedBySize[c.DiskSize] {
+				if !matched[r] && same(changes[r], c) {
+					matched[r] = true
+					renamed[i] = r
+					break
+				}
+			}
+		}
+	}
+	if len(renamed) == 0 {
+		return changes
+	}
+
+	result := make([]FileChange, 0, len(changes)-len(renamed))
+	for i, c := range changes {
+		if matched[i] {
+			continue
+		}
+		if r, ok := renamed[i]; ok {
+			c = renameChange(changes[r], c)
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// sameInode 옮긴 파일은 inode 와 수정 시각이 그대로임. inode 를 알 수 없으면 false.
+func sameInode(removed, added FileChange) bool {
+	return removed.DBInode != 0 && removed.DBInode == added.DiskInode && removed.DBModTime.Equal(added.DiskModTime)
+}
+
+// sameChecksum 다른 파일 시스템으로 옮겨서 inode 가 바뀐 경우는 내용 checksum 으로 찾음. checksum 을 쓰지 않으면 false.
+func sameChecksum(removed, added FileChange) bool {
+	return removed.DBChecksum != "" && removed.DBChecksum == added.DiskChecksum
+}
+
+// renameChange removed 의 DB 레코드를 added 의 위치로 옮기는 ChangeRenamed.
+func renameChange(removed, added FileChange) FileChange {
+	change := added
+	change.ChangeType = ChangeRenamed
+	change.FileID = removed.FileID
+	change.DBSize = removed.DBSize
+	change.DBChecksum = removed.DBChecksum
+	change.DBModTime = removed.DBModTime
+	change.DBInode = removed.DBInode
+	change.OldPath = removed.Path
+	change.OldName = removed.Name
+	return change
+}
+
+// sameFileNames 디스크와 DB 의 파일 이름 목록이 같은지.
+func sameFileNames(diskFiles, dbFiles []File) bool {
+	if len(diskFiles) != len(dbFiles) {
+		return false
+	}
+	names := make(map[string]bool, len(dbFiles))
+	for _, f := range dbFiles {
+		names[f.Name] = true
+	}
+	for _, f := range diskFiles {
+		if !names[f.Name] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,315 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDetectRenames(t *testing.T) {
+	mtime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	changes := []FileChange{
+		// 다른 폴더로 옮긴 파일: inode 와 수정 시각이 같음.
+		{ChangeType: "removed", FileID: 1, Name: "s1_R1.fastq", Path: "/data/a", DBSize: 10, DBModTime: mtime, DBInode: 100},
+		{ChangeType: "added", Name: "s1_R1.fastq", Path: "/data/b", DiskSize: 10, DiskModTime: mtime, DiskInode: 100},
+		// 다른 파일 시스템에서 복사한 파일: inode 는 다르지만 checksum 이 같음.
+		{ChangeType: "removed", FileID: 2, Name: "s2_R1.fastq", Path: "/data/a", DBSize: 20, DBChecksum: "sha256:ab", DBInode: 200},
+		{ChangeType: "added", Name: "s9_R1.fastq", Path: "/data/a", DiskSize: 20, DiskChecksum: "sha256:ab", DiskInode: 900},
+		// 크기가 다르면 같은 inode 라도 다른 파일로 봄.
+		{ChangeType: "removed", FileID: 3, Name: "s3_R1.fastq", Path: "/data/a", DBSize: 30, DBModTime: mtime, DBInode: 300},
+		{ChangeType: "added", Name: "s3_R1.fastq", Path: "/data/c", DiskSize: 31, DiskModTime: mtime, DiskInode: 300},
+	}
+
+	got := detectRenames(changes)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 changes, got %+v", got)
+	}
+	byName := make(map[string]FileChange)
+	for _, c := range got {
+		byName[c.ChangeType+":"+filepath.Join(c.Path, c.Name)] = c
+	}
+	moved, ok := byName["renamed:/data/b/s1_R1.fastq"]
+	if !ok || moved.FileID != 1 || moved.OldPath != "/data/a" || moved.OldName != "s1_R1.fastq" {
+		t.Errorf("expected move of file 1 from /data/a, got %+v", moved)
+	}
+	copied, ok := byName["renamed:/data/a/s9_R1.fastq"]
+	if !ok || copied.FileID != 2 || copied.OldName != "s2_R1.fastq" || copied.DiskInode != 900 {
+		t.Errorf("expected rename of file 2 matched by checksum, got %+v", copied)
+	}
+	if _, ok := byName["removed:/data/a/s3_R1.fastq"]; !ok {
+		t.Errorf("expected file 3 to stay removed, got %+v", got)
+	}
+	if _, ok := byName["added:/data/c/s3_R1.fastq"]; !ok {
+		t.Errorf("expected resized file to stay added, got %+v", got)
+	}
+}
+
+func TestSyncFolders_Rename(t *testing.T) {
+	// exec_sqlmock_test 에서 sqlFiles 를 바꿔 놓을 수 있으므로 embed 된 쿼리로 되돌림.
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
+	rootDir := setupNestedRoot(t)
+	sampleA := filepath.Join(rootDir, "project/run1/sampleA")
+	sampleB := filepath.Join(rootDir, "project/run1/sampleB")
+	run2 := filepath.Join(rootDir, "project/run2")
+	// sampleB 에서 한 쌍을 옮긴 뒤에도 sampleB 가 비지 않도록 한 쌍 더 둠.
+	for _, name := range []string{"s5_R1.txt", "s5_R2.txt"} {
+		if err := os.WriteFile(filepath.Join(sampleB, name), []byte("xxx"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	db, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer db.Close()
+	if err := InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase: %v", err)
+	}
+	ctx := context.Background()
+	exclusions := []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+	if err := SaveFolderTree(ctx, db, rootDir, 3, nil, exclusions); err != nil {
+		t.Fatalf("SaveFolderTree: %v", err)
+	}
+	opts := SyncOptions{MaxDepth: 3}
+	if _, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts); err != nil {
+		t.Fatalf("first SyncFolders: %v", err)
+	}
+	fileID := func(folder, name string) int64 {
+		t.Helper()
+		var id int64
+		err := db.QueryRow("SELECT f.id FROM files f JOIN folders fo ON f.folder_id = fo.id WHERE fo.path = ? AND f.name = ?", folder, name).Scan(&id)
+		if err != nil {
+			t.Fatalf("query %s/%s: %v", folder, name, err)
+		}
+		return id
+	}
+	renamedID := fileID(sampleA, "s1_R1.txt")
+	movedID := fileID(sampleB, "s2_R1.txt")
+
+	// sampleA 안에서 이름을 바꾸고, sampleB 의 한 쌍을 sampleA 로 옮기고, run2 의 파일은 지움.
+	moves := map[string]string{
+		filepath.Join(sampleA, "s1_R1.txt"): filepath.Join(sampleA, "s9_R1.txt"),
+		filepath.Join(sampleA, "s1_R2.txt"): filepath.Join(sampleA, "s9_R2.txt"),
+		filepath.Join(sampleB, "s2_R1.txt"): filepath.Join(sampleA, "s2_R1.txt"),
+		filepath.Join(sampleB, "s2_R2.txt"): filepath.Join(sampleA, "s2_R2.txt"),
+	}
+	for from, to := range moves {
+		if err := os.Rename(from, to); err != nil {
+			t.Fatalf("rename: %v", err)
+		}
+	}
+	for _, name := range []string{"s3_R1.txt", "s3_R2.txt"} {
+		if err := os.Remove(filepath.Join(run2, name)); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+	}
+
+	_, _, changes, err := DiffFolderTree(db, rootDir, nil, exclusions, opts)
+	if err != nil {
+		t.Fatalf("DiffFolderTree: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.ChangeType]++
+		if c.ChangeType == ChangeRenamed && c.FileID == movedID && (c.OldPath != sampleB || c.Path != sampleA) {
+			t.Errorf("expected move from %s to %s, got %+v", sampleB, sampleA, c)
+		}
+		if c.ChangeType == "removed" && c.Path != run2 {
+			t.Errorf("expected removed file to carry its folder path, got %+v", c)
+		}
+	}
+	if counts[ChangeRenamed] != 4 || counts["removed"] != 2 || counts["added"] != 0 {
+		t.Fatalf("expected 4 renamed and 2 removed, got %v", counts)
+	}
+
+	updated, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || !updated {
+		t.Fatalf("SyncFolders after rename: updated=%v err=%v", updated, err)
+	}
+	if id := fileID(sampleA, "s9_R1.txt"); id != renamedID {
+		t.Errorf("expected renamed file to keep id %d, got %d", renamedID, id)
+	}
+	if id := fileID(sampleA, "s2_R1.txt"); id != movedID {
+		t.Errorf("expected moved file to keep id %d, got %d", movedID, id)
+	}
+	var left int
+	if err := db.QueryRow("SELECT COUNT(*) FROM files f JOIN folders fo ON f.folder_id = fo.id WHERE fo.path = ?", run2).Scan(&left); err != nil {
+		t.Fatalf("count run2 files: %v", err)
+	}
+	if left != 0 {
+		t.Errorf("expected removed files to be deleted, %d left", left)
+	}
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || updated {
+		t.Fatalf("SyncFolders after rename was applied: updated=%v err=%v", updated, err)
+	}
+
+	// 이름만 바뀌면 폴더의 크기, 파일 수, 가장 최근 수정 시각이 그대로여도 찾아야 함.
+	pairID := fileID(sampleB, "s5_R1.txt")
+	for _, part := range []string{"R1", "R2"} {
+		if err := os.Rename(filepath.Join(sampleB, "s5_"+part+".txt"), filepath.Join(sampleB, "s6_"+part+".txt")); err != nil {
+			t.Fatalf("rename: %v", err)
+		}
+	}
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || !updated {
+		t.Fatalf("SyncFolders after rename in place: updated=%v err=%v", updated, err)
+	}
+	if id := fileID(sampleB, "s6_R1.txt"); id != pairID {
+		t.Errorf("expected renamed file to keep id %d, got %d", pairID, id)
+	}
+}
+
+func TestSyncFolders_MoveThenRemoveFolder(t *testing.T) {
+	// exec_sqlmock_test 에서 sqlFiles 를 바꿔 놓을 수 있으므로 embed 된 쿼리로 되돌림.
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
+	rootDir := setupNestedRoot(t)
+	sampleA := filepath.Join(rootDir, "project/run1/sampleA")
+	sampleB := filepath.Join(rootDir, "project/run1/sampleB")
+	db, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer db.Close()
+	if err := InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase: %v", err)
+	}
+	ctx := context.Background()
+	exclusions := []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+	if err := SaveFolderTree(ctx, db, rootDir, 3, nil, exclusions); err != nil {
+		t.Fatalf("SaveFolderTree: %v", err)
+	}
+	opts := SyncOptions{MaxDepth: 3}
+	if _, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts); err != nil {
+		t.Fatalf("first SyncFolders: %v", err)
+	}
+	var movedID int64
+	err = db.QueryRow("SELECT f.id FROM files f JOIN folders fo ON f.folder_id = fo.id WHERE fo.path = ? AND f.name = ?", sampleB, "s2_R1.txt").Scan(&movedID)
+	if err != nil {
+		t.Fatalf("query moved file: %v", err)
+	}
+
+	// sampleB 의 파일을 sampleA 로 옮기고, sync 가 만든 FileBlock 이 남은 sampleB 를 지움.
+	for _, name := range []string{"s2_R1.txt", "s2_R2.txt"} {
+		if err := os.Rename(filepath.Join(sampleB, name), filepath.Join(sampleA, name)); err != nil {
+			t.Fatalf("rename: %v", err)
+		}
+	}
+	if err := os.RemoveAll(sampleB); err != nil {
+		t.Fatalf("remove folder: %v", err)
+	}
+
+	_, diffs, changes, err := DiffFolderTree(db, rootDir, nil, exclusions, opts)
+	if err != nil {
+		t.Fatalf("DiffFolderTree: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.ChangeType]++
+		if c.ChangeType == ChangeRenamed && (c.OldPath != sampleB || c.Path != sampleA) {
+			t.Errorf("expected move from %s to %s, got %+v", sampleB, sampleA, c)
+		}
+	}
+	if counts[ChangeRenamed] != 2 || len(changes) != 2 {
+		t.Fatalf("expected 2 renamed, got %v", counts)
+	}
+	removed := false
+	for _, d := range diffs {
+		if d.Path == sampleB && d.Removed {
+			removed = true
+		}
+	}
+	if !removed {
+		t.Errorf("expected %s to be reported as removed, got %+v", sampleB, diffs)
+	}
+
+	updated, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || !updated {
+		t.Fatalf("SyncFolders after move: updated=%v err=%v", updated, err)
+	}
+	var id int64
+	err = db.QueryRow("SELECT f.id FROM files f JOIN folders fo ON f.folder_id = fo.id WHERE fo.path = ? AND f.name = ?", sampleA, "s2_R1.txt").Scan(&id)
+	if err != nil || id != movedID {
+		t.Errorf("expected moved file to keep id %d, got %d (%v)", movedID, id, err)
+	}
+	var left int
+	if err := db.QueryRow("SELECT COUNT(*) FROM folders WHERE path = ?", sampleB).Scan(&left); err != nil {
+		t.Fatalf("count folders: %v", err)
+	}
+	if left != 0 {
+		t.Errorf("expected removed folder to be deleted")
+	}
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || updated {
+		t.Fatalf("SyncFolders after move was applied: updated=%v err=%v", updated, err)
+	}
+}
+
+func TestSyncFolders_RemoveNestedFolder(t *testing.T) {
+	// exec_sqlmock_test 에서 sqlFiles 를 바꿔 놓을 수 있으므로 embed 된 쿼리로 되돌림.
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
+	rootDir := setupNestedRoot(t)
+	run1 := filepath.Join(rootDir, "project/run1")
+	db, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer db.Close()
+	if err := InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase: %v", err)
+	}
+	ctx := context.Background()
+	exclusions := []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+	opts := SyncOptions{MaxDepth: 3}
+	if _, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts); err != nil {
+		t.Fatalf("first SyncFolders: %v", err)
+	}
+
+	// run1 과 그 아래의 sampleA, sampleB 를 한꺼번에 지움.
+	if err := os.RemoveAll(run1); err != nil {
+		t.Fatalf("remove folder: %v", err)
+	}
+	updated, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || !updated {
+		t.Fatalf("SyncFolders after remove: updated=%v err=%v", updated, err)
+	}
+
+	prefix := run1 + string(filepath.Separator) + "%"
+	var folders, files int
+	if err := db.QueryRow("SELECT COUNT(*) FROM folders WHERE path = ? OR path LIKE ?", run1, prefix).Scan(&folders); err != nil {
+		t.Fatalf("count folders: %v", err)
+	}
+	if folders != 0 {
+		t.Errorf("expected %s and its sub-folders to be deleted, %d left", run1, folders)
+	}
+	err = db.QueryRow("SELECT COUNT(*) FROM files f JOIN folders fo ON f.folder_id = fo.id WHERE fo.path = ? OR fo.path LIKE ?", run1, prefix).Scan(&files)
+	if err != nil {
+		t.Fatalf("count files: %v", err)
+	}
+	if files != 0 {
+		t.Errorf("expected files under %s to be deleted, %d left", run1, files)
+	}
+	// ON DELETE CASCADE 로 지워졌는지 폴더와 상관없이도 확인함.
+	if err := db.QueryRow("SELECT COUNT(*) FROM files").Scan(&files); err != nil {
+		t.Fatalf("count all files: %v", err)
+	}
+	if files != 2 {
+		t.Errorf("expected only the 2 files of run2 to remain, got %d", files)
+	}
+
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || updated {
+		t.Fatalf("SyncFolders after remove was applied: updated=%v err=%v", updated, err)
+	}
+}
@@ -5,37 +5,84 @@ import (
 	"database/sql"
 	"github.com/seoyhaein/tori/block"
 	globallog "github.com/seoyhaein/tori/log"
+	"github.com/seoyhaein/tori/protofile"
+	pb "github.com/seoyhaein/tori/protos"
 	"os"
 	"path/filepath"
+	"time"
 )
 
+// SyncOptions SyncFolders 의 동작을 조정하는 옵션.
+type SyncOptions struct {
+	// Force 가 true 면 DB 비교 결과가 같더라도 모든 FileBlock 과 DataBlock 을 다시 생성함.
+	// rule.json 만 수정된 경우처럼 크기/개수 비교로는 알 수 없는 변경을 반영할 때 사용.
+	// 폴더 자체가 바뀌지 않은 폴더는 읽지 않으므로, 제외 목록에서 항목을 뺐을 때도 Force 로 sync 해야 그 항목을 찾음.
+	Force bool
+	// Compression *files.pb 와 datablock.pb 를 저장할 때 쓸 압축 방식 (protofile.None, protofile.Gzip).
+	Compression string
+	// MaxDepth RootDir 아래에서 폴더를 찾을 깊이. 0 이면 DefaultMaxDepth (RootDir 바로 아래 폴더만).
+	MaxDepth int
+	// Checksum 파일 내용 비교에 쓸 checksum 알고리즘 (checksum.SHA256, checksum.XXHash). 비어 있거나 checksum.None 이면 크기와 수정 시각만 비교함.
+	// 크기, 파일 수, 가장 최근 수정 시각이 DB 와 같은 폴더는 Force 일 때만 다시 계산함.
+	Checksum string
+}
+
 // SyncFolders 는 DB 스냅샷 비교부터 DataBlock 파일 생성까지 모두 처리 TODO SyncFolders, DiffFolders 들ㅇ가는 입력 파라미터 수정할 필요 있음.
-func SyncFolders(ctx context.Context, db *sql.DB, rootPath string, foldersExclusions, filesExclusions []string) (bool, error) {
-	// 1) DiffFolders 호출
-	folderFiles, fDiff, fChange, err := DiffFolders(db, rootPath, foldersExclusions, filesExclusions)
-	if err != nil {
-		globallog.Log.Errorf("DiffFolders 실패: %v", err)
-		return false, err
-	}
+func SyncFolders(ctx context.Context, db *sql.DB, rootPath string, foldersExclusions, filesExclusions []string, opts SyncOptions) (updated bool, err error) {
+	start := time.Now()
+	defer func() {
+		result := "unchanged"
+		if err != nil {
+			result = "error"
+		} else if updated {
+			result = "updated"
+		}
+		syncDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+	}()
 
-	// 2) datablock.pb 경로 준비
+	// 1) datablock.pb 경로 준비
 	outputDatablock := filepath.Join(rootPath, "datablock.pb")
 	_, statErr := os.Stat(outputDatablock)
 	firstRun := os.IsNotExist(statErr)
 
+	// 2) DiffFolders 호출. 처음 만들 때는 DB 와 같아 보이는 폴더도 모두 비교함.
+	phaseStart := time.Now()
+	diffOpts := opts
+	diffOpts.Force = opts.Force || firstRun
+	folderFiles, fDiff, fChange, err := DiffFolderTree(db, rootPath, foldersExclusions, filesExclusions, diffOpts)
+	if err != nil {
+		globallog.Log.Errorf("DiffFolders 실패: %v", err)
+		return false, err
+	}
+	observePhase(phaseDiff, phaseStart)
+	recordScan(folderFiles, fDiff, fChange)
+
 	// 3) 업데이트 필요 여부 판단
-	needsUpdate := firstRun || !(fDiff == nil && fChange == nil)
+	needsUpdate := opts.Force || firstRun || FolderStatsChanged(fDiff) || ContentChanged(fChange)
 	if !needsUpdate {
+		// 내용은 그대로이고 checksum 이나 수정 시각만 바뀌었으면, 다음 sync 에서 다시 비교하지 않도록 DB 에만 저장함.
+		if fDiff != nil || fChange != nil {
+			if err := UpdateDB(ctx, db, fDiff, fChange); err != nil {
+				globallog.Log.Errorf("UpdateDB 실패: %v", err)
+				return false, err
+			}
+		}
 		globallog.Log.Info("all files and folders are same & datablock.pb exists; skipping update.")
 		return false, nil
 	}
 
+	if opts.Force {
+		globallog.Log.Info("force sync requested; regenerating all FileBlocks and datablock.pb")
+	}
+
 	// 4) DB 업데이트
 	if fDiff != nil || fChange != nil {
+		phaseStart = time.Now()
 		if err := UpdateDB(ctx, db, fDiff, fChange); err != nil {
 			globallog.Log.Errorf("UpdateDB 실패: %v", err)
 			return false, err
 		}
+		observePhase(phaseDBUpdate, phaseStart)
 		if ctx.Err() != nil {
 			globallog.Log.Warnf("SyncFolders 종료: 컨텍스트 취소 감지 (%v)", ctx.Err())
 			return false, ctx.Err()
@@ -43,21 +90,38 @@ func SyncFolders(ctx context.Context, db *sql.DB, rootPath string, foldersExclus
 	}
 
 	// 5) FileBlock 생성 (api 패키지로 위임)
-	fbs, err := block.GenerateFBs(folderFiles)
+	phaseStart = time.Now()
+	fbs, err := block.GenerateFolderBlocks(folderFiles, opts.Compression)
 	if err != nil {
-		globallog.Log.Errorf("GenerateFBs 실패: %v", err)
+		globallog.Log.Errorf("GenerateFolderBlocks 실패: %v", err)
 		return false, err
 	}
+	observePhase(phaseFileBlock, phaseStart)
 	if ctx.Err() != nil {
 		globallog.Log.Warnf("SyncFolders 종료: 컨텍스트 취소 감지 (%v)", ctx.Err())
 		return false, ctx.Err()
 	}
 
-	// 6) DataBlock 저장
-	if err := block.GenerateDataBlock(fbs, outputDatablock); err != nil {
-		globallog.Log.Errorf("GenerateDataBlock 실패 (%s): %v", outputDatablock, err)
+	// 6) DataBlock 에 generation 을 매겨서 저장
+	phaseStart = time.Now()
+	dataBlock, err := block.MergeDataBlock(fbs)
+	if err != nil {
+		globallog.Log.Errorf("MergeDataBlock 실패: %v", err)
+		return false, err
+	}
+	var floor uint64
+	if !firstRun {
+		floor = diskGeneration(outputDatablock)
+	}
+	if err := AssignDataBlockVersion(ctx, db, dataBlock, floor); err != nil {
+		globallog.Log.Errorf("AssignDataBlockVersion 실패: %v", err)
+		return false, err
+	}
+	if err := block.SaveDataBlock(dataBlock, outputDatablock, opts.Compression); err != nil {
+		globallog.Log.Errorf("SaveDataBlock 실패 (%s): %v", outputDatablock, err)
 		return false, err
 	}
+	observePhase(phaseDataBlockWrite, phaseStart)
 	if ctx.Err() != nil {
 		globallog.Log.Warnf("SyncFolders 완료 이후 컨텍스트 취소 감지 (%v)", ctx.Err())
 		return false, ctx.Err()
@@ -65,3 +129,13 @@ func SyncFolders(ctx context.Context, db *sql.DB, rootPath string, foldersExclus
 
 	return true, nil
 }
+
+// diskGeneration 디스크에 있는 datablock.pb 의 generation. 읽을 수 없으면 0.
+func diskGeneration(path string) uint64 {
+	dataBlock := &pb.DataBlock{}
+	if err := protofile.Load(path, dataBlock); err != nil {
+		globallog.Log.Warnf("failed to read generation from %s: %v", path, err)
+		return 0
+	}
+	return dataBlock.GetGeneration()
+}
@@ -0,0 +1,270 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/seoyhaein/tori/checksum"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setupSyncRoot rootDir/sample 폴더에 rule.json 과 샘플 파일을 만들고, 스냅샷이 저장된 DB 를 반환함.
+func setupSyncRoot(t *testing.T) (string, string) {
+	t.Helper()
+	rootDir := t.TempDir()
+	folder := filepath.Join(rootDir, "sample")
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	rs := map[string]any{
+		"version":     "1",
+		"delimiter":   []string{"_", ".txt"},
+		"header":      []string{"R1", "R2"},
+		"rowRules":    map[string]any{"matchParts": []int{0}},
+		"columnRules": map[string]any{"matchParts": []int{1}},
+		"sizeRules":   map[string]any{"minSize": 0, "maxSize": 1000},
+	}
+	b, _ := json.Marshal(rs)
+	if err := os.WriteFile(filepath.Join(folder, "rule.json"), b, 0644); err != nil {
+		t.Fatalf("write rule.json: %v", err)
+	}
+	for _, f := range []string{"s1_R1.txt", "s1_R2.txt"} {
+		if err := os.WriteFile(filepath.Join(folder, f), []byte("x"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	return rootDir, folder
+}
+
+func TestSyncFolders_Force(t *testing.T) {
+	// exec_sqlmock_test 에서 sqlFiles 를 바꿔 놓을 수 있으므로 embed 된 쿼리로 되돌림.
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
+	rootDir, folder := setupSyncRoot(t)
+	db, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer db.Close()
+	if err := InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase: %v", err)
+	}
+	ctx := context.Background()
+	exclusions := []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+	if err := SaveFolders(ctx, db, rootDir, nil, exclusions); err != nil {
+		t.Fatalf("SaveFolders: %v", err)
+	}
+
+	// 첫 실행은 datablock.pb 가 없으므로 생성됨.
+	updated, err := SyncFolders(ctx, db, rootDir, nil, exclusions, SyncOptions{})
+	if err != nil || !updated {
+		t.Fatalf("first SyncFolders: updated=%v err=%v", updated, err)
+	}
+	dataBlockPath := filepath.Join(rootDir, "datablock.pb")
+	before, err := os.Stat(dataBlockPath)
+	if err != nil {
+		t.Fatalf("stat datablock.pb: %v", err)
+	}
+
+	// rule.json 만 바꾸면 파일 크기/개수 비교로는 알 수 없으므로 일반 sync 는 건너뜀.
+	time.Sleep(10 * time.Millisecond)
+	rule, _ := os.ReadFile(filepath.Join(folder, "rule.json"))
+	if err := os.WriteFile(filepath.Join(folder, "rule.json"), append(rule, '\n'), 0644); err != nil {
+		t.Fatalf("rewrite rule.json: %v", err)
+	}
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, SyncOptions{})
+	if err != nil {
+		t.Fatalf("second SyncFolders: %v", err)
+	}
+	if updated {
+		t.Fatalf("expected unforced sync to be skipped")
+	}
+
+	// force 면 변경이 없어도 다시 생성함.
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, SyncOptions{Force: true})
+	if err != nil || !updated {
+		t.Fatalf("forced SyncFolders: updated=%v err=%v", updated, err)
+	}
+	after, err := os.Stat(dataBlockPath)
+	if err != nil {
+		t.Fatalf("stat datablock.pb: %v", err)
+	}
+	if !after.ModTime().After(before.ModTime()) {
+		t.Errorf("expected datablock.pb to be rewritten (before %v, after %v)", before.ModTime(), after.ModTime())
+	}
+}
+
+// TestSyncFolders_Checksum 크기가 같은 수정은 checksum 으로 찾고, 수정 시각만 바뀐 파일은 FileBlock 을 다시 만들지 않고
+// 저장된 checksum 의 수정 시각만 갱신하는지 확인함.
+func TestSyncFolders_Checksum(t *testing.T) {
+	// exec_sqlmock_test 에서 sqlFiles 를 바꿔 놓을 수 있으므로 embed 된 쿼리로 되돌림.
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
+	rootDir, folder := setupSyncRoot(t)
+	db, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer db.Close()
+	if err := InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase: %v", err)
+	}
+	ctx := context.Background()
+	exclusions := []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+	if err := SaveFolders(ctx, db, rootDir, nil, exclusions); err != nil {
+		t.Fatalf("SaveFolders: %v", err)
+	}
+	opts := SyncOptions{Checksum: checksum.SHA256}
+	if _, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts); err != nil {
+		t.Fatalf("first SyncFolders: %v", err)
+	}
+	target := filepath.Join(folder, "s1_R1.txt")
+	stored := func() (string, int64) {
+		t.Helper()
+		sum, modTime, err := GetFileChecksum(ctx, db, folder, "s1_R1.txt")
+		if err != nil {
+			t.Fatalf("GetFileChecksum: %v", err)
+		}
+		return sum, modTime
+	}
+	want, _ := checksum.File(target, checksum.SHA256)
+	if sum, _ := stored(); sum != want {
+		t.Fatalf("expected stored checksum %s, got %q", want, sum)
+	}
+
+	// 크기가 같은 내용 변경은 수정으로 처리함.
+	if err := os.WriteFile(target, []byte("y"), 0644); err != nil {
+		t.Fatalf("rewrite file: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(target, later, later); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	updated, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || !updated {
+		t.Fatalf("same-size modification: updated=%v err=%v", updated, err)
+	}
+	want, _ = checksum.File(target, checksum.SHA256)
+	if sum, _ := stored(); sum != want {
+		t.Errorf("expected checksum to be updated to %s, got %q", want, sum)
+	}
+
+	// 수정 시각만 바뀌면 FileBlock 은 그대로 두고 checksum 의 수정 시각만 저장함.
+	touched := later.Add(time.Minute)
+	if err := os.Chtimes(target, touched, touched); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || updated {
+		t.Fatalf("touch only: updated=%v err=%v", updated, err)
+	}
+	if sum, modTime := stored(); sum != want || modTime != touched.UnixNano() {
+		t.Errorf("expected checksum %s at %d, got %q at %d", want, touched.UnixNano(), sum, modTime)
+	}
+	_, _, changes, err := DiffFolderTree(db, rootDir, nil, exclusions, SyncOptions{Checksum: checksum.SHA256, Force: true})
+	if err != nil || len(changes) != 0 {
+		t.Errorf("expected no pending changes after refresh, got %+v (err %v)", changes, err)
+	}
+}
+
+// TestSyncFolders_ModTime 수정 시각만 바뀐 파일을 수정으로 찾고, 수정 시각이 없는 이전 DB 는 FileBlock 을 다시 만들지 않고 채우며,
+// 크기, 파일 수, 가장 최근 수정 시각이 같은 폴더는 파일 단위 비교를 건너뛰는지 확인함.
+func TestSyncFolders_ModTime(t *testing.T) {
+	// exec_sqlmock_test 에서 sqlFiles 를 바꿔 놓을 수 있으므로 embed 된 쿼리로 되돌림.
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
+	rootDir, folder := setupSyncRoot(t)
+	db, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer db.Close()
+	if err := InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase: %v", err)
+	}
+	ctx := context.Background()
+	exclusions := []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+	if err := SaveFolders(ctx, db, rootDir, nil, exclusions); err != nil {
+		t.Fatalf("SaveFolders: %v", err)
+	}
+	if _, err := SyncFolders(ctx, db, rootDir, nil, exclusions, SyncOptions{}); err != nil {
+		t.Fatalf("first SyncFolders: %v", err)
+	}
+	target := filepath.Join(folder, "s1_R1.txt")
+	storedModTime := func(name string) time.Time {
+		t.Helper()
+		files, err := GetFilesByPathFromDB(db, folder)
+		if err != nil {
+			t.Fatalf("GetFilesByPathFromDB: %v", err)
+		}
+		for _, f := range files {
+			if f.Name == name {
+				return f.ModTime
+			}
+		}
+		t.Fatalf("file %s not found in DB", name)
+		return time.Time{}
+	}
+
+	// 폴더에는 가장 최근 수정 시각이 UTC 로 저장됨.
+	touched := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(target, touched, touched); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	updated, err := SyncFolders(ctx, db, rootDir, nil, exclusions, SyncOptions{})
+	if err != nil || !updated {
+		t.Fatalf("touch: updated=%v err=%v", updated, err)
+	}
+	if got := storedModTime("s1_R1.txt"); !got.Equal(touched) || got.Location() != time.UTC {
+		t.Errorf("expected stored mtime %v in UTC, got %v", touched, got)
+	}
+	folders, err := GetFoldersFromDB(db)
+	if err != nil {
+		t.Fatalf("GetFoldersFromDB: %v", err)
+	}
+	if len(folders) != 1 || !folders[0].NewestModTime.Equal(touched) {
+		t.Errorf("expected folder newest_mtime %v, got %+v", touched, folders)
+	}
+
+	// 폴더 통계가 같으면 DB 의 파일 정보가 틀려도 Force 가 아닌 sync 는 파일을 비교하지 않음.
+	if _, err := db.Exec("UPDATE files SET size = 99 WHERE name = 's1_R2.txt'"); err != nil {
+		t.Fatalf("update size: %v", err)
+	}
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, SyncOptions{})
+	if err != nil || updated {
+		t.Fatalf("unchanged folder: updated=%v err=%v", updated, err)
+	}
+	if updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, SyncOptions{Force: true}); err != nil || !updated {
+		t.Fatalf("forced SyncFolders: updated=%v err=%v", updated, err)
+	}
+	_, _, changes, err := DiffFolderTree(db, rootDir, nil, exclusions, SyncOptions{Force: true})
+	if err != nil || len(changes) != 0 {
+		t.Errorf("expected forced sync to fix the file row, got %+v (err %v)", changes, err)
+	}
+
+	// 수정 시각을 저장하기 전의 DB 는 FileBlock 을 다시 만들지 않고 수정 시각만 채움.
+	if _, err := db.Exec("UPDATE files SET mtime = NULL"); err != nil {
+		t.Fatalf("clear mtime: %v", err)
+	}
+	if _, err := db.Exec("UPDATE folders SET newest_mtime = NULL"); err != nil {
+		t.Fatalf("clear newest_mtime: %v", err)
+	}
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, SyncOptions{})
+	if err != nil || updated {
+		t.Fatalf("legacy rows: updated=%v err=%v", updated, err)
+	}
+	if got := storedModTime("s1_R1.txt"); !got.Equal(touched) {
+		t.Errorf("expected mtime %v to be backfilled, got %v", touched, got)
+	}
+	if updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, SyncOptions{}); err != nil || updated {
+		t.Fatalf("after backfill: updated=%v err=%v", updated, err)
+	}
+}
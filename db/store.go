@@ -5,13 +5,23 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/seoyhaein/tori/block"
 	u "github.com/seoyhaein/utils"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
-// SaveFolders rootPath 하위의 모든 Folder 에 대해 파일 정보를 DB에 삽입함.
+// SaveFolders rootPath 하위의 모든 Folder 에 대해 파일 정보를 DB에 삽입함. RootDir 바로 아래 폴더만 저장함.
 func SaveFolders(ctx context.Context, db *sql.DB, rootPath string, foldersExclusions, filesExclusions []string) error {
+	return SaveFolderTree(ctx, db, rootPath, DefaultMaxDepth, foldersExclusions, filesExclusions)
+}
+
+// SaveFolderTree rootPath 아래 maxDepth 깊이까지의 모든 Folder 와 파일 정보를 DB에 삽입함.
+// 폴더 통계는 하위 폴더의 파일까지 포함한 합계로 저장함.
+func SaveFolderTree(ctx context.Context, db *sql.DB, rootPath string, maxDepth int, foldersExclusions, filesExclusions []string) error {
 	// rootPath 하위의 Folder 목록 조회
-	folders, err := GetSubFolders(rootPath, foldersExclusions)
+	folders, err := GetFolderTree(rootPath, maxDepth, foldersExclusions, filesExclusions)
 	if err != nil {
 		return fmt.Errorf("failed to get subfolders from %s: %w", rootPath, err)
 	}
@@ -24,14 +34,25 @@ func SaveFolders(ctx context.Context, db *sql.DB, rootPath string, foldersExclus
 		}
 	}
 
+	// StoreFilesFolderInfo 는 폴더에 직접 있는 파일로만 통계를 채우므로, 모두 저장한 뒤 하위 폴더를 포함한 합계로 바꿈.
+	if err := execSQL(ctx, db, "update_folder_rollups.sql"); err != nil {
+		return fmt.Errorf("failed to update folder rollups: %w", err)
+	}
 	return nil
 }
 
 // UpdateDB 폴더 변경 내역과 파일 변경 내역을 DB에 반영
 func UpdateDB(ctx context.Context, db *sql.DB, diffs []FolderDiff, changes []FileChange) error {
-	// 폴더 변경 업데이트
-	if err := UpsertFolders(ctx, db, diffs); err != nil {
-		return err
+	// 폴더 변경 업데이트. 없어진 폴더는 그 안의 파일을 옮기거나 지운 뒤에 지움.
+	var removed []FolderDiff
+	for _, diff := range diffs {
+		if diff.Removed {
+			removed = append(removed, diff)
+			continue
+		}
+		if err := diff.UpsertFolder(ctx, db); err != nil {
+			return err
+		}
 	}
 	// UpsertFolders 해줘야지만, db 에 folderId 가 생겨서 검색할 수 가 있음.
 	for i := range changes {
@@ -45,42 +66,119 @@ func UpdateDB(ctx context.Context, db *sql.DB, diffs []FolderDiff, changes []Fil
 	if err := UpsertDelFiles(ctx, db, changes); err != nil {
 		return err
 	}
-	return nil
+	return UpsertFolders(ctx, db, removed)
 }
 
-// DiffFolders 폴더 파일 비교
+// DiffFolders 폴더 파일 비교. RootDir 바로 아래 폴더만 비교하고, 폴더마다 [폴더 경로, 파일 이름...] 을 반환함.
 func DiffFolders(db *sql.DB, rootPath string, foldersExclusions, filesExclusions []string) ([][]string, []FolderDiff, []FileChange, error) {
-	// 1. 폴더 비교: 디스크 폴더들과 db의 폴더 목록을 비교
-	_, folders, folderDiffs, err := CompareFolders(db, rootPath, foldersExclusions, filesExclusions)
+	blocks, folderDiffs, fileChanges, err := DiffFolderTree(db, rootPath, foldersExclusions, filesExclusions, SyncOptions{})
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	folderFiles := make([][]string, 0, len(blocks))
+	for _, b := range blocks {
+		folderFiles = append(folderFiles, append([]string{b.Path}, b.Files...))
+	}
+	return folderFiles, folderDiffs, fileChanges, nil
+}
 
-	var (
-		folderFiles    [][]string
-		allFileChanges []FileChange
-	)
+// DiffFolderTree opts.MaxDepth 깊이까지의 모든 폴더와 파일을 DB 와 비교하고, FileBlock 으로 만들 폴더 목록을 반환함.
+// 변경은 FileBlock 이 아닌 중간 폴더의 것도 포함함. 크기, 파일 수, 가장 최근 수정 시각이 DB 와 같은 폴더는
+// opts.Force 가 아니면 파일 이름만 확인하고, 이름도 같으면 파일 단위 비교와 checksum 계산을 건너뜀.
+// 이름이 바뀌었거나 다른 폴더로 옮겨진 파일은 폴더를 가리지 않고 detectRenames 로 찾음.
+// 디스크에서 없어진 폴더는 Removed 인 FolderDiff 로, 그 안의 파일은 "removed" 로 반환하므로 옮긴 뒤 지운 폴더의 파일도 찾음.
+//
+// DB 의 폴더와 파일은 처음에 한 번만 읽음. opts.Force 가 아니면, 폴더 자체의 수정 시각과 inode 가 DB 에 저장된 값과 같은 폴더는
+// 읽지 않고 DB 에 있는 파일만 stat 함 (readFolder 참고). 폴더 자체의 수정 시각과 inode 만 바뀐 폴더도 FolderDiff 로 반환해서 DB 에 저장하게 함.
+func DiffFolderTree(db *sql.DB, rootPath string, foldersExclusions, filesExclusions []string, opts SyncOptions) ([]block.FolderFiles, []FolderDiff, []FileChange, error) {
+	dbFolders, err := GetFoldersFromDB(db)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get folders from DB: %w", err)
+	}
+	dbFiles, err := getFilesByFolderFromDB(db)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var known map[string]*knownFolder
+	if !opts.Force {
+		known = knownFolders(dbFolders, dbFiles)
+	}
 
-	// 2. 각 폴더에 대해 파일 비교
+	// 1. 폴더 비교: 디스크 폴더들과 db의 폴더 목록을 비교
+	folders, diskFiles, err := folderTree(rootPath, opts.MaxDepth, foldersExclusions, filesExclusions, known)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get subfolders from disk: %w", err)
+	}
+	folderDiffs := compareFolderStats(dbFolders, folders, true)
+	changed := make(map[string]bool, len(folderDiffs))
+	for _, fd := range folderDiffs {
+		changed[fd.Path] = true
+	}
+
+	// 2. 바뀐 폴더만 파일 비교
+	var allFileChanges []FileChange
 	for _, folder := range folders {
-		filesMatch, files, fileChanges, err := CompareFiles(db, folder.Path, filesExclusions)
+		// 폴더 안에서 이름만 바뀌면 폴더 통계는 그대로이므로 이름은 항상 확인함.
+		if !opts.Force && !changed[folder.Path] && sameFileNames(diskFiles[folder.Path], dbFiles[folder.Path]) {
+			continue
+		}
+		fileChanges, err := compareFileLists(diskFiles[folder.Path], dbFiles[folder.Path], opts.Checksum)
 		if err != nil {
 			return nil, nil, nil, err
 		}
-		if !filesMatch {
-			allFileChanges = append(allFileChanges, fileChanges...)
-		}
+		allFileChanges = append(allFileChanges, fileChanges...)
+	}
 
-		fileNames := ExtractFileNames(files)
-		folderFiles = append(folderFiles, append([]string{folder.Path}, fileNames...))
+	// 3. 디스크에서 없어진 폴더의 파일은 "removed" 로 넣어서, 다른 폴더로 옮겨진 파일을 detectRenames 가 찾게 함.
+	removedDiffs, removedChanges, err := removedFolders(rootPath, dbFolders, dbFiles)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	folderDiffs = append(folderDiffs, removedDiffs...)
+	allFileChanges = append(allFileChanges, removedChanges...)
+	allFileChanges = detectRenames(allFileChanges)
+	blocks := BlockFolders(folders, diskFiles)
 
 	// 전체 동일 여부 판단: folderDiffs 와 allFileChanges 가 모두 비어 있으면 동일
 	if len(folderDiffs) == 0 && len(allFileChanges) == 0 {
-		return folderFiles, nil, nil, nil
+		return blocks, nil, nil, nil
 	}
 
-	return folderFiles, folderDiffs, allFileChanges, nil
+	return blocks, folderDiffs, allFileChanges, nil
+}
+
+// removedFolders dbFolders 중 rootPath 아래에서 없어진 폴더와, 그 폴더에 남은 파일(dbFiles)의 "removed" 변경을 반환함.
+// 제외 목록이나 깊이 때문에 이번에 훑지 않은 폴더는 디스크에 있으면 그대로 둠.
+func removedFolders(rootPath string, dbFolders []Folder, dbFiles map[string][]File) ([]FolderDiff, []FileChange, error) {
+	rootPath = filepath.Clean(rootPath)
+	var diffs []FolderDiff
+	var changes []FileChange
+	for _, folder := range dbFolders {
+		rel, err := filepath.Rel(rootPath, folder.Path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if _, err := os.Stat(folder.Path); err == nil || !os.IsNotExist(err) {
+			continue
+		}
+		diffs = append(diffs, FolderDiff{
+			FolderID:        folder.ID,
+			Path:            folder.Path,
+			DBTotalSize:     folder.TotalSize,
+			DBFileCount:     folder.FileCount,
+			DBNewestModTime: folder.NewestModTime,
+			DBDirModTime:    folder.DirModTime,
+			DBDirInode:      folder.DirInode,
+			Removed:         true,
+		})
+		// 디스크 파일이 없으므로 compareFileLists 는 DB 파일을 모두 "removed" 로 반환함.
+		fileChanges, err := compareFileLists(nil, dbFiles[folder.Path], "")
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, fileChanges...)
+	}
+	return diffs, changes, nil
 }
 
 // StoreFilesFolderInfo 폴더 경로를 받아 폴더 내 파일 정보를 DB에 삽입하는 함수, TODO 한번만 실행되고 말아야 함. 이름 수정하자.
@@ -109,7 +207,9 @@ func StoreFilesFolderInfo(ctx context.Context, db *sql.DB, folderPath string, ex
 		folderDetails.Path,
 		folderDetails.TotalSize,
 		folderDetails.FileCount,
-		folderDetails.CreatedTime)
+		dbTime(folderDetails.NewestModTime),
+		dbTime(folderDetails.DirModTime),
+		int64(folderDetails.DirInode))
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
 			logger.Infof("rollback failed: %v", rbErr)
@@ -133,7 +233,10 @@ func StoreFilesFolderInfo(ctx context.Context, db *sql.DB, folderPath string, ex
 			folderID,
 			file.Name,
 			file.Size,
-			file.CreatedTime)
+			file.Checksum,
+			file.ChecksumModTime,
+			dbTime(file.ModTime),
+			int64(file.Inode))
 		if err != nil {
 			if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
 				logger.Infof("rollback failed: %v", rbErr)
@@ -159,6 +262,31 @@ func StoreFilesFolderInfo(ctx context.Context, db *sql.DB, folderPath string, ex
 	return nil
 }
 
+// GetFileChecksum folderPath 폴더의 name 파일에 대해 DB 에 저장된 checksum 과, 그것을 계산할 때의 수정 시각(UnixNano)을 반환함.
+// 파일이 DB 에 없으면 sql.ErrNoRows 를 반환함.
+func GetFileChecksum(ctx context.Context, db *sql.DB, folderPath, name string) (sum string, modTime int64, err error) {
+	rows, err := querySQL(ctx, db, "select_file_checksum.sql", filepath.Clean(folderPath), name)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to query checksum of %s: %w", name, err)
+	}
+	defer func() {
+		if cErr := rows.Close(); cErr != nil {
+			logger.Warnf("failed to close rows: %v", cErr)
+		}
+	}()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", 0, fmt.Errorf("failed to query checksum of %s: %w", name, err)
+		}
+		return "", 0, sql.ErrNoRows
+	}
+	if err := rows.Scan(&sum, &modTime); err != nil {
+		return "", 0, fmt.Errorf("failed to scan checksum of %s: %w", name, err)
+	}
+	return sum, modTime, nil
+}
+
 func getFolderID(db *sql.DB, path string) (int64, error) {
 	rows, err := querySQLNoCtx(db, "get_folder_id.sql", path)
 	if err != nil {
@@ -232,10 +360,15 @@ func GetFoldersFromDB(db *sql.DB) (folders []Folder, err error) {
 	// 각 행을 순회하면서 Folder 구조체에 스캔
 	for rows.Next() {
 		var f Folder
-		err = rows.Scan(&f.ID, &f.Path, &f.TotalSize, &f.FileCount, &f.CreatedTime)
+		var newest, dirModTime sql.NullTime
+		var dirInode int64
+		err = rows.Scan(&f.ID, &f.Path, &f.TotalSize, &f.FileCount, &f.CreatedTime, &newest, &dirModTime, &dirInode)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan folder: %w", err)
 		}
+		f.NewestModTime = newest.Time
+		f.DirModTime = dirModTime.Time
+		f.DirInode = uint64(dirInode)
 		folders = append(folders, f)
 	}
 
@@ -282,6 +415,41 @@ func GetFilesFromDB(db *sql.DB) (files []File, err error) {
 	return files, nil
 }
 
+// getFilesByFolderFromDB DB 의 모든 파일을 폴더 경로별로 한 번에 조회함. 폴더마다 파일은 이름 순서임.
+func getFilesByFolderFromDB(db *sql.DB) (files map[string][]File, err error) {
+	rows, err := querySQLNoCtx(db, "select_files_with_path.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query files: %w", err)
+	}
+
+	defer func() {
+		if cErr := rows.Close(); cErr != nil {
+			if err == nil {
+				err = fmt.Errorf("failed to close rows: %w", cErr)
+			} else {
+				err = fmt.Errorf("%v; failed to close rows: %w", err, cErr)
+			}
+		}
+	}()
+
+	files = make(map[string][]File)
+	for rows.Next() {
+		var f File
+		var modTime sql.NullTime
+		var inode int64
+		if err := rows.Scan(&f.ID, &f.FolderID, &f.Path, &f.Name, &f.Size, &f.CreatedTime, &f.Checksum, &f.ChecksumModTime, &modTime, &inode); err != nil {
+			return nil, fmt.Errorf("failed to scan file: %w", err)
+		}
+		f.ModTime = modTime.Time
+		f.Inode = uint64(inode)
+		files[f.Path] = append(files[f.Path], f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return files, nil
+}
+
 // GetFilesByPathFromDB 는 주어진 Folder 경로에 해당하는 파일 정보를 DB 에서 조회함.
 // IMPORTANT: SQL 쿼리는 "queries/select_files_for_folder.sql" 파일에 분리되어 있음.
 func GetFilesByPathFromDB(db *sql.DB, folderPath string) (files []File, err error) {
@@ -303,9 +471,14 @@ func GetFilesByPathFromDB(db *sql.DB, folderPath string) (files []File, err erro
 
 	for rows.Next() {
 		var f File
-		if err := rows.Scan(&f.ID, &f.FolderID, &f.Name, &f.Size, &f.CreatedTime); err != nil {
+		var modTime sql.NullTime
+		var inode int64
+		if err := rows.Scan(&f.ID, &f.FolderID, &f.Name, &f.Size, &f.CreatedTime, &f.Checksum, &f.ChecksumModTime, &modTime, &inode); err != nil {
 			return nil, fmt.Errorf("failed to scan file for folder %s: %w", folderPath, err)
 		}
+		f.ModTime = modTime.Time
+		f.Inode = uint64(inode)
+		f.Path = folderPath
 		files = append(files, f)
 	}
 	if err = rows.Err(); err != nil {
@@ -58,39 +58,24 @@ func createLargeTestStructure(rootDir string, numDirs, numFilesPerDir int, creat
 
 // TODO 비정상 디렉토리 구조를 만들어서 제대로 에러를 리턴하는지 테스트 해야함.
 
-// SetupInMemoryDB in‑memory SQLite DB를 생성하고, 필요한 테이블 스키마를 설정한다.
+// SetupInMemoryDB in‑memory SQLite DB를 생성하고, InitializeDatabase 로 실제 DB 와 같은 스키마를 만든다.
 // 실패 시 t.Fatalf 를 호출하여 테스트를 중단한다.
 func SetupInMemoryDB(t *testing.T) *sql.DB {
+	t.Helper()
+	// exec_sqlmock_test 에서 sqlFiles 를 바꿔 놓을 수 있으므로 embed 된 쿼리로 되돌림.
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
 	db, err := sql.Open("sqlite3", ":memory:")
 	if err != nil {
 		t.Fatalf("failed to open in-memory db: %v", err)
 	}
-
-	schemas := []string{
-		`CREATE TABLE IF NOT EXISTS folders (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			path TEXT NOT NULL UNIQUE,
-			total_size INTEGER DEFAULT 0,
-			file_count INTEGER DEFAULT 0,
-			created_time DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
-		);`,
-		`CREATE TABLE IF NOT EXISTS files (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			folder_id INTEGER NOT NULL,
-			name TEXT NOT NULL,
-			size INTEGER NOT NULL,
-			created_time DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
-			FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE
-		);`,
-		`CREATE INDEX IF NOT EXISTS idx_files_folder_id ON files(folder_id);`,
-	}
-
-	for _, schema := range schemas {
-		if _, err := db.Exec(schema); err != nil {
-			t.Fatalf("failed to execute schema: %v", err)
-		}
+	// :memory: DB 는 연결마다 따로 만들어지므로 연결을 하나만 씀.
+	db.SetMaxOpenConns(1)
+	if err := InitializeDatabase(db); err != nil {
+		t.Fatalf("failed to initialize db: %v", err)
 	}
-
 	return db
 }
 
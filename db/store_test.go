@@ -12,9 +12,9 @@ import (
 func setupChangeFS() func() {
 	old := sqlFiles
 	sqlFiles = fstest.MapFS{
-		"queries/insert_file.sql":  &fstest.MapFile{Data: []byte("INSERT INTO files VALUES (?,?,?)")},
-		"queries/update_files.sql": &fstest.MapFile{Data: []byte("UPDATE files SET size=? WHERE id=?")},
-		"queries/delete_files.sql": &fstest.MapFile{Data: []byte("DELETE FROM files WHERE id=?")},
+		"queries/insert_file.sql": &fstest.MapFile{Data: []byte("INSERT INTO files VALUES (?,?,?)")},
+		"queries/update_file.sql": &fstest.MapFile{Data: []byte("UPDATE files SET size=? WHERE id=?")},
+		"queries/delete_file.sql": &fstest.MapFile{Data: []byte("DELETE FROM files WHERE id=?")},
 	}
 	return func() { sqlFiles = old }
 }
@@ -27,7 +27,7 @@ func TestUpsertDelFile_Added(t *testing.T) {
 		t.Fatalf("sqlmock new: %v", err)
 	}
 	query := "INSERT INTO files VALUES (?,?,?)"
-	mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int64(1), "a", int64(10)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int64(1), "a", int64(10), "", int64(0), nil, int64(0)).WillReturnResult(sqlmock.NewResult(1, 1))
 	fc := FileChange{ChangeType: "added", FolderID: 1, Name: "a", DiskSize: 10}
 	if err := fc.UpsertDelFile(context.Background(), db); err != nil {
 		t.Fatalf("UpsertDelFile error: %v", err)
@@ -45,7 +45,7 @@ func TestUpsertDelFile_Modified(t *testing.T) {
 		t.Fatalf("sqlmock new: %v", err)
 	}
 	query := "UPDATE files SET size=? WHERE id=?"
-	mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int64(5), int64(2)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int64(5), "", int64(0), nil, int64(0), int64(2)).WillReturnResult(sqlmock.NewResult(1, 1))
 	fc := FileChange{ChangeType: "modified", DiskSize: 5, FileID: 2}
 	if err := fc.UpsertDelFile(context.Background(), db); err != nil {
 		t.Fatalf("UpsertDelFile error: %v", err)
@@ -95,8 +95,8 @@ func TestUpsertDelFiles(t *testing.T) {
 	}
 	q1 := "INSERT INTO files VALUES (?,?,?)"
 	q2 := "UPDATE files SET size=? WHERE id=?"
-	mock.ExpectExec(regexp.QuoteMeta(q1)).WithArgs(int64(1), "a", int64(10)).WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectExec(regexp.QuoteMeta(q2)).WithArgs(int64(5), int64(2)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(q1)).WithArgs(int64(1), "a", int64(10), "", int64(0), nil, int64(0)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(q2)).WithArgs(int64(5), "", int64(0), nil, int64(0), int64(2)).WillReturnResult(sqlmock.NewResult(1, 1))
 	changes := []FileChange{
 		{ChangeType: "added", FolderID: 1, Name: "a", DiskSize: 10},
 		{ChangeType: "modified", DiskSize: 5, FileID: 2},
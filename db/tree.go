@@ -0,0 +1,217 @@
+package db
+
+import (
+	"fmt"
+	"github.com/seoyhaein/tori/block"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultMaxDepth maxDepth 가 0 일 때 사용하는 깊이. RootDir 바로 아래 폴더만 찾던 이전 동작과 같음.
+const DefaultMaxDepth = 1
+
+// ruleFileName FileBlock 규칙 파일 이름.
+const ruleFileName = "rule.json"
+
+// GetFolderTree rootPath 아래의 폴더를 maxDepth 깊이까지 모두 찾음. rootPath 바로 아래 폴더의 깊이가 1 이고, 0 이면 DefaultMaxDepth.
+// 부모 폴더가 자식 폴더보다 먼저 오고, 같은 부모의 폴더는 이름 순서임.
+// TotalSize, FileCount, NewestModTime 은 찾은 하위 폴더의 파일까지 모두 포함한 값이고, Block, RuleDir 은 folderBlock 의 규칙으로 정함.
+func GetFolderTree(rootPath string, maxDepth int, foldersExclusions, filesExclusions []string) ([]Folder, error) {
+	folders, _, err := folderTree(rootPath, maxDepth, foldersExclusions, filesExclusions, nil)
+	return folders, err
+}
+
+// knownFolder DB 에 저장된 폴더와 그 폴더에 직접 있는 파일, 하위 폴더 경로.
+type knownFolder struct {
+	folder     Folder
+	files      []File
+	subFolders []string
+}
+
+// knownFolders DB 의 폴더와 폴더 경로별 파일로 folderTree 에 넘길 map 을 만듦. 하위 폴더는 경로 순서임.
+func knownFolders(dbFolders []Folder, dbFiles map[string][]File) map[string]*knownFolder {
+	known := make(map[string]*knownFolder, len(dbFolders))
+	for _, f := range dbFolders {
+		known[f.Path] = &knownFolder{folder: f, files: dbFiles[f.Path]}
+	}
+	for _, f := range dbFolders {
+		if parent, ok := known[filepath.Dir(f.Path)]; ok {
+			parent.subFolders = append(parent.subFolders, f.Path)
+		}
+	}
+	for _, kf := range known {
+		slices.Sort(kf.subFolders)
+	}
+	return known
+}
+
+// folderTree GetFolderTree 와 같고, 폴더를 훑으면서 읽은 폴더 경로별 파일 목록도 반환함.
+// known 이 nil 이 아니면 폴더 자체가 DB 에 저장된 때와 같은 폴더는 읽지 않음 (readFolder 참고).
+func folderTree(rootPath string, maxDepth int, foldersExclusions, filesExclusions []string, known map[string]*knownFolder) ([]Folder, map[string][]File, error) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	rootPath = filepath.Clean(rootPath)
+	var inherited string
+	if hasRuleFile(rootPath) {
+		inherited = rootPath
+	}
+	var folders []Folder
+	files := make(map[string][]File)
+	if _, err := walkFolderTree(rootPath, 1, maxDepth, inherited, false, foldersExclusions, filesExclusions, known, &folders, files); err != nil {
+		return nil, nil, err
+	}
+	return folders, files, nil
+}
+
+// walkFolderTree dir 의 하위 폴더를 folders 에, 그 파일 목록을 files 에 추가하고, 하위 폴더 전체를 합친 통계를 반환함.
+// unchanged 면 dir 이 DB 에 저장된 때와 같으므로 하위 폴더도 known 에서 찾음.
+func walkFolderTree(dir string, depth, maxDepth int, inheritedRule string, unchanged bool, foldersExclusions, filesExclusions []string,
+	known map[string]*knownFolder, folders *[]Folder, files map[string][]File) (Folder, error) {
+	var total Folder
+	subFolders, ok := knownSubFolders(dir, unchanged, foldersExclusions, known)
+	if !ok {
+		var err error
+		subFolders, err = GetSubFolders(dir, foldersExclusions)
+		if err != nil {
+			return total, err
+		}
+	}
+	for _, sub := range subFolders {
+		direct, subFiles, subUnchanged, err := readFolder(sub.Path, filesExclusions, known)
+		if err != nil {
+			return total, fmt.Errorf("failed to compute stats for folder %s: %w", sub.Path, err)
+		}
+		files[sub.Path] = subFiles
+		sub.TotalSize, sub.FileCount, sub.NewestModTime = direct.TotalSize, direct.FileCount, direct.NewestModTime
+		sub.DirModTime, sub.DirInode = direct.DirModTime, direct.DirInode
+		sub.Depth = depth
+		sub.RuleDir = inheritedRule
+		hasRule := hasRuleFile(sub.Path)
+		if hasRule {
+			sub.RuleDir = sub.Path
+		}
+
+		// 자식보다 먼저 오도록 자리를 잡아 두고, 하위 폴더를 다 본 뒤에 합계와 Block 여부를 채움.
+		idx := len(*folders)
+		*folders = append(*folders, sub)
+		leaf := true
+		if depth < maxDepth {
+			children, err := walkFolderTree(sub.Path, depth+1, maxDepth, sub.RuleDir, subUnchanged, foldersExclusions, filesExclusions, known, folders, files)
+			if err != nil {
+				return total, err
+			}
+			leaf = len(*folders) == idx+1
+			addFolderStats(&sub, children)
+		}
+		sub.Block = folderBlock(leaf, hasRule, direct.FileCount)
+		(*folders)[idx] = sub
+
+		addFolderStats(&total, sub)
+	}
+	return total, nil
+}
+
+// readFolder dirPath 에 직접 있는 파일과 그 통계를 구함. 폴더 자체의 수정 시각과 inode 가 known 에 저장된 값과 같으면
+// 폴더에 항목이 생기거나 지워지거나 이름이 바뀌지 않았으므로, 폴더를 읽지 않고 DB 에 있는 파일만 stat 해서 만들고 unchanged 를 true 로 반환함.
+// 파일을 그 자리에서 다시 쓰면 폴더의 수정 시각은 그대로이므로 파일의 stat 은 항상 새로 구함.
+// 그 밖의 경우(처음 보는 폴더, 믿을 수 없는 수정 시각, 없어진 파일)는 GetCurrentFolderFileInfo 로 폴더를 읽음.
+// 제외 목록에서 빠진 항목은 폴더를 읽어야 찾으므로, 제외 목록을 줄였으면 SyncOptions.Force 로 sync 해야 함.
+func readFolder(dirPath string, exclusions []string, known map[string]*knownFolder) (Folder, []File, bool, error) {
+	if kf, ok := known[dirPath]; ok {
+		modTime, inode, err := dirStat(dirPath)
+		if err == nil && !modTime.IsZero() && modTime.Equal(kf.folder.DirModTime) && inode == kf.folder.DirInode {
+			if folder, files, ok := statKnownFiles(dirPath, kf.files, exclusions); ok {
+				folder.DirModTime, folder.DirInode = modTime, inode
+				return folder, files, true, nil
+			}
+		}
+	}
+	folder, files, err := GetCurrentFolderFileInfo(dirPath, exclusions)
+	return folder, files, false, err
+}
+
+// statKnownFiles DB 에 있는 dirPath 의 파일 known 만 stat 해서 GetCurrentFolderFileInfo 와 같은 값을 만듦. 없어진 파일이 있으면 ok 가 false.
+func statKnownFiles(dirPath string, known []File, exclusions []string) (Folder, []File, bool) {
+	folder := Folder{Path: dirPath, CreatedTime: time.Now().Format("2006-01-02 15:04:05")}
+	files := make([]File, 0, len(known))
+	for _, k := range known {
+		if ExcludedFile(k.Name, exclusions) {
+			continue
+		}
+		info, err := os.Lstat(filepath.Join(dirPath, k.Name))
+		if err != nil || info.IsDir() {
+			return Folder{}, nil, false
+		}
+		file := newDiskFile(dirPath, info)
+		folder.TotalSize += file.Size
+		folder.FileCount++
+		if file.ModTime.After(folder.NewestModTime) {
+			folder.NewestModTime = file.ModTime
+		}
+		files = append(files, file)
+	}
+	// GetCurrentFolderFileInfo 처럼 이름 순서로 맞춤.
+	slices.SortFunc(files, func(a, b File) int { return strings.Compare(a.Name, b.Name) })
+	return folder, files, true
+}
+
+// knownSubFolders unchanged 인 폴더 dir 의 하위 폴더를 known 에서 찾음.
+// DB 에 하위 폴더가 없으면 지난번에 maxDepth 때문에 찾지 않았을 수도 있으므로 ok 가 false 이고, 그럴 때는 폴더를 읽어야 함.
+func knownSubFolders(dir string, unchanged bool, exclusions []string, known map[string]*knownFolder) ([]Folder, bool) {
+	kf, ok := known[dir]
+	if !unchanged || !ok || len(kf.subFolders) == 0 {
+		return nil, false
+	}
+	subFolders := make([]Folder, 0, len(kf.subFolders))
+	for _, path := range kf.subFolders {
+		if slices.Contains(exclusions, filepath.Base(path)) {
+			continue
+		}
+		info, err := os.Lstat(path)
+		if err != nil || !info.IsDir() {
+			return nil, false
+		}
+		subFolders = append(subFolders, Folder{Path: path, CreatedTime: info.ModTime().Format("2006-01-02 15:04:05")})
+	}
+	return subFolders, true
+}
+
+// addFolderStats from 의 크기, 파일 수, 가장 최근 수정 시각을 to 에 합침.
+func addFolderStats(to *Folder, from Folder) {
+	to.TotalSize += from.TotalSize
+	to.FileCount += from.FileCount
+	if from.NewestModTime.After(to.NewestModTime) {
+		to.NewestModTime = from.NewestModTime
+	}
+}
+
+// folderBlock 폴더를 FileBlock 으로 만들지. 하위 폴더가 없는(maxDepth 에 있는 폴더 포함) 폴더는 항상 FileBlock 이 되고,
+// 하위 폴더가 있는 폴더는 rule.json 과 직접 가진 파일이 모두 있을 때만 FileBlock 이 됨.
+// 파일 없이 rule.json 만 있는 중간 폴더는 하위 폴더들에게 rule.json 을 물려주는 역할만 함.
+func folderBlock(leaf, hasRule bool, directFiles int64) bool {
+	return leaf || (hasRule && directFiles > 0)
+}
+
+func hasRuleFile(dir string) bool {
+	exists, err := FileExistsExact(dir, ruleFileName)
+	if err != nil {
+		logger.Warnf("failed to check %s in %s: %v", ruleFileName, dir, err)
+	}
+	return exists
+}
+
+// BlockFolders tree 에서 FileBlock 으로 만들 폴더를 골라 block.FolderFiles 로 변환함. files 는 폴더 경로별 파일 목록.
+func BlockFolders(tree []Folder, files map[string][]File) []block.FolderFiles {
+	var out []block.FolderFiles
+	for _, f := range tree {
+		if !f.Block {
+			continue
+		}
+		out = append(out, block.FolderFiles{Path: f.Path, RuleDir: f.RuleDir, Files: ExtractFileNames(files[f.Path])})
+	}
+	return out
+}
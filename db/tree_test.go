@@ -0,0 +1,255 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/seoyhaein/tori/protofile"
+	pb "github.com/seoyhaein/tori/protos"
+)
+
+// setupNestedRoot project/run1/{sampleA,sampleB}, project/run2 구조를 만듦. rule.json 은 run1, run2 에만 있음.
+func setupNestedRoot(t *testing.T) string {
+	t.Helper()
+	rootDir := t.TempDir()
+	rs := map[string]any{
+		"version":     "1",
+		"delimiter":   []string{"_", ".txt"},
+		"header":      []string{"R1", "R2"},
+		"rowRules":    map[string]any{"matchParts": []int{0}},
+		"columnRules": map[string]any{"matchParts": []int{1}},
+		"sizeRules":   map[string]any{"minSize": 0, "maxSize": 1000},
+	}
+	rule, _ := json.Marshal(rs)
+	files := map[string][]string{
+		"project/run1/sampleA": {"s1_R1.txt", "s1_R2.txt"},
+		"project/run1/sampleB": {"s2_R1.txt", "s2_R2.txt"},
+		"project/run2":         {"s3_R1.txt", "s3_R2.txt"},
+	}
+	for dir, names := range files {
+		if err := os.MkdirAll(filepath.Join(rootDir, dir), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		for _, name := range names {
+			if err := os.WriteFile(filepath.Join(rootDir, dir, name), []byte("xx"), 0644); err != nil {
+				t.Fatalf("write file: %v", err)
+			}
+		}
+	}
+	for _, dir := range []string{"project/run1", "project/run2"} {
+		if err := os.WriteFile(filepath.Join(rootDir, dir, "rule.json"), rule, 0644); err != nil {
+			t.Fatalf("write rule.json: %v", err)
+		}
+	}
+	return rootDir
+}
+
+func TestGetFolderTree(t *testing.T) {
+	rootDir := setupNestedRoot(t)
+	exclusions := []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+	join := func(p string) string { return filepath.Join(rootDir, p) }
+
+	tree, err := GetFolderTree(rootDir, 3, nil, exclusions)
+	if err != nil {
+		t.Fatalf("GetFolderTree: %v", err)
+	}
+	want := []Folder{
+		{Path: join("project"), TotalSize: 12, FileCount: 6, Depth: 1},
+		{Path: join("project/run1"), TotalSize: 8, FileCount: 4, Depth: 2, RuleDir: join("project/run1")},
+		{Path: join("project/run1/sampleA"), TotalSize: 4, FileCount: 2, Depth: 3, Block: true, RuleDir: join("project/run1")},
+		{Path: join("project/run1/sampleB"), TotalSize: 4, FileCount: 2, Depth: 3, Block: true, RuleDir: join("project/run1")},
+		{Path: join("project/run2"), TotalSize: 4, FileCount: 2, Depth: 2, Block: true, RuleDir: join("project/run2")},
+	}
+	if len(tree) != len(want) {
+		t.Fatalf("expected %d folders, got %+v", len(want), tree)
+	}
+	for i, w := range want {
+		got := tree[i]
+		if got.Path != w.Path || got.TotalSize != w.TotalSize || got.FileCount != w.FileCount ||
+			got.Depth != w.Depth || got.Block != w.Block || got.RuleDir != w.RuleDir {
+			t.Errorf("folder %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+
+	// 기본 깊이에서는 RootDir 바로 아래 폴더만 FileBlock 이 됨.
+	tree, err = GetFolderTree(rootDir, 0, nil, exclusions)
+	if err != nil {
+		t.Fatalf("GetFolderTree: %v", err)
+	}
+	if len(tree) != 1 || tree[0].Path != join("project") || !tree[0].Block || tree[0].FileCount != 0 {
+		t.Errorf("unexpected default depth tree: %+v", tree)
+	}
+}
+
+func TestSyncFolders_Nested(t *testing.T) {
+	// exec_sqlmock_test 에서 sqlFiles 를 바꿔 놓을 수 있으므로 embed 된 쿼리로 되돌림.
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
+	rootDir := setupNestedRoot(t)
+	db, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer db.Close()
+	if err := InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase: %v", err)
+	}
+	ctx := context.Background()
+	exclusions := []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+	if err := SaveFolderTree(ctx, db, rootDir, 3, nil, exclusions); err != nil {
+		t.Fatalf("SaveFolderTree: %v", err)
+	}
+
+	// DB 의 폴더 통계도 하위 폴더를 포함한 합계여야 함.
+	var count int64
+	if err := db.QueryRow("SELECT file_count FROM folders WHERE path = ?", filepath.Join(rootDir, "project")).Scan(&count); err != nil {
+		t.Fatalf("query project folder: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("expected project rollup of 6 files, got %d", count)
+	}
+
+	opts := SyncOptions{MaxDepth: 3}
+	updated, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || !updated {
+		t.Fatalf("first SyncFolders: updated=%v err=%v", updated, err)
+	}
+	var dataBlock pb.DataBlock
+	if err := protofile.Load(filepath.Join(rootDir, "datablock.pb"), &dataBlock); err != nil {
+		t.Fatalf("load datablock.pb: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, fb := range dataBlock.GetBlocks() {
+		ids[fb.GetBlockId()] = true
+	}
+	for _, p := range []string{"project/run1/sampleA", "project/run1/sampleB", "project/run2"} {
+		if !ids[filepath.Join(rootDir, p)] {
+			t.Errorf("expected a block for %s, got %v", p, ids)
+		}
+	}
+	if len(ids) != 3 {
+		t.Errorf("expected 3 blocks, got %v", ids)
+	}
+
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || updated {
+		t.Fatalf("second SyncFolders: updated=%v err=%v", updated, err)
+	}
+
+	// 가장 깊은 폴더의 변경도 감지해야 함.
+	for _, name := range []string{"s4_R1.txt", "s4_R2.txt"} {
+		if err := os.WriteFile(filepath.Join(rootDir, "project/run1/sampleB", name), []byte("xx"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || !updated {
+		t.Fatalf("third SyncFolders: updated=%v err=%v", updated, err)
+	}
+	updated, err = SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || updated {
+		t.Fatalf("fourth SyncFolders: updated=%v err=%v", updated, err)
+	}
+}
+
+func TestDiffFolderTree_SkipsUnchangedFolders(t *testing.T) {
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+
+	rootDir := setupNestedRoot(t)
+	db, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer db.Close()
+	if err := InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase: %v", err)
+	}
+	ctx := context.Background()
+	exclusions := []string{"*.json", "invalid_files", "*.csv", "*.pb"}
+	opts := SyncOptions{MaxDepth: 3}
+	if _, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts); err != nil {
+		t.Fatalf("first SyncFolders: %v", err)
+	}
+
+	// 방금 바뀐 폴더의 수정 시각은 믿지 않으므로, 폴더의 수정 시각을 과거로 옮긴 뒤 sync 해서 DB 에 저장함.
+	old := time.Now().Add(-time.Hour)
+	err = filepath.WalkDir(rootDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		return os.Chtimes(path, old, old)
+	})
+	if err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	updated, err := SyncFolders(ctx, db, rootDir, nil, exclusions, opts)
+	if err != nil || updated {
+		t.Fatalf("second SyncFolders: updated=%v err=%v", updated, err)
+	}
+
+	var reads []string
+	readDir = func(name string) ([]os.DirEntry, error) {
+		reads = append(reads, name)
+		return os.ReadDir(name)
+	}
+	t.Cleanup(func() { readDir = os.ReadDir })
+
+	_, folderDiffs, fileChanges, err := DiffFolderTree(db, rootDir, nil, exclusions, opts)
+	if err != nil {
+		t.Fatalf("DiffFolderTree: %v", err)
+	}
+	if folderDiffs != nil || fileChanges != nil {
+		t.Fatalf("expected no changes, got %+v %+v", folderDiffs, fileChanges)
+	}
+	// RootDir 과, DB 에 하위 폴더가 없어서 maxDepth 전에 하위 폴더를 찾아야 하는 run2 만 읽음.
+	want := []string{rootDir, filepath.Join(rootDir, "project/run2")}
+	if !slices.Equal(reads, want) {
+		t.Errorf("expected reads %v, got %v", want, reads)
+	}
+
+	// 그 자리에서 다시 쓴 파일은 폴더를 읽지 않아도 찾아야 함.
+	sampleA := filepath.Join(rootDir, "project/run1/sampleA")
+	if err := os.WriteFile(filepath.Join(sampleA, "s1_R1.txt"), []byte("xyz"), 0644); err != nil {
+		t.Fatalf("rewrite file: %v", err)
+	}
+	reads = nil
+	_, _, fileChanges, err = DiffFolderTree(db, rootDir, nil, exclusions, opts)
+	if err != nil {
+		t.Fatalf("DiffFolderTree after rewrite: %v", err)
+	}
+	if len(fileChanges) != 1 || fileChanges[0].ChangeType != "modified" || fileChanges[0].Name != "s1_R1.txt" {
+		t.Errorf("expected s1_R1.txt modified, got %+v", fileChanges)
+	}
+	if slices.Contains(reads, sampleA) {
+		t.Errorf("expected %s not to be read, got %v", sampleA, reads)
+	}
+
+	// 새 파일이 생기면 폴더의 수정 시각이 바뀌므로 폴더를 읽음.
+	if err := os.WriteFile(filepath.Join(sampleA, "s5_R1.txt"), []byte("xx"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	reads = nil
+	_, _, fileChanges, err = DiffFolderTree(db, rootDir, nil, exclusions, opts)
+	if err != nil {
+		t.Fatalf("DiffFolderTree after add: %v", err)
+	}
+	if !slices.Contains(reads, sampleA) {
+		t.Errorf("expected %s to be read, got %v", sampleA, reads)
+	}
+	var added bool
+	for _, c := range fileChanges {
+		added = added || c.ChangeType == "added" && c.Name == "s5_R1.txt"
+	}
+	if !added {
+		t.Errorf("expected s5_R1.txt added, got %+v", fileChanges)
+	}
+}
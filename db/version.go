@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// DataBlockVersion datablock_versions 테이블의 행. 만들어진 DataBlock 마다 generation 과 content hash 를 기록함.
+type DataBlockVersion struct {
+	Generation  uint64
+	ContentHash string
+	UpdatedAt   time.Time
+}
+
+func scanDataBlockVersion(rows *sql.Rows) (*DataBlockVersion, error) {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("rows error: %w", err)
+		}
+		return nil, nil
+	}
+	var (
+		v         DataBlockVersion
+		updatedAt string
+	)
+	if err := rows.Scan(&v.Generation, &v.ContentHash, &updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan datablock version: %w", err)
+	}
+	t, err := time.Parse(time.RFC3339Nano, updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid updated_at %q for generation %d: %w", updatedAt, v.Generation, err)
+	}
+	v.UpdatedAt = t
+	return &v, nil
+}
+
+// LatestDataBlockVersion 가장 최근 generation. 기록이 없으면 nil.
+func LatestDataBlockVersion(ctx context.Context, db *sql.DB) (*DataBlockVersion, error) {
+	rows, err := querySQL(ctx, db, "select_latest_datablock_version.sql")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDataBlockVersion(rows)
+}
+
+// FindDataBlockVersion generation 이나 content hash 가 일치하는 기록을 찾음. 0 이나 빈 문자열인 조건은 무시하고,
+// 같은 hash 가 여러 번 나왔으면 가장 최근 것을 반환함. 없으면 nil.
+func FindDataBlockVersion(ctx context.Context, db *sql.DB, generation uint64, contentHash string) (*DataBlockVersion, error) {
+	rows, err := querySQL(ctx, db, "select_datablock_version.sql", generation, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDataBlockVersion(rows)
+}
+
+// AssignDataBlockVersion dataBlock 에 generation 을 매기고 datablock_versions 에 기록함. content_hash 는 미리 채워져 있어야 함.
+// 마지막 기록과 내용이 같으면(force sync 등) 같은 generation 과 updated_at 을 다시 씀. 그 외에는 마지막 기록과 floor 중 큰 값 + 1 을 씀.
+// floor 는 디스크에 남아 있는 datablock.pb 의 generation 으로, DB 를 새로 만들어도 generation 이 뒤로 가지 않게 함.
+func AssignDataBlockVersion(ctx context.Context, db *sql.DB, dataBlock *pb.DataBlock, floor uint64) error {
+	if dataBlock.GetContentHash() == "" {
+		return errors.New("datablock has no content hash")
+	}
+	latest, err := LatestDataBlockVersion(ctx, db)
+	if err != nil {
+		return fmt.Errorf("failed to read latest datablock version: %w", err)
+	}
+	if latest != nil && latest.ContentHash == dataBlock.GetContentHash() && latest.Generation >= floor {
+		// updated_at 만 비교하는 이전 클라이언트도 바뀌지 않은 것으로 보도록 처음 기록한 시각을 그대로 씀.
+		dataBlock.Generation = latest.Generation
+		dataBlock.UpdatedAt = timestamppb.New(latest.UpdatedAt)
+		return nil
+	}
+	next := floor + 1
+	if latest != nil && latest.Generation >= floor {
+		next = latest.Generation + 1
+	}
+	updatedAt := dataBlock.GetUpdatedAt().AsTime().UTC().Format(time.RFC3339Nano)
+	if err := execSQL(ctx, db, "insert_datablock_version.sql", next, dataBlock.GetContentHash(), updatedAt); err != nil {
+		return fmt.Errorf("failed to record datablock generation %d: %w", next, err)
+	}
+	dataBlock.Generation = next
+	return nil
+}
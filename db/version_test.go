@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TestAssignDataBlockVersion 내용이 바뀔 때만 generation 이 올라가고, floor 보다 작아지지 않는지 검증
+func TestAssignDataBlockVersion(t *testing.T) {
+	ctx := context.Background()
+	origFS := sqlFiles
+	sqlFiles = embeddedFiles
+	t.Cleanup(func() { sqlFiles = origFS })
+	dbConn, err := ConnectDB("sqlite3", filepath.Join(t.TempDir(), "tori.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB: %v", err)
+	}
+	defer dbConn.Close()
+	if err := InitializeDatabase(dbConn); err != nil {
+		t.Fatalf("InitializeDatabase: %v", err)
+	}
+
+	first := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	a := &pb.DataBlock{ContentHash: "sha256:aa", UpdatedAt: timestamppb.New(first)}
+	if err := AssignDataBlockVersion(ctx, dbConn, a, 0); err != nil {
+		t.Fatalf("assign a: %v", err)
+	}
+	if a.GetGeneration() != 1 {
+		t.Fatalf("first generation = %d, want 1", a.GetGeneration())
+	}
+
+	// 같은 내용이면 generation 과 처음 updated_at 을 그대로 씀.
+	again := &pb.DataBlock{ContentHash: "sha256:aa", UpdatedAt: timestamppb.Now()}
+	if err := AssignDataBlockVersion(ctx, dbConn, again, 0); err != nil {
+		t.Fatalf("assign again: %v", err)
+	}
+	if again.GetGeneration() != 1 || !again.GetUpdatedAt().AsTime().Equal(first) {
+		t.Fatalf("unchanged content got generation %d at %v", again.GetGeneration(), again.GetUpdatedAt().AsTime())
+	}
+
+	b := &pb.DataBlock{ContentHash: "sha256:bb", UpdatedAt: timestamppb.Now()}
+	if err := AssignDataBlockVersion(ctx, dbConn, b, 0); err != nil {
+		t.Fatalf("assign b: %v", err)
+	}
+	if b.GetGeneration() != 2 {
+		t.Fatalf("second generation = %d, want 2", b.GetGeneration())
+	}
+
+	// DB 보다 디스크의 generation 이 크면 그 다음 값을 씀.
+	c := &pb.DataBlock{ContentHash: "sha256:bb", UpdatedAt: timestamppb.Now()}
+	if err := AssignDataBlockVersion(ctx, dbConn, c, 10); err != nil {
+		t.Fatalf("assign c: %v", err)
+	}
+	if c.GetGeneration() != 11 {
+		t.Fatalf("generation after floor = %d, want 11", c.GetGeneration())
+	}
+
+	found, err := FindDataBlockVersion(ctx, dbConn, 0, "sha256:aa")
+	if err != nil || found == nil || found.Generation != 1 {
+		t.Fatalf("FindDataBlockVersion by hash = %+v, %v", found, err)
+	}
+	found, err = FindDataBlockVersion(ctx, dbConn, 2, "")
+	if err != nil || found == nil || found.ContentHash != "sha256:bb" {
+		t.Fatalf("FindDataBlockVersion by generation = %+v, %v", found, err)
+	}
+	if found, err = FindDataBlockVersion(ctx, dbConn, 99, ""); err != nil || found != nil {
+		t.Fatalf("FindDataBlockVersion unknown = %+v, %v", found, err)
+	}
+	if err := AssignDataBlockVersion(ctx, dbConn, &pb.DataBlock{}, 0); err == nil {
+		t.Fatal("expected error for datablock without content hash")
+	}
+}
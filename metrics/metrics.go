@@ -0,0 +1,15 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// DurationBuckets sync 처럼 몇 분씩 걸릴 수 있는 작업과 스트림 RPC 의 시간(초)용 bucket.
+// prometheus.DefBuckets 는 10 초까지라서 긴 작업이 모두 +Inf 로 들어감.
+var DurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// Handler prometheus 기본 registry 를 내보내는 http.Handler. metric 은 각 패키지에서 promauto 로 등록함.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,150 @@
+package protofile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// 디스크에 저장하는 *files.pb, datablock.pb 의 압축 방식.
+const (
+	// None 압축하지 않음. 이전 버전이 쓰던 형식과 같음.
+	None = "none"
+	// Gzip compress/gzip 으로 압축함. 경로 문자열이 반복되는 DataBlock 은 보통 10 배 이상 줄어듦.
+	Gzip = "gzip"
+	// Zstd zstd frame. 저장 방식으로는 쓸 수 없고, 읽을 때 이 형식이면 ErrUnsupportedCompression 을 반환함.
+	Zstd = "zstd"
+)
+
+// ErrUnsupportedCompression 이 빌드에서 지원하지 않는 압축 방식.
+var ErrUnsupportedCompression = errors.New("unsupported compression")
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Validate compression 이 저장에 쓸 수 있는 값인지 확인함. 빈 문자열은 None 과 같음.
+func Validate(compression string) error {
+	switch compression {
+	case "", None, Gzip:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q (expected %q or %q)", ErrUnsupportedCompression, compression, None, Gzip)
+	}
+}
+
+// Marshal msg 를 protobuf 로 직렬화하고 compression 으로 압축함.
+func Marshal(msg proto.Message, compression string) ([]byte, error) {
+	if err := Validate(compression); err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %w", msg, err)
+	}
+	if compression != Gzip {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress %T: %w", msg, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress %T: %w", msg, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Save msg 를 compression 으로 압축해서 filePath 에 씀. 같은 디렉터리의 임시 파일에 쓴 뒤 rename 해서,
+// 파일을 읽는 쪽이 쓰다 만 내용을 보지 않게 함. perm 은 umask 없이 그대로 적용됨.
+func Save(filePath string, msg proto.Message, perm os.FileMode, compression string) error {
+	data, err := Marshal(msg, compression)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", filePath, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to set permissions on %s: %w", filePath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// Detect data 앞부분의 magic number 로 압축 방식을 알아냄. 해당하는 것이 없으면 None.
+// 압축하지 않은 DataBlock/FileBlock 은 field tag(0x0a, 0x12) 로 시작하므로 magic 과 겹치지 않음.
+func Detect(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		return Gzip
+	case bytes.HasPrefix(data, zstdMagic):
+		return Zstd
+	default:
+		return None
+	}
+}
+
+// Decode 압축 방식을 자동으로 알아내서 풀어낸 protobuf 바이트를 반환함. 압축하지 않은 파일은 그대로 반환함.
+func Decode(data []byte) ([]byte, error) {
+	switch Detect(data) {
+	case Gzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip header: %w", err)
+		}
+		defer zr.Close()
+		out, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip data: %w", err)
+		}
+		return out, nil
+	case Zstd:
+		return nil, fmt.Errorf("%w: zstd", ErrUnsupportedCompression)
+	default:
+		return data, nil
+	}
+}
+
+// Unmarshal data 를 Decode 한 뒤 msg 로 역직렬화함.
+func Unmarshal(data []byte, msg proto.Message) error {
+	raw, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	if err := proto.Unmarshal(raw, msg); err != nil {
+		return fmt.Errorf("failed to unmarshal %T: %w", msg, err)
+	}
+	return nil
+}
+
+// Load filePath 를 읽어서 msg 로 역직렬화함. 압축 여부는 파일 내용으로 판단함.
+func Load(filePath string, msg proto.Message) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if err := Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("%s: %w", filePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package protofile
+
+import (
+	"errors"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testDataBlock() *pb.DataBlock {
+	fb := &pb.FileBlock{BlockId: "/data/run1/sample1", ColumnHeaders: []string{"R1", "R2"}}
+	for i := 0; i < 200; i++ {
+		fb.Rows = append(fb.Rows, &pb.Row{RowNumber: int32(i), Cells: map[string]string{
+			"R1": "/data/run1/sample1/reads_" + strings.Repeat("0", 5) + "_R1.fastq.gz",
+			"R2": "/data/run1/sample1/reads_" + strings.Repeat("0", 5) + "_R2.fastq.gz",
+		}})
+	}
+	return &pb.DataBlock{UpdatedAt: timestamppb.Now(), Blocks: []*pb.FileBlock{fb}}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	want := testDataBlock()
+	sizes := map[string]int64{}
+	for _, compression := range []string{"", None, Gzip} {
+		path := filepath.Join(dir, "datablock-"+compression+".pb")
+		if err := Save(path, want, 0o644, compression); err != nil {
+			t.Fatalf("Save(%q) failed: %v", compression, err)
+		}
+		got := &pb.DataBlock{}
+		if err := Load(path, got); err != nil {
+			t.Fatalf("Load(%q) failed: %v", compression, err)
+		}
+		if !proto.Equal(got, want) {
+			t.Errorf("%q: loaded DataBlock differs", compression)
+		}
+		info, _ := os.Stat(path)
+		sizes[compression] = info.Size()
+	}
+	if sizes[Gzip] >= sizes[None] {
+		t.Errorf("expected gzip file to be smaller: gzip=%d none=%d", sizes[Gzip], sizes[None])
+	}
+
+	// 이전 버전이 쓴 압축하지 않은 파일도 그대로 읽혀야 함.
+	raw, _ := proto.Marshal(want)
+	if Detect(raw) != None {
+		t.Errorf("uncompressed protobuf detected as %s", Detect(raw))
+	}
+}
+
+// TestSaveReplaces 기존 파일을 임시 파일 + rename 으로 교체해서, 임시 파일이 남지 않고 perm 이 적용되는지 확인함.
+func TestSaveReplaces(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "datablock.pb")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	want := testDataBlock()
+	if err := Save(path, want, 0o640, Gzip); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got := &pb.DataBlock{}
+	if err := Load(path, got); err != nil || !proto.Equal(got, want) {
+		t.Fatalf("expected saved DataBlock, got err %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o640 {
+		t.Errorf("expected mode 0640, got %v (err %v)", info.Mode(), err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only %s in the directory, got %v", path, entries)
+	}
+
+	// 없는 디렉터리에 쓰면 실패해야 함.
+	if err := Save(filepath.Join(dir, "missing", "datablock.pb"), want, 0o644, None); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestUnsupportedCompression(t *testing.T) {
+	if err := Validate(Zstd); !errors.Is(err, ErrUnsupportedCompression) {
+		t.Errorf("expected zstd to be rejected for writing, got %v", err)
+	}
+	if err := Validate("lz4"); !errors.Is(err, ErrUnsupportedCompression) {
+		t.Errorf("expected unknown compression to be rejected, got %v", err)
+	}
+	zstdFrame := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}
+	if err := Unmarshal(zstdFrame, &pb.DataBlock{}); !errors.Is(err, ErrUnsupportedCompression) {
+		t.Errorf("expected zstd data to be reported as unsupported, got %v", err)
+	}
+	if err := Unmarshal([]byte{0x1f, 0x8b, 0x00}, &pb.DataBlock{}); err == nil {
+		t.Error("expected truncated gzip data to fail")
+	}
+}
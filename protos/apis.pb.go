@@ -0,0 +1,1717 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: apis.proto
+
+package protos
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// (옵션) 클라이언트가 강제로 동기화를 요청할 때 사용 (필요 없으면 빈 메시지로 대체 가능)
+type SyncFoldersInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Force         bool                   `protobuf:"varint,1,opt,name=force,proto3" json:"force,omitempty"` // force update flag, 기본값 false
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncFoldersInfoRequest) Reset() {
+	*x = SyncFoldersInfoRequest{}
+	mi := &file_apis_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncFoldersInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncFoldersInfoRequest) ProtoMessage() {}
+
+func (x *SyncFoldersInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncFoldersInfoRequest.ProtoReflect.Descriptor instead.
+func (*SyncFoldersInfoRequest) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SyncFoldersInfoRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+// 동기화 작업 결과를 응답
+type SyncFoldersInfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 업데이트가 이루어졌으면 true, 그렇지 않으면 false
+	Updated       bool `protobuf:"varint,1,opt,name=updated,proto3" json:"updated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncFoldersInfoResponse) Reset() {
+	*x = SyncFoldersInfoResponse{}
+	mi := &file_apis_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncFoldersInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncFoldersInfoResponse) ProtoMessage() {}
+
+func (x *SyncFoldersInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncFoldersInfoResponse.ProtoReflect.Descriptor instead.
+func (*SyncFoldersInfoResponse) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SyncFoldersInfoResponse) GetUpdated() bool {
+	if x != nil {
+		return x.Updated
+	}
+	return false
+}
+
+// 단일 파일 블럭을 나타내는 메시지
+type FileBlock struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlockId       string                 `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`                   // 블록을 구분하기 위한 고유 ID (예: 파일 경로)
+	ColumnHeaders []string               `protobuf:"bytes,2,rep,name=column_headers,json=columnHeaders,proto3" json:"column_headers,omitempty"` // 컬럼 이름들
+	Rows          []*Row                 `protobuf:"bytes,3,rep,name=rows,proto3" json:"rows,omitempty"`                                        // 행 데이터
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileBlock) Reset() {
+	*x = FileBlock{}
+	mi := &file_apis_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileBlock) ProtoMessage() {}
+
+func (x *FileBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileBlock.ProtoReflect.Descriptor instead.
+func (*FileBlock) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FileBlock) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+func (x *FileBlock) GetColumnHeaders() []string {
+	if x != nil {
+		return x.ColumnHeaders
+	}
+	return nil
+}
+
+func (x *FileBlock) GetRows() []*Row {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+// 하나의 행(row)을 나타내며, 행 번호와 헤더-값 매핑을 포함
+type Row struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RowNumber     int32                  `protobuf:"varint,1,opt,name=row_number,json=rowNumber,proto3" json:"row_number,omitempty"`                                                 // 행 번호
+	Cells         map[string]string      `protobuf:"bytes,2,rep,name=cells,proto3" json:"cells,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 헤더 이름과 셀 값의 매핑
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	mi := &file_apis_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Row) GetRowNumber() int32 {
+	if x != nil {
+		return x.RowNumber
+	}
+	return 0
+}
+
+func (x *Row) GetCells() map[string]string {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+// 여러 파일 블럭을 묶어서 나타내는 메시지
+type DataBlock struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // 최종 업데이트 시간
+	Blocks    []*FileBlock           `protobuf:"bytes,2,rep,name=blocks,proto3" json:"blocks,omitempty"`                        // 파일 블럭 리스트
+	// 내용이 바뀔 때마다 1 씩 늘어나는 버전. 시계와 관계없이 비교할 수 있음. 0 이면 버전이 매겨지기 전의 파일.
+	Generation uint64 `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`
+	// blocks 내용만으로 계산한 hash ("sha256:<hex>"). updated_at, generation 은 포함하지 않음.
+	ContentHash   string `protobuf:"bytes,4,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataBlock) Reset() {
+	*x = DataBlock{}
+	mi := &file_apis_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataBlock) ProtoMessage() {}
+
+func (x *DataBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataBlock.ProtoReflect.Descriptor instead.
+func (*DataBlock) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DataBlock) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *DataBlock) GetBlocks() []*FileBlock {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *DataBlock) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *DataBlock) GetContentHash() string {
+	if x != nil {
+		return x.ContentHash
+	}
+	return ""
+}
+
+// 클라이언트가 현재 가지고 있는 데이터의 업데이트 타임스탬프를 포함하는 요청 메시지
+// 버전은 current_content_hash, current_generation, current_updated_at 순서로 먼저 채워진 값 하나로 비교함.
+type GetDataBlockRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 클라이언트가 마지막으로 받은 데이터의 updated_at 값. 이전 클라이언트 호환용.
+	CurrentUpdatedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=current_updated_at,json=currentUpdatedAt,proto3" json:"current_updated_at,omitempty"`
+	// 클라이언트가 마지막으로 받은 데이터의 generation
+	CurrentGeneration uint64 `protobuf:"varint,2,opt,name=current_generation,json=currentGeneration,proto3" json:"current_generation,omitempty"`
+	// 클라이언트가 마지막으로 받은 데이터의 content_hash
+	CurrentContentHash string `protobuf:"bytes,3,opt,name=current_content_hash,json=currentContentHash,proto3" json:"current_content_hash,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GetDataBlockRequest) Reset() {
+	*x = GetDataBlockRequest{}
+	mi := &file_apis_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataBlockRequest) ProtoMessage() {}
+
+func (x *GetDataBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataBlockRequest.ProtoReflect.Descriptor instead.
+func (*GetDataBlockRequest) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetDataBlockRequest) GetCurrentUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CurrentUpdatedAt
+	}
+	return nil
+}
+
+func (x *GetDataBlockRequest) GetCurrentGeneration() uint64 {
+	if x != nil {
+		return x.CurrentGeneration
+	}
+	return 0
+}
+
+func (x *GetDataBlockRequest) GetCurrentContentHash() string {
+	if x != nil {
+		return x.CurrentContentHash
+	}
+	return ""
+}
+
+// 서버가 응답으로 DataBlockData 를 포함하여 보내는 메시지
+type GetDataBlockResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Data  *DataBlock             `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// 예를 들어, 데이터가 최신이면 no_update 플래그를 true 로 설정할 수도 있음
+	NoUpdate bool `protobuf:"varint,2,opt,name=no_update,json=noUpdate,proto3" json:"no_update,omitempty"`
+	// 서버가 클라이언트 버전을 알지 못함(더 새로운 generation, 모르는 hash 등). 클라이언트는 가진 데이터를 버리고 data 로 바꿔야 함.
+	FullResync    bool `protobuf:"varint,3,opt,name=full_resync,json=fullResync,proto3" json:"full_resync,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDataBlockResponse) Reset() {
+	*x = GetDataBlockResponse{}
+	mi := &file_apis_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataBlockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataBlockResponse) ProtoMessage() {}
+
+func (x *GetDataBlockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataBlockResponse.ProtoReflect.Descriptor instead.
+func (*GetDataBlockResponse) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetDataBlockResponse) GetData() *DataBlock {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *GetDataBlockResponse) GetNoUpdate() bool {
+	if x != nil {
+		return x.NoUpdate
+	}
+	return false
+}
+
+func (x *GetDataBlockResponse) GetFullResync() bool {
+	if x != nil {
+		return x.FullResync
+	}
+	return false
+}
+
+// StreamDataBlock 의 첫 메시지. 뒤이어 올 FileBlock 수를 알려줌.
+type DataBlockHeader struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	TotalBlocks int32                  `protobuf:"varint,2,opt,name=total_blocks,json=totalBlocks,proto3" json:"total_blocks,omitempty"`
+	// 클라이언트 버전이 최신이면 true 이고, 뒤에 FileBlock 메시지가 오지 않음.
+	NoUpdate      bool   `protobuf:"varint,3,opt,name=no_update,json=noUpdate,proto3" json:"no_update,omitempty"`
+	Generation    uint64 `protobuf:"varint,4,opt,name=generation,proto3" json:"generation,omitempty"`
+	ContentHash   string `protobuf:"bytes,5,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	FullResync    bool   `protobuf:"varint,6,opt,name=full_resync,json=fullResync,proto3" json:"full_resync,omitempty"` // GetDataBlockResponse.full_resync 와 같음
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataBlockHeader) Reset() {
+	*x = DataBlockHeader{}
+	mi := &file_apis_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataBlockHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataBlockHeader) ProtoMessage() {}
+
+func (x *DataBlockHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataBlockHeader.ProtoReflect.Descriptor instead.
+func (*DataBlockHeader) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DataBlockHeader) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *DataBlockHeader) GetTotalBlocks() int32 {
+	if x != nil {
+		return x.TotalBlocks
+	}
+	return 0
+}
+
+func (x *DataBlockHeader) GetNoUpdate() bool {
+	if x != nil {
+		return x.NoUpdate
+	}
+	return false
+}
+
+func (x *DataBlockHeader) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *DataBlockHeader) GetContentHash() string {
+	if x != nil {
+		return x.ContentHash
+	}
+	return ""
+}
+
+func (x *DataBlockHeader) GetFullResync() bool {
+	if x != nil {
+		return x.FullResync
+	}
+	return false
+}
+
+// StreamDataBlock 의 메시지 하나. 첫 메시지는 header, 이후는 FileBlock 하나씩.
+type DataBlockChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Chunk:
+	//
+	//	*DataBlockChunk_Header
+	//	*DataBlockChunk_Block
+	Chunk         isDataBlockChunk_Chunk `protobuf_oneof:"chunk"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataBlockChunk) Reset() {
+	*x = DataBlockChunk{}
+	mi := &file_apis_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataBlockChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataBlockChunk) ProtoMessage() {}
+
+func (x *DataBlockChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataBlockChunk.ProtoReflect.Descriptor instead.
+func (*DataBlockChunk) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DataBlockChunk) GetChunk() isDataBlockChunk_Chunk {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *DataBlockChunk) GetHeader() *DataBlockHeader {
+	if x != nil {
+		if x, ok := x.Chunk.(*DataBlockChunk_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *DataBlockChunk) GetBlock() *FileBlock {
+	if x != nil {
+		if x, ok := x.Chunk.(*DataBlockChunk_Block); ok {
+			return x.Block
+		}
+	}
+	return nil
+}
+
+type isDataBlockChunk_Chunk interface {
+	isDataBlockChunk_Chunk()
+}
+
+type DataBlockChunk_Header struct {
+	Header *DataBlockHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type DataBlockChunk_Block struct {
+	Block *FileBlock `protobuf:"bytes,2,opt,name=block,proto3,oneof"`
+}
+
+func (*DataBlockChunk_Header) isDataBlockChunk_Chunk() {}
+
+func (*DataBlockChunk_Block) isDataBlockChunk_Chunk() {}
+
+// 클라이언트가 가진 버전 이후로 바뀐 부분만 요청하는 메시지
+// 기준 버전은 current_content_hash, current_generation, current_updated_at 순서로 채워진 값을 차례로 써서 history 에서 찾음.
+type GetDataBlockDeltaRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 클라이언트가 마지막으로 받은 데이터의 updated_at 값. 이전 클라이언트 호환용.
+	CurrentUpdatedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=current_updated_at,json=currentUpdatedAt,proto3" json:"current_updated_at,omitempty"`
+	// 클라이언트가 마지막으로 받은 데이터의 generation
+	CurrentGeneration uint64 `protobuf:"varint,2,opt,name=current_generation,json=currentGeneration,proto3" json:"current_generation,omitempty"`
+	// 클라이언트가 마지막으로 받은 데이터의 content_hash
+	CurrentContentHash string `protobuf:"bytes,3,opt,name=current_content_hash,json=currentContentHash,proto3" json:"current_content_hash,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GetDataBlockDeltaRequest) Reset() {
+	*x = GetDataBlockDeltaRequest{}
+	mi := &file_apis_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataBlockDeltaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataBlockDeltaRequest) ProtoMessage() {}
+
+func (x *GetDataBlockDeltaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataBlockDeltaRequest.ProtoReflect.Descriptor instead.
+func (*GetDataBlockDeltaRequest) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetDataBlockDeltaRequest) GetCurrentUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CurrentUpdatedAt
+	}
+	return nil
+}
+
+func (x *GetDataBlockDeltaRequest) GetCurrentGeneration() uint64 {
+	if x != nil {
+		return x.CurrentGeneration
+	}
+	return 0
+}
+
+func (x *GetDataBlockDeltaRequest) GetCurrentContentHash() string {
+	if x != nil {
+		return x.CurrentContentHash
+	}
+	return ""
+}
+
+// FileBlock 하나 안에서 바뀐 행들. 행은 row_number 로 구분함.
+type FileBlockDelta struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	BlockId           string                 `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	ColumnHeaders     []string               `protobuf:"bytes,2,rep,name=column_headers,json=columnHeaders,proto3" json:"column_headers,omitempty"` // 최신 컬럼 이름들
+	UpsertedRows      []*Row                 `protobuf:"bytes,3,rep,name=upserted_rows,json=upsertedRows,proto3" json:"upserted_rows,omitempty"`    // 새로 생기거나 내용이 바뀐 행
+	RemovedRowNumbers []int32                `protobuf:"varint,4,rep,packed,name=removed_row_numbers,json=removedRowNumbers,proto3" json:"removed_row_numbers,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FileBlockDelta) Reset() {
+	*x = FileBlockDelta{}
+	mi := &file_apis_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileBlockDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileBlockDelta) ProtoMessage() {}
+
+func (x *FileBlockDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileBlockDelta.ProtoReflect.Descriptor instead.
+func (*FileBlockDelta) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FileBlockDelta) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+func (x *FileBlockDelta) GetColumnHeaders() []string {
+	if x != nil {
+		return x.ColumnHeaders
+	}
+	return nil
+}
+
+func (x *FileBlockDelta) GetUpsertedRows() []*Row {
+	if x != nil {
+		return x.UpsertedRows
+	}
+	return nil
+}
+
+func (x *FileBlockDelta) GetRemovedRowNumbers() []int32 {
+	if x != nil {
+		return x.RemovedRowNumbers
+	}
+	return nil
+}
+
+// 클라이언트 버전과 서버의 최신 버전 사이의 차이
+type GetDataBlockDeltaResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // 서버의 최신 updated_at
+	NoUpdate  bool                   `protobuf:"varint,2,opt,name=no_update,json=noUpdate,proto3" json:"no_update,omitempty"`   // 클라이언트가 이미 최신 버전
+	// 서버가 클라이언트 버전을 모르거나 delta 가 더 큰 경우 true, 이때는 snapshot 으로 교체해야 함.
+	FullSnapshot    bool              `protobuf:"varint,3,opt,name=full_snapshot,json=fullSnapshot,proto3" json:"full_snapshot,omitempty"`
+	Snapshot        *DataBlock        `protobuf:"bytes,4,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	AddedBlocks     []*FileBlock      `protobuf:"bytes,5,rep,name=added_blocks,json=addedBlocks,proto3" json:"added_blocks,omitempty"`
+	RemovedBlockIds []string          `protobuf:"bytes,6,rep,name=removed_block_ids,json=removedBlockIds,proto3" json:"removed_block_ids,omitempty"`
+	ModifiedBlocks  []*FileBlockDelta `protobuf:"bytes,7,rep,name=modified_blocks,json=modifiedBlocks,proto3" json:"modified_blocks,omitempty"`
+	// 서버의 최신 generation, content_hash. 다음 요청의 current_generation, current_content_hash 로 씀.
+	Generation    uint64 `protobuf:"varint,8,opt,name=generation,proto3" json:"generation,omitempty"`
+	ContentHash   string `protobuf:"bytes,9,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDataBlockDeltaResponse) Reset() {
+	*x = GetDataBlockDeltaResponse{}
+	mi := &file_apis_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataBlockDeltaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataBlockDeltaResponse) ProtoMessage() {}
+
+func (x *GetDataBlockDeltaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataBlockDeltaResponse.ProtoReflect.Descriptor instead.
+func (*GetDataBlockDeltaResponse) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetDataBlockDeltaResponse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *GetDataBlockDeltaResponse) GetNoUpdate() bool {
+	if x != nil {
+		return x.NoUpdate
+	}
+	return false
+}
+
+func (x *GetDataBlockDeltaResponse) GetFullSnapshot() bool {
+	if x != nil {
+		return x.FullSnapshot
+	}
+	return false
+}
+
+func (x *GetDataBlockDeltaResponse) GetSnapshot() *DataBlock {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+func (x *GetDataBlockDeltaResponse) GetAddedBlocks() []*FileBlock {
+	if x != nil {
+		return x.AddedBlocks
+	}
+	return nil
+}
+
+func (x *GetDataBlockDeltaResponse) GetRemovedBlockIds() []string {
+	if x != nil {
+		return x.RemovedBlockIds
+	}
+	return nil
+}
+
+func (x *GetDataBlockDeltaResponse) GetModifiedBlocks() []*FileBlockDelta {
+	if x != nil {
+		return x.ModifiedBlocks
+	}
+	return nil
+}
+
+func (x *GetDataBlockDeltaResponse) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *GetDataBlockDeltaResponse) GetContentHash() string {
+	if x != nil {
+		return x.ContentHash
+	}
+	return ""
+}
+
+// UI 에서 선택한 셀 하나. 행은 row_number 또는 row key(rule.json 의 rowRules 로 만든 키)로 지정함.
+type PathSelection struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	BlockId string                 `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// Types that are valid to be assigned to Row:
+	//
+	//	*PathSelection_RowNumber
+	//	*PathSelection_RowKey
+	Row           isPathSelection_Row `protobuf_oneof:"row"`
+	Column        string              `protobuf:"bytes,4,opt,name=column,proto3" json:"column,omitempty"` // 컬럼 헤더 이름
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PathSelection) Reset() {
+	*x = PathSelection{}
+	mi := &file_apis_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathSelection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathSelection) ProtoMessage() {}
+
+func (x *PathSelection) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathSelection.ProtoReflect.Descriptor instead.
+func (*PathSelection) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PathSelection) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+func (x *PathSelection) GetRow() isPathSelection_Row {
+	if x != nil {
+		return x.Row
+	}
+	return nil
+}
+
+func (x *PathSelection) GetRowNumber() int32 {
+	if x != nil {
+		if x, ok := x.Row.(*PathSelection_RowNumber); ok {
+			return x.RowNumber
+		}
+	}
+	return 0
+}
+
+func (x *PathSelection) GetRowKey() string {
+	if x != nil {
+		if x, ok := x.Row.(*PathSelection_RowKey); ok {
+			return x.RowKey
+		}
+	}
+	return ""
+}
+
+func (x *PathSelection) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+type isPathSelection_Row interface {
+	isPathSelection_Row()
+}
+
+type PathSelection_RowNumber struct {
+	RowNumber int32 `protobuf:"varint,2,opt,name=row_number,json=rowNumber,proto3,oneof"`
+}
+
+type PathSelection_RowKey struct {
+	RowKey string `protobuf:"bytes,3,opt,name=row_key,json=rowKey,proto3,oneof"`
+}
+
+func (*PathSelection_RowNumber) isPathSelection_Row() {}
+
+func (*PathSelection_RowKey) isPathSelection_Row() {}
+
+type ResolvePathsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Selections    []*PathSelection       `protobuf:"bytes,1,rep,name=selections,proto3" json:"selections,omitempty"`
+	Relative      bool                   `protobuf:"varint,2,opt,name=relative,proto3" json:"relative,omitempty"` // true 면 RootDir 기준 상대 경로, false 면 절대 경로
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolvePathsRequest) Reset() {
+	*x = ResolvePathsRequest{}
+	mi := &file_apis_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolvePathsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolvePathsRequest) ProtoMessage() {}
+
+func (x *ResolvePathsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolvePathsRequest.ProtoReflect.Descriptor instead.
+func (*ResolvePathsRequest) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ResolvePathsRequest) GetSelections() []*PathSelection {
+	if x != nil {
+		return x.Selections
+	}
+	return nil
+}
+
+func (x *ResolvePathsRequest) GetRelative() bool {
+	if x != nil {
+		return x.Relative
+	}
+	return false
+}
+
+// selection 하나에 대한 결과. 실패하면 path 는 비어 있고 error 에 이유가 담김.
+type ResolvedPath struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Selection *PathSelection         `protobuf:"bytes,1,opt,name=selection,proto3" json:"selection,omitempty"`
+	Path      string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Error     string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// 마지막 sync 때 계산한 파일 내용 checksum ("<algorithm>:<hex>", 예: "sha256:9f86...").
+	// 서버가 checksum 을 쓰지 않거나 아직 계산하지 않았으면 비어 있음.
+	Checksum      string `protobuf:"bytes,4,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolvedPath) Reset() {
+	*x = ResolvedPath{}
+	mi := &file_apis_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolvedPath) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolvedPath) ProtoMessage() {}
+
+func (x *ResolvedPath) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolvedPath.ProtoReflect.Descriptor instead.
+func (*ResolvedPath) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ResolvedPath) GetSelection() *PathSelection {
+	if x != nil {
+		return x.Selection
+	}
+	return nil
+}
+
+func (x *ResolvedPath) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ResolvedPath) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ResolvedPath) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+type ResolvePathsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ResolvedPath        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // selections 와 같은 순서
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolvePathsResponse) Reset() {
+	*x = ResolvePathsResponse{}
+	mi := &file_apis_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolvePathsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolvePathsResponse) ProtoMessage() {}
+
+func (x *ResolvePathsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolvePathsResponse.ProtoReflect.Descriptor instead.
+func (*ResolvePathsResponse) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ResolvePathsResponse) GetResults() []*ResolvedPath {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// template 은 {{ fileBlock "X" | row 3 | col "R1" }} 같은 자리표시자를 가진 user script.
+// col 의 경로는 shell 용으로 따옴표를 쳐서 채우고, {{ ... | col "R1" | raw }} 면 그대로 채움.
+type RenderScriptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Template      string                 `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	Relative      bool                   `protobuf:"varint,2,opt,name=relative,proto3" json:"relative,omitempty"` // true 면 RootDir 기준 상대 경로로 채움
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderScriptRequest) Reset() {
+	*x = RenderScriptRequest{}
+	mi := &file_apis_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderScriptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderScriptRequest) ProtoMessage() {}
+
+func (x *RenderScriptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderScriptRequest.ProtoReflect.Descriptor instead.
+func (*RenderScriptRequest) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RenderScriptRequest) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *RenderScriptRequest) GetRelative() bool {
+	if x != nil {
+		return x.Relative
+	}
+	return false
+}
+
+// 자리표시자를 하나라도 채우지 못하면 script 는 비어 있고 errors 에 실패한 자리표시자가 모두 담김.
+type RenderScriptResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Script        string                 `protobuf:"bytes,1,opt,name=script,proto3" json:"script,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderScriptResponse) Reset() {
+	*x = RenderScriptResponse{}
+	mi := &file_apis_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderScriptResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderScriptResponse) ProtoMessage() {}
+
+func (x *RenderScriptResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderScriptResponse.ProtoReflect.Descriptor instead.
+func (*RenderScriptResponse) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RenderScriptResponse) GetScript() string {
+	if x != nil {
+		return x.Script
+	}
+	return ""
+}
+
+func (x *RenderScriptResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+// 비어 있는 조건은 무시하고, 설정된 조건은 모두 만족해야 함.
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlockIdPrefix string                 `protobuf:"bytes,1,opt,name=block_id_prefix,json=blockIdPrefix,proto3" json:"block_id_prefix,omitempty"` // RootDir 기준 상대 경로 prefix. opaque 모드에서는 완전한 block ID
+	Headers       []string               `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty"`                                    // 이 컬럼들을 모두 가진 FileBlock
+	CellGlob      string                 `protobuf:"bytes,3,opt,name=cell_glob,json=cellGlob,proto3" json:"cell_glob,omitempty"`                  // 셀(파일 이름) glob 패턴
+	CellRegex     string                 `protobuf:"bytes,4,opt,name=cell_regex,json=cellRegex,proto3" json:"cell_regex,omitempty"`               // 셀(파일 이름) 정규식
+	Column        string                 `protobuf:"bytes,5,opt,name=column,proto3" json:"column,omitempty"`                                      // 셀 조건을 적용할 컬럼 (비어 있으면 모든 컬럼)
+	MinRows       int32                  `protobuf:"varint,6,opt,name=min_rows,json=minRows,proto3" json:"min_rows,omitempty"`
+	MaxRows       int32                  `protobuf:"varint,7,opt,name=max_rows,json=maxRows,proto3" json:"max_rows,omitempty"`
+	PageSize      int32                  `protobuf:"varint,8,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,9,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_apis_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SearchRequest) GetBlockIdPrefix() string {
+	if x != nil {
+		return x.BlockIdPrefix
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetHeaders() []string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetCellGlob() string {
+	if x != nil {
+		return x.CellGlob
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetCellRegex() string {
+	if x != nil {
+		return x.CellRegex
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetMinRows() int32 {
+	if x != nil {
+		return x.MinRows
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetMaxRows() int32 {
+	if x != nil {
+		return x.MaxRows
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// 셀 조건이 있으면 각 FileBlock 에는 일치하는 행만 들어 있음.
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Blocks        []*FileBlock           `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	TotalBlocks   int32                  `protobuf:"varint,2,opt,name=total_blocks,json=totalBlocks,proto3" json:"total_blocks,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // 검색한 DataBlock 의 updated_at
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_apis_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SearchResponse) GetBlocks() []*FileBlock {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetTotalBlocks() int32 {
+	if x != nil {
+		return x.TotalBlocks
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// UI 에 넘길 DataBlock 요청. 서버 경로는 빠지고 block_id 는 RootDir 기준 상대 경로(또는 opaque ID),
+// 셀은 파일 이름만 담김.
+type GetUIDataBlockRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 클라이언트가 마지막으로 받은 데이터의 updated_at 값
+	CurrentUpdatedAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=current_updated_at,json=currentUpdatedAt,proto3" json:"current_updated_at,omitempty"`
+	Columns          []string               `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"` // 이 컬럼들만 남김 (비어 있으면 모든 컬럼)
+	// GetDataBlockRequest 와 같음. UI DataBlock 은 원본 DataBlock 의 generation, content_hash 를 그대로 가짐.
+	CurrentGeneration  uint64 `protobuf:"varint,3,opt,name=current_generation,json=currentGeneration,proto3" json:"current_generation,omitempty"`
+	CurrentContentHash string `protobuf:"bytes,4,opt,name=current_content_hash,json=currentContentHash,proto3" json:"current_content_hash,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GetUIDataBlockRequest) Reset() {
+	*x = GetUIDataBlockRequest{}
+	mi := &file_apis_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUIDataBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUIDataBlockRequest) ProtoMessage() {}
+
+func (x *GetUIDataBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUIDataBlockRequest.ProtoReflect.Descriptor instead.
+func (*GetUIDataBlockRequest) Descriptor() ([]byte, []int) {
+	return file_apis_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetUIDataBlockRequest) GetCurrentUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CurrentUpdatedAt
+	}
+	return nil
+}
+
+func (x *GetUIDataBlockRequest) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *GetUIDataBlockRequest) GetCurrentGeneration() uint64 {
+	if x != nil {
+		return x.CurrentGeneration
+	}
+	return 0
+}
+
+func (x *GetUIDataBlockRequest) GetCurrentContentHash() string {
+	if x != nil {
+		return x.CurrentContentHash
+	}
+	return ""
+}
+
+var File_apis_proto protoreflect.FileDescriptor
+
+const file_apis_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"apis.proto\x12\x06protos\x1a\x1fgoogle/protobuf/timestamp.proto\".\n" +
+	"\x16SyncFoldersInfoRequest\x12\x14\n" +
+	"\x05force\x18\x01 \x01(\bR\x05force\"3\n" +
+	"\x17SyncFoldersInfoResponse\x12\x18\n" +
+	"\aupdated\x18\x01 \x01(\bR\aupdated\"n\n" +
+	"\tFileBlock\x12\x19\n" +
+	"\bblock_id\x18\x01 \x01(\tR\ablockId\x12%\n" +
+	"\x0ecolumn_headers\x18\x02 \x03(\tR\rcolumnHeaders\x12\x1f\n" +
+	"\x04rows\x18\x03 \x03(\v2\v.protos.RowR\x04rows\"\x8c\x01\n" +
+	"\x03Row\x12\x1d\n" +
+	"\n" +
+	"row_number\x18\x01 \x01(\x05R\trowNumber\x12,\n" +
+	"\x05cells\x18\x02 \x03(\v2\x16.protos.Row.CellsEntryR\x05cells\x1a8\n" +
+	"\n" +
+	"CellsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb4\x01\n" +
+	"\tDataBlock\x129\n" +
+	"\n" +
+	"updated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12)\n" +
+	"\x06blocks\x18\x02 \x03(\v2\x11.protos.FileBlockR\x06blocks\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x04R\n" +
+	"generation\x12!\n" +
+	"\fcontent_hash\x18\x04 \x01(\tR\vcontentHash\"\xc0\x01\n" +
+	"\x13GetDataBlockRequest\x12H\n" +
+	"\x12current_updated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x10currentUpdatedAt\x12-\n" +
+	"\x12current_generation\x18\x02 \x01(\x04R\x11currentGeneration\x120\n" +
+	"\x14current_content_hash\x18\x03 \x01(\tR\x12currentContentHash\"{\n" +
+	"\x14GetDataBlockResponse\x12%\n" +
+	"\x04data\x18\x01 \x01(\v2\x11.protos.DataBlockR\x04data\x12\x1b\n" +
+	"\tno_update\x18\x02 \x01(\bR\bnoUpdate\x12\x1f\n" +
+	"\vfull_resync\x18\x03 \x01(\bR\n" +
+	"fullResync\"\xf0\x01\n" +
+	"\x0fDataBlockHeader\x129\n" +
+	"\n" +
+	"updated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12!\n" +
+	"\ftotal_blocks\x18\x02 \x01(\x05R\vtotalBlocks\x12\x1b\n" +
+	"\tno_update\x18\x03 \x01(\bR\bnoUpdate\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x04 \x01(\x04R\n" +
+	"generation\x12!\n" +
+	"\fcontent_hash\x18\x05 \x01(\tR\vcontentHash\x12\x1f\n" +
+	"\vfull_resync\x18\x06 \x01(\bR\n" +
+	"fullResync\"w\n" +
+	"\x0eDataBlockChunk\x121\n" +
+	"\x06header\x18\x01 \x01(\v2\x17.protos.DataBlockHeaderH\x00R\x06header\x12)\n" +
+	"\x05block\x18\x02 \x01(\v2\x11.protos.FileBlockH\x00R\x05blockB\a\n" +
+	"\x05chunk\"\xc5\x01\n" +
+	"\x18GetDataBlockDeltaRequest\x12H\n" +
+	"\x12current_updated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x10currentUpdatedAt\x12-\n" +
+	"\x12current_generation\x18\x02 \x01(\x04R\x11currentGeneration\x120\n" +
+	"\x14current_content_hash\x18\x03 \x01(\tR\x12currentContentHash\"\xb4\x01\n" +
+	"\x0eFileBlockDelta\x12\x19\n" +
+	"\bblock_id\x18\x01 \x01(\tR\ablockId\x12%\n" +
+	"\x0ecolumn_headers\x18\x02 \x03(\tR\rcolumnHeaders\x120\n" +
+	"\rupserted_rows\x18\x03 \x03(\v2\v.protos.RowR\fupsertedRows\x12.\n" +
+	"\x13removed_row_numbers\x18\x04 \x03(\x05R\x11removedRowNumbers\"\xad\x03\n" +
+	"\x19GetDataBlockDeltaResponse\x129\n" +
+	"\n" +
+	"updated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1b\n" +
+	"\tno_update\x18\x02 \x01(\bR\bnoUpdate\x12#\n" +
+	"\rfull_snapshot\x18\x03 \x01(\bR\ffullSnapshot\x12-\n" +
+	"\bsnapshot\x18\x04 \x01(\v2\x11.protos.DataBlockR\bsnapshot\x124\n" +
+	"\fadded_blocks\x18\x05 \x03(\v2\x11.protos.FileBlockR\vaddedBlocks\x12*\n" +
+	"\x11removed_block_ids\x18\x06 \x03(\tR\x0fremovedBlockIds\x12?\n" +
+	"\x0fmodified_blocks\x18\a \x03(\v2\x16.protos.FileBlockDeltaR\x0emodifiedBlocks\x12\x1e\n" +
+	"\n" +
+	"generation\x18\b \x01(\x04R\n" +
+	"generation\x12!\n" +
+	"\fcontent_hash\x18\t \x01(\tR\vcontentHash\"\x85\x01\n" +
+	"\rPathSelection\x12\x19\n" +
+	"\bblock_id\x18\x01 \x01(\tR\ablockId\x12\x1f\n" +
+	"\n" +
+	"row_number\x18\x02 \x01(\x05H\x00R\trowNumber\x12\x19\n" +
+	"\arow_key\x18\x03 \x01(\tH\x00R\x06rowKey\x12\x16\n" +
+	"\x06column\x18\x04 \x01(\tR\x06columnB\x05\n" +
+	"\x03row\"h\n" +
+	"\x13ResolvePathsRequest\x125\n" +
+	"\n" +
+	"selections\x18\x01 \x03(\v2\x15.protos.PathSelectionR\n" +
+	"selections\x12\x1a\n" +
+	"\brelative\x18\x02 \x01(\bR\brelative\"\x89\x01\n" +
+	"\fResolvedPath\x123\n" +
+	"\tselection\x18\x01 \x01(\v2\x15.protos.PathSelectionR\tselection\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1a\n" +
+	"\bchecksum\x18\x04 \x01(\tR\bchecksum\"F\n" +
+	"\x14ResolvePathsResponse\x12.\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.protos.ResolvedPathR\aresults\"M\n" +
+	"\x13RenderScriptRequest\x12\x1a\n" +
+	"\btemplate\x18\x01 \x01(\tR\btemplate\x12\x1a\n" +
+	"\brelative\x18\x02 \x01(\bR\brelative\"F\n" +
+	"\x14RenderScriptResponse\x12\x16\n" +
+	"\x06script\x18\x01 \x01(\tR\x06script\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\"\x97\x02\n" +
+	"\rSearchRequest\x12&\n" +
+	"\x0fblock_id_prefix\x18\x01 \x01(\tR\rblockIdPrefix\x12\x18\n" +
+	"\aheaders\x18\x02 \x03(\tR\aheaders\x12\x1b\n" +
+	"\tcell_glob\x18\x03 \x01(\tR\bcellGlob\x12\x1d\n" +
+	"\n" +
+	"cell_regex\x18\x04 \x01(\tR\tcellRegex\x12\x16\n" +
+	"\x06column\x18\x05 \x01(\tR\x06column\x12\x19\n" +
+	"\bmin_rows\x18\x06 \x01(\x05R\aminRows\x12\x19\n" +
+	"\bmax_rows\x18\a \x01(\x05R\amaxRows\x12\x1b\n" +
+	"\tpage_size\x18\b \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\t \x01(\tR\tpageToken\"\xc1\x01\n" +
+	"\x0eSearchResponse\x12)\n" +
+	"\x06blocks\x18\x01 \x03(\v2\x11.protos.FileBlockR\x06blocks\x12!\n" +
+	"\ftotal_blocks\x18\x02 \x01(\x05R\vtotalBlocks\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xdc\x01\n" +
+	"\x15GetUIDataBlockRequest\x12H\n" +
+	"\x12current_updated_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x10currentUpdatedAt\x12\x18\n" +
+	"\acolumns\x18\x02 \x03(\tR\acolumns\x12-\n" +
+	"\x12current_generation\x18\x03 \x01(\x04R\x11currentGeneration\x120\n" +
+	"\x14current_content_hash\x18\x04 \x01(\tR\x12currentContentHash2c\n" +
+	"\rDBApisService\x12R\n" +
+	"\x0fSyncFoldersInfo\x12\x1e.protos.SyncFoldersInfoRequest\x1a\x1f.protos.SyncFoldersInfoResponse2\xee\x04\n" +
+	"\x10DataBlockService\x12I\n" +
+	"\fGetDataBlock\x12\x1b.protos.GetDataBlockRequest\x1a\x1c.protos.GetDataBlockResponse\x12H\n" +
+	"\x0fStreamDataBlock\x12\x1b.protos.GetDataBlockRequest\x1a\x16.protos.DataBlockChunk0\x01\x12M\n" +
+	"\x0eWatchDataBlock\x12\x1b.protos.GetDataBlockRequest\x1a\x1c.protos.GetDataBlockResponse0\x01\x12X\n" +
+	"\x11GetDataBlockDelta\x12 .protos.GetDataBlockDeltaRequest\x1a!.protos.GetDataBlockDeltaResponse\x12I\n" +
+	"\fResolvePaths\x12\x1b.protos.ResolvePathsRequest\x1a\x1c.protos.ResolvePathsResponse\x12I\n" +
+	"\fRenderScript\x12\x1b.protos.RenderScriptRequest\x1a\x1c.protos.RenderScriptResponse\x127\n" +
+	"\x06Search\x12\x15.protos.SearchRequest\x1a\x16.protos.SearchResponse\x12M\n" +
+	"\x0eGetUIDataBlock\x12\x1d.protos.GetUIDataBlockRequest\x1a\x1c.protos.GetDataBlockResponseB\"Z github.com/seoyhaein/tori/protosb\x06proto3"
+
+var (
+	file_apis_proto_rawDescOnce sync.Once
+	file_apis_proto_rawDescData []byte
+)
+
+func file_apis_proto_rawDescGZIP() []byte {
+	file_apis_proto_rawDescOnce.Do(func() {
+		file_apis_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_apis_proto_rawDesc), len(file_apis_proto_rawDesc)))
+	})
+	return file_apis_proto_rawDescData
+}
+
+var file_apis_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_apis_proto_goTypes = []any{
+	(*SyncFoldersInfoRequest)(nil),    // 0: protos.SyncFoldersInfoRequest
+	(*SyncFoldersInfoResponse)(nil),   // 1: protos.SyncFoldersInfoResponse
+	(*FileBlock)(nil),                 // 2: protos.FileBlock
+	(*Row)(nil),                       // 3: protos.Row
+	(*DataBlock)(nil),                 // 4: protos.DataBlock
+	(*GetDataBlockRequest)(nil),       // 5: protos.GetDataBlockRequest
+	(*GetDataBlockResponse)(nil),      // 6: protos.GetDataBlockResponse
+	(*DataBlockHeader)(nil),           // 7: protos.DataBlockHeader
+	(*DataBlockChunk)(nil),            // 8: protos.DataBlockChunk
+	(*GetDataBlockDeltaRequest)(nil),  // 9: protos.GetDataBlockDeltaRequest
+	(*FileBlockDelta)(nil),            // 10: protos.FileBlockDelta
+	(*GetDataBlockDeltaResponse)(nil), // 11: protos.GetDataBlockDeltaResponse
+	(*PathSelection)(nil),             // 12: protos.PathSelection
+	(*ResolvePathsRequest)(nil),       // 13: protos.ResolvePathsRequest
+	(*ResolvedPath)(nil),              // 14: protos.ResolvedPath
+	(*ResolvePathsResponse)(nil),      // 15: protos.ResolvePathsResponse
+	(*RenderScriptRequest)(nil),       // 16: protos.RenderScriptRequest
+	(*RenderScriptResponse)(nil),      // 17: protos.RenderScriptResponse
+	(*SearchRequest)(nil),             // 18: protos.SearchRequest
+	(*SearchResponse)(nil),            // 19: protos.SearchResponse
+	(*GetUIDataBlockRequest)(nil),     // 20: protos.GetUIDataBlockRequest
+	nil,                               // 21: protos.Row.CellsEntry
+	(*timestamppb.Timestamp)(nil),     // 22: google.protobuf.Timestamp
+}
+var file_apis_proto_depIdxs = []int32{
+	3,  // 0: protos.FileBlock.rows:type_name -> protos.Row
+	21, // 1: protos.Row.cells:type_name -> protos.Row.CellsEntry
+	22, // 2: protos.DataBlock.updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 3: protos.DataBlock.blocks:type_name -> protos.FileBlock
+	22, // 4: protos.GetDataBlockRequest.current_updated_at:type_name -> google.protobuf.Timestamp
+	4,  // 5: protos.GetDataBlockResponse.data:type_name -> protos.DataBlock
+	22, // 6: protos.DataBlockHeader.updated_at:type_name -> google.protobuf.Timestamp
+	7,  // 7: protos.DataBlockChunk.header:type_name -> protos.DataBlockHeader
+	2,  // 8: protos.DataBlockChunk.block:type_name -> protos.FileBlock
+	22, // 9: protos.GetDataBlockDeltaRequest.current_updated_at:type_name -> google.protobuf.Timestamp
+	3,  // 10: protos.FileBlockDelta.upserted_rows:type_name -> protos.Row
+	22, // 11: protos.GetDataBlockDeltaResponse.updated_at:type_name -> google.protobuf.Timestamp
+	4,  // 12: protos.GetDataBlockDeltaResponse.snapshot:type_name -> protos.DataBlock
+	2,  // 13: protos.GetDataBlockDeltaResponse.added_blocks:type_name -> protos.FileBlock
+	10, // 14: protos.GetDataBlockDeltaResponse.modified_blocks:type_name -> protos.FileBlockDelta
+	12, // 15: protos.ResolvePathsRequest.selections:type_name -> protos.PathSelection
+	12, // 16: protos.ResolvedPath.selection:type_name -> protos.PathSelection
+	14, // 17: protos.ResolvePathsResponse.results:type_name -> protos.ResolvedPath
+	2,  // 18: protos.SearchResponse.blocks:type_name -> protos.FileBlock
+	22, // 19: protos.SearchResponse.updated_at:type_name -> google.protobuf.Timestamp
+	22, // 20: protos.GetUIDataBlockRequest.current_updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 21: protos.DBApisService.SyncFoldersInfo:input_type -> protos.SyncFoldersInfoRequest
+	5,  // 22: protos.DataBlockService.GetDataBlock:input_type -> protos.GetDataBlockRequest
+	5,  // 23: protos.DataBlockService.StreamDataBlock:input_type -> protos.GetDataBlockRequest
+	5,  // 24: protos.DataBlockService.WatchDataBlock:input_type -> protos.GetDataBlockRequest
+	9,  // 25: protos.DataBlockService.GetDataBlockDelta:input_type -> protos.GetDataBlockDeltaRequest
+	13, // 26: protos.DataBlockService.ResolvePaths:input_type -> protos.ResolvePathsRequest
+	16, // 27: protos.DataBlockService.RenderScript:input_type -> protos.RenderScriptRequest
+	18, // 28: protos.DataBlockService.Search:input_type -> protos.SearchRequest
+	20, // 29: protos.DataBlockService.GetUIDataBlock:input_type -> protos.GetUIDataBlockRequest
+	1,  // 30: protos.DBApisService.SyncFoldersInfo:output_type -> protos.SyncFoldersInfoResponse
+	6,  // 31: protos.DataBlockService.GetDataBlock:output_type -> protos.GetDataBlockResponse
+	8,  // 32: protos.DataBlockService.StreamDataBlock:output_type -> protos.DataBlockChunk
+	6,  // 33: protos.DataBlockService.WatchDataBlock:output_type -> protos.GetDataBlockResponse
+	11, // 34: protos.DataBlockService.GetDataBlockDelta:output_type -> protos.GetDataBlockDeltaResponse
+	15, // 35: protos.DataBlockService.ResolvePaths:output_type -> protos.ResolvePathsResponse
+	17, // 36: protos.DataBlockService.RenderScript:output_type -> protos.RenderScriptResponse
+	19, // 37: protos.DataBlockService.Search:output_type -> protos.SearchResponse
+	6,  // 38: protos.DataBlockService.GetUIDataBlock:output_type -> protos.GetDataBlockResponse
+	30, // [30:39] is the sub-list for method output_type
+	21, // [21:30] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_apis_proto_init() }
+func file_apis_proto_init() {
+	if File_apis_proto != nil {
+		return
+	}
+	file_apis_proto_msgTypes[8].OneofWrappers = []any{
+		(*DataBlockChunk_Header)(nil),
+		(*DataBlockChunk_Block)(nil),
+	}
+	file_apis_proto_msgTypes[12].OneofWrappers = []any{
+		(*PathSelection_RowNumber)(nil),
+		(*PathSelection_RowKey)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_apis_proto_rawDesc), len(file_apis_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_apis_proto_goTypes,
+		DependencyIndexes: file_apis_proto_depIdxs,
+		MessageInfos:      file_apis_proto_msgTypes,
+	}.Build()
+	File_apis_proto = out.File
+	file_apis_proto_goTypes = nil
+	file_apis_proto_depIdxs = nil
+}
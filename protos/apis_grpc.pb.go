@@ -0,0 +1,570 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: apis.proto
+
+package protos
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	DBApisService_SyncFoldersInfo_FullMethodName = "/protos.DBApisService/SyncFoldersInfo"
+)
+
+// DBApisServiceClient is the client API for DBApisService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DBApisService 대신 SyncFoldersInfo 라는 이름의 서비스를 정의
+type DBApisServiceClient interface {
+	// 클라이언트의 요청에 따라 서버의 폴더와 DB를 비교한 후, 업데이트가 필요한 경우 수행하고 결과를 반환
+	SyncFoldersInfo(ctx context.Context, in *SyncFoldersInfoRequest, opts ...grpc.CallOption) (*SyncFoldersInfoResponse, error)
+}
+
+type dBApisServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDBApisServiceClient(cc grpc.ClientConnInterface) DBApisServiceClient {
+	return &dBApisServiceClient{cc}
+}
+
+func (c *dBApisServiceClient) SyncFoldersInfo(ctx context.Context, in *SyncFoldersInfoRequest, opts ...grpc.CallOption) (*SyncFoldersInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncFoldersInfoResponse)
+	err := c.cc.Invoke(ctx, DBApisService_SyncFoldersInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DBApisServiceServer is the server API for DBApisService service.
+// All implementations must embed UnimplementedDBApisServiceServer
+// for forward compatibility.
+//
+// DBApisService 대신 SyncFoldersInfo 라는 이름의 서비스를 정의
+type DBApisServiceServer interface {
+	// 클라이언트의 요청에 따라 서버의 폴더와 DB를 비교한 후, 업데이트가 필요한 경우 수행하고 결과를 반환
+	SyncFoldersInfo(context.Context, *SyncFoldersInfoRequest) (*SyncFoldersInfoResponse, error)
+	mustEmbedUnimplementedDBApisServiceServer()
+}
+
+// UnimplementedDBApisServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDBApisServiceServer struct{}
+
+func (UnimplementedDBApisServiceServer) SyncFoldersInfo(context.Context, *SyncFoldersInfoRequest) (*SyncFoldersInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncFoldersInfo not implemented")
+}
+func (UnimplementedDBApisServiceServer) mustEmbedUnimplementedDBApisServiceServer() {}
+func (UnimplementedDBApisServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeDBApisServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DBApisServiceServer will
+// result in compilation errors.
+type UnsafeDBApisServiceServer interface {
+	mustEmbedUnimplementedDBApisServiceServer()
+}
+
+func RegisterDBApisServiceServer(s grpc.ServiceRegistrar, srv DBApisServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDBApisServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DBApisService_ServiceDesc, srv)
+}
+
+func _DBApisService_SyncFoldersInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncFoldersInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DBApisServiceServer).SyncFoldersInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DBApisService_SyncFoldersInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DBApisServiceServer).SyncFoldersInfo(ctx, req.(*SyncFoldersInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DBApisService_ServiceDesc is the grpc.ServiceDesc for DBApisService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DBApisService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.DBApisService",
+	HandlerType: (*DBApisServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SyncFoldersInfo",
+			Handler:    _DBApisService_SyncFoldersInfo_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "apis.proto",
+}
+
+const (
+	DataBlockService_GetDataBlock_FullMethodName      = "/protos.DataBlockService/GetDataBlock"
+	DataBlockService_StreamDataBlock_FullMethodName   = "/protos.DataBlockService/StreamDataBlock"
+	DataBlockService_WatchDataBlock_FullMethodName    = "/protos.DataBlockService/WatchDataBlock"
+	DataBlockService_GetDataBlockDelta_FullMethodName = "/protos.DataBlockService/GetDataBlockDelta"
+	DataBlockService_ResolvePaths_FullMethodName      = "/protos.DataBlockService/ResolvePaths"
+	DataBlockService_RenderScript_FullMethodName      = "/protos.DataBlockService/RenderScript"
+	DataBlockService_Search_FullMethodName            = "/protos.DataBlockService/Search"
+	DataBlockService_GetUIDataBlock_FullMethodName    = "/protos.DataBlockService/GetUIDataBlock"
+)
+
+// DataBlockServiceClient is the client API for DataBlockService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DataBlockService: 클라이언트의 요청에 대해 DataBlockData 를 반환하는 서비스
+type DataBlockServiceClient interface {
+	GetDataBlock(ctx context.Context, in *GetDataBlockRequest, opts ...grpc.CallOption) (*GetDataBlockResponse, error)
+	// GetDataBlock 과 같지만 header 다음에 FileBlock 을 하나씩 나눠 보냄. 메시지 크기 제한을 넘는 큰 DataBlock 용.
+	StreamDataBlock(ctx context.Context, in *GetDataBlockRequest, opts ...grpc.CallOption) (DataBlockService_StreamDataBlockClient, error)
+	// 현재 DataBlock 을 바로 보내고, 이후 sync 로 DataBlock 이 갱신될 때마다 새 DataBlock 을 push 함.
+	// current_updated_at 이 서버와 같으면 첫 메시지는 no_update 로 보냄.
+	WatchDataBlock(ctx context.Context, in *GetDataBlockRequest, opts ...grpc.CallOption) (DataBlockService_WatchDataBlockClient, error)
+	// 클라이언트 버전 이후로 추가/삭제/변경된 FileBlock 과 행만 반환함.
+	GetDataBlockDelta(ctx context.Context, in *GetDataBlockDeltaRequest, opts ...grpc.CallOption) (*GetDataBlockDeltaResponse, error)
+	// (block_id, 행, 컬럼) 선택을 실제 파일 경로로 바꿈. 파일이 디스크에 아직 있는지 다시 확인함.
+	ResolvePaths(ctx context.Context, in *ResolvePathsRequest, opts ...grpc.CallOption) (*ResolvePathsResponse, error)
+	// user script 템플릿의 자리표시자를 현재 DataBlock 의 파일 경로로 채움.
+	RenderScript(ctx context.Context, in *RenderScriptRequest, opts ...grpc.CallOption) (*RenderScriptResponse, error)
+	// block_id prefix, 컬럼, 셀 패턴, 행 수로 FileBlock 을 찾아서 일치하는 것만 페이지 단위로 반환함.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// 서버 경로와 내부 정보를 뺀 UI 용 DataBlock 을 반환함.
+	GetUIDataBlock(ctx context.Context, in *GetUIDataBlockRequest, opts ...grpc.CallOption) (*GetDataBlockResponse, error)
+}
+
+type dataBlockServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDataBlockServiceClient(cc grpc.ClientConnInterface) DataBlockServiceClient {
+	return &dataBlockServiceClient{cc}
+}
+
+func (c *dataBlockServiceClient) GetDataBlock(ctx context.Context, in *GetDataBlockRequest, opts ...grpc.CallOption) (*GetDataBlockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDataBlockResponse)
+	err := c.cc.Invoke(ctx, DataBlockService_GetDataBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataBlockServiceClient) StreamDataBlock(ctx context.Context, in *GetDataBlockRequest, opts ...grpc.CallOption) (DataBlockService_StreamDataBlockClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DataBlockService_ServiceDesc.Streams[0], DataBlockService_StreamDataBlock_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataBlockServiceStreamDataBlockClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataBlockService_StreamDataBlockClient interface {
+	Recv() (*DataBlockChunk, error)
+	grpc.ClientStream
+}
+
+type dataBlockServiceStreamDataBlockClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataBlockServiceStreamDataBlockClient) Recv() (*DataBlockChunk, error) {
+	m := new(DataBlockChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataBlockServiceClient) WatchDataBlock(ctx context.Context, in *GetDataBlockRequest, opts ...grpc.CallOption) (DataBlockService_WatchDataBlockClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DataBlockService_ServiceDesc.Streams[1], DataBlockService_WatchDataBlock_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataBlockServiceWatchDataBlockClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataBlockService_WatchDataBlockClient interface {
+	Recv() (*GetDataBlockResponse, error)
+	grpc.ClientStream
+}
+
+type dataBlockServiceWatchDataBlockClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataBlockServiceWatchDataBlockClient) Recv() (*GetDataBlockResponse, error) {
+	m := new(GetDataBlockResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataBlockServiceClient) GetDataBlockDelta(ctx context.Context, in *GetDataBlockDeltaRequest, opts ...grpc.CallOption) (*GetDataBlockDeltaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDataBlockDeltaResponse)
+	err := c.cc.Invoke(ctx, DataBlockService_GetDataBlockDelta_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataBlockServiceClient) ResolvePaths(ctx context.Context, in *ResolvePathsRequest, opts ...grpc.CallOption) (*ResolvePathsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolvePathsResponse)
+	err := c.cc.Invoke(ctx, DataBlockService_ResolvePaths_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataBlockServiceClient) RenderScript(ctx context.Context, in *RenderScriptRequest, opts ...grpc.CallOption) (*RenderScriptResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenderScriptResponse)
+	err := c.cc.Invoke(ctx, DataBlockService_RenderScript_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataBlockServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, DataBlockService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataBlockServiceClient) GetUIDataBlock(ctx context.Context, in *GetUIDataBlockRequest, opts ...grpc.CallOption) (*GetDataBlockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDataBlockResponse)
+	err := c.cc.Invoke(ctx, DataBlockService_GetUIDataBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DataBlockServiceServer is the server API for DataBlockService service.
+// All implementations must embed UnimplementedDataBlockServiceServer
+// for forward compatibility.
+//
+// DataBlockService: 클라이언트의 요청에 대해 DataBlockData 를 반환하는 서비스
+type DataBlockServiceServer interface {
+	GetDataBlock(context.Context, *GetDataBlockRequest) (*GetDataBlockResponse, error)
+	// GetDataBlock 과 같지만 header 다음에 FileBlock 을 하나씩 나눠 보냄. 메시지 크기 제한을 넘는 큰 DataBlock 용.
+	StreamDataBlock(*GetDataBlockRequest, DataBlockService_StreamDataBlockServer) error
+	// 현재 DataBlock 을 바로 보내고, 이후 sync 로 DataBlock 이 갱신될 때마다 새 DataBlock 을 push 함.
+	// current_updated_at 이 서버와 같으면 첫 메시지는 no_update 로 보냄.
+	WatchDataBlock(*GetDataBlockRequest, DataBlockService_WatchDataBlockServer) error
+	// 클라이언트 버전 이후로 추가/삭제/변경된 FileBlock 과 행만 반환함.
+	GetDataBlockDelta(context.Context, *GetDataBlockDeltaRequest) (*GetDataBlockDeltaResponse, error)
+	// (block_id, 행, 컬럼) 선택을 실제 파일 경로로 바꿈. 파일이 디스크에 아직 있는지 다시 확인함.
+	ResolvePaths(context.Context, *ResolvePathsRequest) (*ResolvePathsResponse, error)
+	// user script 템플릿의 자리표시자를 현재 DataBlock 의 파일 경로로 채움.
+	RenderScript(context.Context, *RenderScriptRequest) (*RenderScriptResponse, error)
+	// block_id prefix, 컬럼, 셀 패턴, 행 수로 FileBlock 을 찾아서 일치하는 것만 페이지 단위로 반환함.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// 서버 경로와 내부 정보를 뺀 UI 용 DataBlock 을 반환함.
+	GetUIDataBlock(context.Context, *GetUIDataBlockRequest) (*GetDataBlockResponse, error)
+	mustEmbedUnimplementedDataBlockServiceServer()
+}
+
+// UnimplementedDataBlockServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDataBlockServiceServer struct{}
+
+func (UnimplementedDataBlockServiceServer) GetDataBlock(context.Context, *GetDataBlockRequest) (*GetDataBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDataBlock not implemented")
+}
+func (UnimplementedDataBlockServiceServer) StreamDataBlock(*GetDataBlockRequest, DataBlockService_StreamDataBlockServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDataBlock not implemented")
+}
+func (UnimplementedDataBlockServiceServer) WatchDataBlock(*GetDataBlockRequest, DataBlockService_WatchDataBlockServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchDataBlock not implemented")
+}
+func (UnimplementedDataBlockServiceServer) GetDataBlockDelta(context.Context, *GetDataBlockDeltaRequest) (*GetDataBlockDeltaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDataBlockDelta not implemented")
+}
+func (UnimplementedDataBlockServiceServer) ResolvePaths(context.Context, *ResolvePathsRequest) (*ResolvePathsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolvePaths not implemented")
+}
+func (UnimplementedDataBlockServiceServer) RenderScript(context.Context, *RenderScriptRequest) (*RenderScriptResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenderScript not implemented")
+}
+func (UnimplementedDataBlockServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedDataBlockServiceServer) GetUIDataBlock(context.Context, *GetUIDataBlockRequest) (*GetDataBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUIDataBlock not implemented")
+}
+func (UnimplementedDataBlockServiceServer) mustEmbedUnimplementedDataBlockServiceServer() {}
+func (UnimplementedDataBlockServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeDataBlockServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DataBlockServiceServer will
+// result in compilation errors.
+type UnsafeDataBlockServiceServer interface {
+	mustEmbedUnimplementedDataBlockServiceServer()
+}
+
+func RegisterDataBlockServiceServer(s grpc.ServiceRegistrar, srv DataBlockServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDataBlockServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DataBlockService_ServiceDesc, srv)
+}
+
+func _DataBlockService_GetDataBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDataBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataBlockServiceServer).GetDataBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataBlockService_GetDataBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataBlockServiceServer).GetDataBlock(ctx, req.(*GetDataBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataBlockService_StreamDataBlock_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetDataBlockRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataBlockServiceServer).StreamDataBlock(m, &dataBlockServiceStreamDataBlockServer{ServerStream: stream})
+}
+
+type DataBlockService_StreamDataBlockServer interface {
+	Send(*DataBlockChunk) error
+	grpc.ServerStream
+}
+
+type dataBlockServiceStreamDataBlockServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataBlockServiceStreamDataBlockServer) Send(m *DataBlockChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DataBlockService_WatchDataBlock_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetDataBlockRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataBlockServiceServer).WatchDataBlock(m, &dataBlockServiceWatchDataBlockServer{ServerStream: stream})
+}
+
+type DataBlockService_WatchDataBlockServer interface {
+	Send(*GetDataBlockResponse) error
+	grpc.ServerStream
+}
+
+type dataBlockServiceWatchDataBlockServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataBlockServiceWatchDataBlockServer) Send(m *GetDataBlockResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DataBlockService_GetDataBlockDelta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDataBlockDeltaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataBlockServiceServer).GetDataBlockDelta(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataBlockService_GetDataBlockDelta_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataBlockServiceServer).GetDataBlockDelta(ctx, req.(*GetDataBlockDeltaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataBlockService_ResolvePaths_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolvePathsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataBlockServiceServer).ResolvePaths(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataBlockService_ResolvePaths_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataBlockServiceServer).ResolvePaths(ctx, req.(*ResolvePathsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataBlockService_RenderScript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenderScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataBlockServiceServer).RenderScript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataBlockService_RenderScript_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataBlockServiceServer).RenderScript(ctx, req.(*RenderScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataBlockService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataBlockServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataBlockService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataBlockServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataBlockService_GetUIDataBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUIDataBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataBlockServiceServer).GetUIDataBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataBlockService_GetUIDataBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataBlockServiceServer).GetUIDataBlock(ctx, req.(*GetUIDataBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DataBlockService_ServiceDesc is the grpc.ServiceDesc for DataBlockService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DataBlockService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.DataBlockService",
+	HandlerType: (*DataBlockServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetDataBlock",
+			Handler:    _DataBlockService_GetDataBlock_Handler,
+		},
+		{
+			MethodName: "GetDataBlockDelta",
+			Handler:    _DataBlockService_GetDataBlockDelta_Handler,
+		},
+		{
+			MethodName: "ResolvePaths",
+			Handler:    _DataBlockService_ResolvePaths_Handler,
+		},
+		{
+			MethodName: "RenderScript",
+			Handler:    _DataBlockService_RenderScript_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _DataBlockService_Search_Handler,
+		},
+		{
+			MethodName: "GetUIDataBlock",
+			Handler:    _DataBlockService_GetUIDataBlock_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDataBlock",
+			Handler:       _DataBlockService_StreamDataBlock_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchDataBlock",
+			Handler:       _DataBlockService_WatchDataBlock_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "apis.proto",
+}
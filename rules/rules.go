@@ -87,6 +87,22 @@ func splitFileName(fileName string, delimiters []string) []string {
 	return strings.Fields(fileName)
 }
 
+// joinParts parts 중 matchParts 위치의 값들을 "_" 로 이어 붙임. 범위를 벗어난 위치는 무시함.
+func joinParts(parts []string, matchParts []int) string {
+	var keyParts []string
+	for _, idx := range matchParts {
+		if idx < len(parts) {
+			keyParts = append(keyParts, parts[idx])
+		}
+	}
+	return strings.Join(keyParts, "_")
+}
+
+// RowKey 파일명이 속하는 행의 키. GroupFiles 가 같은 행으로 묶는 파일들은 모두 같은 RowKey 를 가짐.
+func RowKey(fileName string, ruleSet RuleSet) string {
+	return joinParts(splitFileName(fileName, ruleSet.Delimiter), ruleSet.RowRules.MatchParts)
+}
+
 // FilesToMap 파일명 리스트 → (RowIdx → (ColumnKey → 파일명)) 구조 생성
 
 // GroupFiles 이름으로 바꿀 예정 파일 목록을 RuleSet 에 따라 행·열 구조로 묶어서 반환
@@ -99,13 +115,7 @@ func GroupFiles(fileNames []string, ruleSet RuleSet) (map[int]map[string]string,
 		parts := splitFileName(fn, ruleSet.Delimiter)
 
 		// 1) Row 키 생성
-		var rowKeyParts []string
-		for _, idx := range ruleSet.RowRules.MatchParts {
-			if idx < len(parts) {
-				rowKeyParts = append(rowKeyParts, parts[idx])
-			}
-		}
-		rowKey := strings.Join(rowKeyParts, "_")
+		rowKey := joinParts(parts, ruleSet.RowRules.MatchParts)
 
 		if _, found := rowMap[rowKey]; !found {
 			rowMap[rowKey] = nextRowIdx
@@ -115,13 +125,7 @@ func GroupFiles(fileNames []string, ruleSet RuleSet) (map[int]map[string]string,
 		rowIdx := rowMap[rowKey]
 
 		// 2) Column 키 생성
-		var colKeyParts []string
-		for _, idx := range ruleSet.ColumnRules.MatchParts {
-			if idx < len(parts) {
-				colKeyParts = append(colKeyParts, parts[idx])
-			}
-		}
-		colKey := strings.Join(colKeyParts, "_")
+		colKey := joinParts(parts, ruleSet.ColumnRules.MatchParts)
 
 		// 3) 결과에 추가
 		result[rowIdx][colKey] = fn
@@ -139,7 +143,15 @@ func FilterGroups(resultMap map[int]map[string]string, expectedColCount int) (ma
 	invalid := make([]map[string]string, 0)
 	nextRowIdx := 0
 
-	for _, row := range resultMap {
+	// 행 번호가 sync 마다 바뀌지 않도록 GroupFiles 가 매긴 순서대로 순회함.
+	rowIdxs := make([]int, 0, len(resultMap))
+	for idx := range resultMap {
+		rowIdxs = append(rowIdxs, idx)
+	}
+	sort.Ints(rowIdxs)
+
+	for _, idx := range rowIdxs {
+		row := resultMap[idx]
 		if len(row) == expectedColCount {
 			valid[nextRowIdx] = row
 			nextRowIdx++
@@ -152,6 +164,9 @@ func FilterGroups(resultMap map[int]map[string]string, expectedColCount int) (ma
 
 // WriteInvalidFiles invalid 행의 모든 파일명을 <outputDir>/invalid_files_YYYYMMDDhhmmss.txt 로 기록
 
+// InvalidFilesPattern SaveInvalidFiles 가 쓰는 파일 이름의 glob 패턴. 파일 제외 목록에 넣어서 사용함.
+const InvalidFilesPattern = "invalid_files_*.txt"
+
 // SaveInvalidFiles invalid 행의 모든 파일명을 <outputDir>/invalid_files_YYYYMMDDhhmmss.txt 로 기록
 func SaveInvalidFiles(invalidRows []map[string]string, outputDir string) error {
 	if len(invalidRows) == 0 {
@@ -227,11 +242,15 @@ func ListFilesExclude(dirPath string, exclusions []string) ([]string, error) {
 
 	isExcluded := func(name string) bool {
 		for _, ex := range exclusions {
-			if strings.HasPrefix(ex, "*.") {
+			if strings.HasPrefix(ex, "*.") && !strings.ContainsAny(ex[2:], "*?[") {
 				ext := ex[1:] // "*.pb" -> ".pb"
 				if strings.Contains(name, ext) {
 					return true
 				}
+			} else if strings.ContainsAny(ex, "*?[") {
+				if ok, _ := filepath.Match(ex, name); ok {
+					return true
+				}
 			} else {
 				if name == ex {
 					return true
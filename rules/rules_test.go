@@ -41,6 +41,24 @@ func TestGroupFilesAndFilterGroups(t *testing.T) {
 	}
 }
 
+func TestFilterGroups_StableRowOrder(t *testing.T) {
+	resultMap := map[int]map[string]string{
+		0: {"R1": "a_R1", "R2": "a_R2"},
+		1: {"R1": "b_R1"},
+		2: {"R1": "c_R1", "R2": "c_R2"},
+		3: {"R1": "d_R1", "R2": "d_R2"},
+	}
+	for i := 0; i < 20; i++ {
+		valid, invalid := FilterGroups(resultMap, 2)
+		if valid[0]["R1"] != "a_R1" || valid[1]["R1"] != "c_R1" || valid[2]["R1"] != "d_R1" {
+			t.Fatalf("row order changed: %v", valid)
+		}
+		if len(invalid) != 1 {
+			t.Fatalf("expected 1 invalid row, got %d", len(invalid))
+		}
+	}
+}
+
 func TestIsValidRuleSet(t *testing.T) {
 	rs := RuleSet{
 		RowRules:    RowRules{MatchParts: []int{0, 1}},
@@ -141,3 +159,28 @@ func TestLoadRuleSetFromFile(t *testing.T) {
 		t.Errorf("loaded data mismatch: %+v", loaded)
 	}
 }
+
+func TestRowKey(t *testing.T) {
+	rs := RuleSet{
+		Delimiter:   []string{"_", ".fastq"},
+		RowRules:    RowRules{MatchParts: []int{0, 1}},
+		ColumnRules: ColumnRules{MatchParts: []int{2}},
+	}
+	files := []string{"s1_L001_R1.fastq", "s1_L001_R2.fastq", "s2_L001_R1.fastq"}
+	grouped, err := GroupFiles(files, rs)
+	if err != nil {
+		t.Fatalf("GroupFiles error: %v", err)
+	}
+	for _, row := range grouped {
+		var key string
+		for _, fn := range row {
+			if key != "" && RowKey(fn, rs) != key {
+				t.Errorf("files in one row have different row keys: %v", row)
+			}
+			key = RowKey(fn, rs)
+		}
+	}
+	if got := RowKey("s1_L001_R2.fastq", rs); got != "s1_L001" {
+		t.Errorf("expected row key s1_L001, got %s", got)
+	}
+}
@@ -0,0 +1,200 @@
+package search
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPageSize page size 를 지정하지 않았을 때 한 번에 돌려주는 FileBlock 수.
+	DefaultPageSize = 50
+	// MaxPageSize 한 번에 돌려줄 수 있는 최대 FileBlock 수.
+	MaxPageSize = 1000
+)
+
+var (
+	// ErrInvalidQuery 패턴이나 범위가 잘못된 쿼리.
+	ErrInvalidQuery = errors.New("invalid search query")
+	// ErrStalePageToken 페이지 토큰을 만든 뒤 DataBlock 이 갱신되었음. 처음부터 다시 검색해야 함.
+	ErrStalePageToken = errors.New("page token is stale; datablock has been updated")
+)
+
+// Query DataBlock 검색 조건. 비어 있는 조건은 무시하고, 설정된 조건은 모두 만족해야 함.
+type Query struct {
+	BlockIDPrefix string   // block_id 가 이 문자열로 시작하는 FileBlock
+	Headers       []string // 이 컬럼들을 모두 가진 FileBlock
+	CellGlob      string   // 셀 값(파일 이름)에 대한 glob 패턴 (filepath.Match 문법)
+	CellRegex     string   // 셀 값(파일 이름)에 대한 정규식
+	Column        string   // 설정하면 셀 조건을 이 컬럼에만 적용함
+	MinRows       int      // 행 수가 이 값 이상인 FileBlock (0 이면 제한 없음)
+	MaxRows       int      // 행 수가 이 값 이하인 FileBlock (0 이면 제한 없음)
+	PageSize      int      // 0 이면 DefaultPageSize
+	PageToken     string   // 이전 Result.NextPageToken
+}
+
+// Result 한 페이지의 검색 결과. 셀 조건이 있으면 Blocks 의 각 FileBlock 에는 일치하는 행만 들어 있음.
+type Result struct {
+	Blocks        []*pb.FileBlock
+	TotalBlocks   int                    // 조건에 맞는 전체 FileBlock 수
+	NextPageToken string                 // 다음 페이지가 없으면 빈 문자열
+	UpdatedAt     *timestamppb.Timestamp // 검색한 DataBlock 의 updated_at
+}
+
+// Search dataBlock 에서 q 에 맞는 FileBlock 과 행을 찾음. dataBlock 은 바뀌지 않음.
+func Search(dataBlock *pb.DataBlock, q Query) (*Result, error) {
+	m, err := newMatcher(q)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := q.PageSize
+	switch {
+	case pageSize < 0:
+		return nil, fmt.Errorf("%w: negative page size %d", ErrInvalidQuery, pageSize)
+	case pageSize == 0:
+		pageSize = DefaultPageSize
+	case pageSize > MaxPageSize:
+		pageSize = MaxPageSize
+	}
+	version := dataBlock.GetUpdatedAt().AsTime().UnixNano()
+	offset, err := decodePageToken(q.PageToken, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*pb.FileBlock
+	for _, fb := range dataBlock.GetBlocks() {
+		if out := m.matchBlock(fb); out != nil {
+			matched = append(matched, out)
+		}
+	}
+
+	result := &Result{TotalBlocks: len(matched), UpdatedAt: dataBlock.GetUpdatedAt()}
+	if offset >= len(matched) {
+		return result, nil
+	}
+	end := min(offset+pageSize, len(matched))
+	result.Blocks = matched[offset:end]
+	if end < len(matched) {
+		result.NextPageToken = encodePageToken(end, version)
+	}
+	return result, nil
+}
+
+type matcher struct {
+	q     Query
+	regex *regexp.Regexp
+}
+
+func newMatcher(q Query) (*matcher, error) {
+	m := &matcher{q: q}
+	if q.CellGlob != "" {
+		if _, err := filepath.Match(q.CellGlob, ""); err != nil {
+			return nil, fmt.Errorf("%w: bad glob %q: %v", ErrInvalidQuery, q.CellGlob, err)
+		}
+	}
+	if q.CellRegex != "" {
+		re, err := regexp.Compile(q.CellRegex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad regex %q: %v", ErrInvalidQuery, q.CellRegex, err)
+		}
+		m.regex = re
+	}
+	if q.MinRows < 0 || q.MaxRows < 0 || (q.MaxRows > 0 && q.MinRows > q.MaxRows) {
+		return nil, fmt.Errorf("%w: bad row range [%d, %d]", ErrInvalidQuery, q.MinRows, q.MaxRows)
+	}
+	return m, nil
+}
+
+func (m *matcher) hasCellFilter() bool {
+	return m.q.CellGlob != "" || m.regex != nil
+}
+
+// matchBlock fb 가 조건에 맞으면 결과로 돌려줄 FileBlock 을, 아니면 nil 을 반환함.
+func (m *matcher) matchBlock(fb *pb.FileBlock) *pb.FileBlock {
+	if !strings.HasPrefix(fb.GetBlockId(), m.q.BlockIDPrefix) {
+		return nil
+	}
+	for _, h := range m.q.Headers {
+		if !slices.Contains(fb.GetColumnHeaders(), h) {
+			return nil
+		}
+	}
+	if m.q.Column != "" && !slices.Contains(fb.GetColumnHeaders(), m.q.Column) {
+		return nil
+	}
+	n := len(fb.GetRows())
+	if n < m.q.MinRows || (m.q.MaxRows > 0 && n > m.q.MaxRows) {
+		return nil
+	}
+	if !m.hasCellFilter() {
+		return fb
+	}
+
+	var rows []*pb.Row
+	for _, r := range fb.GetRows() {
+		if m.matchRow(r) {
+			rows = append(rows, r)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return &pb.FileBlock{BlockId: fb.GetBlockId(), ColumnHeaders: fb.GetColumnHeaders(), Rows: rows}
+}
+
+// matchRow 셀 하나라도(Column 이 있으면 그 컬럼 셀이) glob 과 regex 를 모두 만족하면 true.
+func (m *matcher) matchRow(r *pb.Row) bool {
+	if m.q.Column != "" {
+		v, ok := r.GetCells()[m.q.Column]
+		return ok && m.matchCell(v)
+	}
+	for _, v := range r.GetCells() {
+		if m.matchCell(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *matcher) matchCell(v string) bool {
+	if m.q.CellGlob != "" {
+		if ok, _ := filepath.Match(m.q.CellGlob, v); !ok {
+			return false
+		}
+	}
+	return m.regex == nil || m.regex.MatchString(v)
+}
+
+// 페이지 토큰은 "<offset>:<DataBlock updated_at(ns)>" 를 base64url 로 감싼 것.
+// DataBlock 이 바뀌면 offset 이 다른 결과를 가리키므로 거부함.
+func encodePageToken(offset int, version int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", offset, version)))
+}
+
+func decodePageToken(token string, version int64) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed page token", ErrInvalidQuery)
+	}
+	offsetStr, versionStr, found := strings.Cut(string(raw), ":")
+	offset, oErr := strconv.Atoi(offsetStr)
+	tokenVersion, vErr := strconv.ParseInt(versionStr, 10, 64)
+	if !found || oErr != nil || vErr != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: malformed page token", ErrInvalidQuery)
+	}
+	if tokenVersion != version {
+		return 0, ErrStalePageToken
+	}
+	return offset, nil
+}
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"errors"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testing"
+	"time"
+)
+
+func sampleDataBlock() *pb.DataBlock {
+	row := func(n int32, r1, r2 string) *pb.Row {
+		return &pb.Row{RowNumber: n, Cells: map[string]string{"R1": r1, "R2": r2}}
+	}
+	return &pb.DataBlock{
+		UpdatedAt: timestamppb.New(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)),
+		Blocks: []*pb.FileBlock{
+			{BlockId: "/data/run1/a", ColumnHeaders: []string{"R1", "R2"}, Rows: []*pb.Row{
+				row(0, "s1_R1.fastq.gz", "s1_R2.fastq.gz"),
+				row(1, "s2_R1.fastq.gz", "s2_R2.fastq.gz"),
+			}},
+			{BlockId: "/data/run1/b", ColumnHeaders: []string{"R1", "R2"}, Rows: []*pb.Row{
+				row(0, "t1_R1.bam", "t1_R2.bam"),
+			}},
+			{BlockId: "/data/run2/c", ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{
+				{RowNumber: 0, Cells: map[string]string{"R1": "s9_R1.fastq.gz"}},
+			}},
+		},
+	}
+}
+
+func blockIDs(r *Result) []string {
+	var ids []string
+	for _, fb := range r.Blocks {
+		ids = append(ids, fb.GetBlockId())
+	}
+	return ids
+}
+
+func TestSearchFilters(t *testing.T) {
+	db := sampleDataBlock()
+	tests := []struct {
+		name string
+		q    Query
+		want []string
+		rows []int // 각 block 의 행 수
+	}{
+		{"all", Query{}, []string{"/data/run1/a", "/data/run1/b", "/data/run2/c"}, []int{2, 1, 1}},
+		{"prefix", Query{BlockIDPrefix: "/data/run1/"}, []string{"/data/run1/a", "/data/run1/b"}, []int{2, 1}},
+		{"headers", Query{Headers: []string{"R2"}}, []string{"/data/run1/a", "/data/run1/b"}, []int{2, 1}},
+		{"glob", Query{CellGlob: "s1_*"}, []string{"/data/run1/a"}, []int{1}},
+		{"regex", Query{CellRegex: `\.bam$`}, []string{"/data/run1/b"}, []int{1}},
+		{"glob on column", Query{CellGlob: "*_R2*", Column: "R2"}, []string{"/data/run1/a", "/data/run1/b"}, []int{2, 1}},
+		{"glob and regex", Query{CellGlob: "*.fastq.gz", CellRegex: "^s9"}, []string{"/data/run2/c"}, []int{1}},
+		{"min rows", Query{MinRows: 2}, []string{"/data/run1/a"}, []int{2}},
+		{"max rows", Query{MaxRows: 1}, []string{"/data/run1/b", "/data/run2/c"}, []int{1, 1}},
+		{"no match", Query{CellGlob: "*.vcf"}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Search(db, tt.q)
+			if err != nil {
+				t.Fatalf("Search error: %v", err)
+			}
+			got := blockIDs(r)
+			if len(got) != len(tt.want) || r.TotalBlocks != len(tt.want) {
+				t.Fatalf("expected %v, got %v (total %d)", tt.want, got, r.TotalBlocks)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] || len(r.Blocks[i].GetRows()) != tt.rows[i] {
+					t.Errorf("block %d: expected %s with %d rows, got %s with %d rows",
+						i, tt.want[i], tt.rows[i], got[i], len(r.Blocks[i].GetRows()))
+				}
+			}
+		})
+	}
+	// 검색이 원본 DataBlock 을 바꾸면 안 됨.
+	if len(db.Blocks[0].Rows) != 2 {
+		t.Errorf("search modified the source datablock")
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	db := sampleDataBlock()
+	first, err := Search(db, Query{PageSize: 2})
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(first.Blocks) != 2 || first.TotalBlocks != 3 || first.NextPageToken == "" {
+		t.Fatalf("unexpected first page: %v total=%d token=%q", blockIDs(first), first.TotalBlocks, first.NextPageToken)
+	}
+	second, err := Search(db, Query{PageSize: 2, PageToken: first.NextPageToken})
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if ids := blockIDs(second); len(ids) != 1 || ids[0] != "/data/run2/c" || second.NextPageToken != "" {
+		t.Errorf("unexpected second page: %v token=%q", ids, second.NextPageToken)
+	}
+
+	// DataBlock 이 갱신되면 이전 토큰은 거부됨.
+	db.UpdatedAt = timestamppb.New(db.UpdatedAt.AsTime().Add(time.Minute))
+	if _, err := Search(db, Query{PageSize: 2, PageToken: first.NextPageToken}); !errors.Is(err, ErrStalePageToken) {
+		t.Errorf("expected ErrStalePageToken, got %v", err)
+	}
+}
+
+func TestSearchInvalidQuery(t *testing.T) {
+	for _, q := range []Query{
+		{CellGlob: "["},
+		{CellRegex: "("},
+		{MinRows: 3, MaxRows: 1},
+		{PageSize: -1},
+		{PageToken: "not-a-token"},
+	} {
+		if _, err := Search(sampleDataBlock(), q); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%+v: expected ErrInvalidQuery, got %v", q, err)
+		}
+	}
+}
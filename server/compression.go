@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+	"slices"
+)
+
+// gzip 패키지를 import 하면 compressor 가 등록되어, server.compression 과 관계없이 gzip 으로 압축된 요청은 항상 받을 수 있음.
+// 응답 압축은 server.compression 이 설정된 경우에만 클라이언트가 grpc-accept-encoding 으로 지원한다고 알린 방식으로 함.
+
+// setSendCompressor 클라이언트가 name 을 지원하면 응답을 name 으로 압축하도록 설정함.
+func setSendCompressor(ctx context.Context, name string) {
+	supported, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil || !slices.Contains(supported, name) {
+		return
+	}
+	if err := grpc.SetSendCompressor(ctx, name); err != nil {
+		logger.Debugf("failed to set %s compressor: %v", name, err)
+	}
+}
+
+// compressionInterceptors server.compression 에 따라 응답 압축을 협상하는 인터셉터. "none" 이나 빈 값이면 nil.
+func compressionInterceptors(compression string) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	if compression != gzip.Name {
+		return nil, nil
+	}
+	unary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		setSendCompressor(ctx, compression)
+		return handler(ctx, req)
+	}
+	stream := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		setSendCompressor(ss.Context(), compression)
+		return handler(srv, ss)
+	}
+	return unary, stream
+}
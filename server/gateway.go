@@ -0,0 +1,299 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/seoyhaein/tori/auth"
+	"github.com/seoyhaein/tori/config"
+	"github.com/seoyhaein/tori/metrics"
+	pb "github.com/seoyhaein/tori/protos"
+	"github.com/seoyhaein/tori/service"
+	"github.com/seoyhaein/tori/tlsutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gateway gRPC 를 쓸 수 없는 스크립트나 대시보드를 위한 HTTP/JSON 엔드포인트.
+// gRPC 핸들러를 그대로 호출하므로 block ID 변환, 에러, 인증/인가가 gRPC API 와 같음.
+//
+//	GET  /v1/datablock  현재 DataBlock. ETag 는 updated_at 이고, If-None-Match 가 같으면 304.
+//	POST /v1/sync       SyncFoldersInfoRequest (본문 생략 가능, ?force=true 도 가능)
+//	POST /v1/search     SearchRequest
+//
+// /metrics 는 인증 없이 수집되므로 게이트웨이에는 두지 않고 server.metricsAddress 에서만 노출함.
+type gateway struct {
+	dataBlocks pb.DataBlockServiceServer
+	dbApis     pb.DBApisServiceServer
+	authn      auth.Authenticator
+	policy     *auth.Policy
+	maxBody    int
+}
+
+// NewHTTPHandler core 를 사용하는 HTTP/JSON 게이트웨이 핸들러를 만듦. 인증 설정은 gRPC 서버와 같음.
+func NewHTTPHandler(core *service.DataBlockCliService) (http.Handler, error) {
+	authn, policy, err := auth.New(core.Config().Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up authentication: %w", err)
+	}
+	g := &gateway{
+		dataBlocks: service.NewDataBlockServer(core),
+		dbApis:     service.NewDBApisServer(core),
+		authn:      authn,
+		policy:     policy,
+		maxBody:    core.Config().Server.WithDefaults().MaxRecvMsgSize,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/datablock", g.handle(pb.DataBlockService_GetDataBlock_FullMethodName, g.getDataBlock))
+	mux.HandleFunc("POST /v1/sync", g.handle(pb.DBApisService_SyncFoldersInfo_FullMethodName, g.sync))
+	mux.HandleFunc("POST /v1/search", g.handle(pb.DataBlockService_Search_FullMethodName, g.search))
+	return mux, nil
+}
+
+type gatewayHandler func(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+// handle 로깅과 인증/인가를 처리한 뒤 h 를 호출하고, 에러는 gRPC status 에 맞는 HTTP 상태 코드로 바꿈.
+func (g *gateway) handle(fullMethod string, h gatewayHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger.Infof("Received HTTP request %s %s (%s)", r.Method, r.URL.Path, fullMethod)
+		ctx := r.Context()
+		if g.authn != nil {
+			var err error
+			if ctx, err = auth.CheckHTTP(r, g.authn, g.policy, fullMethod); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+		if err := h(ctx, w, r); err != nil {
+			logger.Infof("Method %s error: %v", fullMethod, err)
+			writeError(w, err)
+		}
+	}
+}
+
+func (g *gateway) getDataBlock(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	resp, err := g.dataBlocks.GetDataBlock(ctx, &pb.GetDataBlockRequest{})
+	if err != nil {
+		return err
+	}
+	etag := dataBlockETag(resp.GetData())
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	return writeJSON(w, resp.GetData())
+}
+
+func (g *gateway) sync(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	req := &pb.SyncFoldersInfoRequest{}
+	if err := readJSON(r, req, g.maxBody); err != nil {
+		return err
+	}
+	if v := r.URL.Query().Get("force"); v != "" {
+		force, err := strconv.ParseBool(v)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid force parameter %q", v)
+		}
+		req.Force = req.Force || force
+	}
+	resp, err := g.dbApis.SyncFoldersInfo(ctx, req)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, resp)
+}
+
+func (g *gateway) search(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	req := &pb.SearchRequest{}
+	if err := readJSON(r, req, g.maxBody); err != nil {
+		return err
+	}
+	resp, err := g.dataBlocks.Search(ctx, req)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, resp)
+}
+
+// dataBlockETag generation 과 content_hash 를 strong ETag 로 씀. 둘 다 없는 이전 datablock.pb 는 updated_at 을 씀.
+func dataBlockETag(dataBlock *pb.DataBlock) string {
+	if hash := dataBlock.GetContentHash(); hash != "" {
+		return fmt.Sprintf(`"%d-%s"`, dataBlock.GetGeneration(), strings.TrimPrefix(hash, "sha256:"))
+	}
+	ts := dataBlock.GetUpdatedAt()
+	return fmt.Sprintf(`"%d.%09d"`, ts.GetSeconds(), ts.GetNanos())
+}
+
+// etagMatches If-None-Match 헤더 값(쉼표로 구분된 목록, "*", W/ 접두사 허용)에 etag 가 있으면 true.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// readJSON 요청 본문을 protojson 으로 읽음. 본문이 비어 있으면 m 을 그대로 둠. 최대 크기는 gRPC 의 최대 수신 크기와 같음.
+func readJSON(r *http.Request, m proto.Message, maxBody int) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(maxBody)+1))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to read request body: %v", err)
+	}
+	if len(body) > maxBody {
+		return status.Errorf(codes.ResourceExhausted, "request body exceeds %d bytes", maxBody)
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil
+	}
+	if err := protojson.Unmarshal(body, m); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, m proto.Message) error {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		logger.Infof("failed to write HTTP response: %v", err)
+	}
+	return nil
+}
+
+// writeError err 의 gRPC status 를 HTTP 상태 코드와 {"code": ..., "message": ...} 본문으로 바꿈.
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	if errors.Is(err, context.Canceled) {
+		st = status.New(codes.Canceled, err.Error())
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		st = status.New(codes.DeadlineExceeded, err.Error())
+	}
+	b, mErr := protojson.Marshal(st.Proto())
+	if mErr != nil {
+		http.Error(w, st.Message(), httpStatusFromCode(st.Code()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatusFromCode(st.Code()))
+	if _, wErr := w.Write(b); wErr != nil {
+		logger.Infof("failed to write HTTP error response: %v", wErr)
+	}
+}
+
+// httpStatusFromCode gRPC 상태 코드를 HTTP 상태 코드로 바꿈. grpc-gateway 와 같은 대응을 사용함.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ServeHTTP 설정의 server.httpAddress 에서 HTTP/JSON 게이트웨이를 실행함.
+func ServeHTTP(ctx context.Context, core *service.DataBlockCliService) error {
+	address := core.Config().Server.HTTPAddress
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	return ServeGateway(ctx, lis, core)
+}
+
+// ServeGateway lis 에서 HTTP/JSON 게이트웨이를 실행하고, ctx 가 취소되면 처리 중인 요청을 마친 뒤 종료함.
+func ServeGateway(ctx context.Context, lis net.Listener, core *service.DataBlockCliService) error {
+	handler, err := NewHTTPHandler(core)
+	if err != nil {
+		return err
+	}
+	return serveHTTP(ctx, lis, handler, "HTTP gateway", core.Config().Server.WithDefaults())
+}
+
+// ServeMetrics cfg.metricsAddress 에서 /metrics 만 노출함. 인증이 없으므로 Prometheus 만 닿는 주소(예: 127.0.0.1)에 둠.
+func ServeMetrics(ctx context.Context, cfg config.ServerConfig) error {
+	address := cfg.MetricsAddress
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	return serveHTTP(ctx, lis, mux, "metrics server", cfg.WithDefaults())
+}
+
+// ServeStatus address 에서 handler 를 실행함. watch 명령의 /status 용이고, TLS 와 종료 대기 시간은 cfg 를 따름.
+func ServeStatus(ctx context.Context, address string, handler http.Handler, cfg config.ServerConfig) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	return serveHTTP(ctx, lis, handler, "status server", cfg.WithDefaults())
+}
+
+// serveHTTP lis 에서 handler 를 실행하고, ctx 가 취소되면 처리 중인 요청을 최대 shutdownGracePeriod 동안 기다린 뒤 종료함.
+// TLS 가 설정되어 있으면 gRPC 와 같은 인증서로 HTTPS 를 사용함.
+func serveHTTP(ctx context.Context, lis net.Listener, handler http.Handler, name string, cfg config.ServerConfig) error {
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	if cfg.TLS.Enabled() {
+		tlsCfg, err := tlsutil.ServerConfig(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to set up TLS: %w", err)
+		}
+		lis = tls.NewListener(lis, tlsCfg)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		logger.Infof("%s shutting down: %v", name, context.Cause(ctx))
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.ShutdownGracePeriod.D())
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Warnf("%s shutdown: %v", name, err)
+		}
+	}()
+
+	logger.Infof("%s started, address: %s", name, lis.Addr())
+	if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s returned with error: %w", name, err)
+	}
+	<-stopped
+	logger.Infof("%s is shut down", name)
+	return nil
+}
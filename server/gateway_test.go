@@ -0,0 +1,176 @@
+package server
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/seoyhaein/tori/config"
+	dbUtils "github.com/seoyhaein/tori/db"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// startGateway rootDir 를 사용하는 HTTP 게이트웨이를 띄우고 base URL 을 반환함.
+func startGateway(t *testing.T, cfg *config.Config) string {
+	t.Helper()
+	db, err := dbUtils.ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := dbUtils.InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase failed: %v", err)
+	}
+	handler, err := NewHTTPHandler(newCore(t, db, cfg))
+	if err != nil {
+		t.Fatalf("NewHTTPHandler failed: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func doHTTP(t *testing.T, method, url, body string, header map[string]string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return resp, string(b)
+}
+
+func TestHTTPGateway(t *testing.T) {
+	rootDir, folder := setupRunFolder(t)
+	base := startGateway(t, &config.Config{RootDir: rootDir, FilesExclusions: []string{"*.json", "invalid_files", "*.csv", "*.pb"}})
+
+	resp, body := doHTTP(t, http.MethodGet, base+"/v1/datablock", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	dataBlock := &pb.DataBlock{}
+	if err := protojson.Unmarshal([]byte(body), dataBlock); err != nil {
+		t.Fatalf("invalid datablock JSON: %v", err)
+	}
+	if len(dataBlock.GetBlocks()) != 1 || dataBlock.GetBlocks()[0].GetBlockId() != folder {
+		t.Fatalf("unexpected datablock: %v", dataBlock)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header")
+	}
+
+	// 같은 버전이면 304.
+	resp, body = doHTTP(t, http.MethodGet, base+"/v1/datablock", "", map[string]string{"If-None-Match": `"0.000000000", ` + etag})
+	if resp.StatusCode != http.StatusNotModified || body != "" {
+		t.Errorf("expected 304 with empty body, got %d: %s", resp.StatusCode, body)
+	}
+
+	// 검색은 gRPC Search 와 같은 결과.
+	resp, body = doHTTP(t, http.MethodPost, base+"/v1/search", `{"blockIdPrefix":"run1","cellGlob":"s2_*","column":"R1"}`, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	searchResp := &pb.SearchResponse{}
+	if err := protojson.Unmarshal([]byte(body), searchResp); err != nil {
+		t.Fatalf("invalid search JSON: %v", err)
+	}
+	if searchResp.GetTotalBlocks() != 1 || len(searchResp.GetBlocks()[0].GetRows()) != 1 {
+		t.Errorf("unexpected search result: %v", searchResp)
+	}
+	resp, body = doHTTP(t, http.MethodPost, base+"/v1/search", `{"cellRegex":"("}`, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid query, got %d: %s", resp.StatusCode, body)
+	}
+	resp, _ = doHTTP(t, http.MethodPost, base+"/v1/search", `{not json`, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for malformed body, got %d", resp.StatusCode)
+	}
+
+	// sync 후에는 ETag 가 바뀌고 이전 ETag 로는 304 가 아님.
+	resp, body = doHTTP(t, http.MethodPost, base+"/v1/sync?force=true", "", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"updated":true`) {
+		t.Fatalf("expected updated sync, got %d: %s", resp.StatusCode, body)
+	}
+	resp, body = doHTTP(t, http.MethodGet, base+"/v1/datablock", "", map[string]string{"If-None-Match": etag})
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+		t.Errorf("expected new datablock after sync, got %d (etag %s): %s", resp.StatusCode, resp.Header.Get("ETag"), body)
+	}
+
+	// sync 와 DataBlock 관련 metric 이 기록되고, 폴더 경로는 label 로 나가지 않음.
+	body = scrapeMetrics(t)
+	for _, want := range []string{
+		`tori_sync_duration_seconds_count{result="updated"}`,
+		`tori_sync_phase_duration_seconds_count{phase="datablock_write"}`,
+		`tori_sync_last_scanned{kind="file"} 3`,
+		"tori_invalid_rows 1", // s2 는 R2 가 없어서 invalid
+		"tori_datablock_blocks 1",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics is missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, folder) {
+		t.Errorf("/metrics exposes the folder path %s", folder)
+	}
+	// /metrics 는 인증 없이 열리므로 게이트웨이에서는 제공하지 않음.
+	resp, _ = doHTTP(t, http.MethodGet, base+"/metrics", "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for /metrics on the gateway, got %d", resp.StatusCode)
+	}
+
+	resp, _ = doHTTP(t, http.MethodDelete, base+"/v1/datablock", "", nil)
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPGatewayAuth(t *testing.T) {
+	rootDir, _ := setupRunFolder(t)
+	tokenFile := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(tokenFile, []byte("reader-token alice reader\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	base := startGateway(t, &config.Config{RootDir: rootDir, Auth: config.AuthConfig{TokenFile: tokenFile, Roles: config.DefaultRoles()}})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"anonymous", http.MethodGet, "/v1/datablock", "", http.StatusUnauthorized},
+		{"bad token", http.MethodGet, "/v1/datablock", "nope", http.StatusUnauthorized},
+		{"reader get", http.MethodGet, "/v1/datablock", "reader-token", http.StatusOK},
+		{"reader sync", http.MethodPost, "/v1/sync", "reader-token", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := map[string]string{}
+			if tt.token != "" {
+				header["Authorization"] = "Bearer " + tt.token
+			}
+			resp, body := doHTTP(t, tt.method, base+tt.path, "", header)
+			if resp.StatusCode != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, resp.StatusCode, body)
+			}
+		})
+	}
+}
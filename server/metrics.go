@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/seoyhaein/tori/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+var (
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tori_grpc_requests_total",
+		Help: "gRPC requests by method and status code.",
+	}, []string{"method", "code"})
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tori_grpc_request_duration_seconds",
+		Help:    "gRPC request latency by method. Streams are measured until they end.",
+		Buckets: metrics.DurationBuckets,
+	}, []string{"method"})
+)
+
+func observeRPC(fullMethod string, start time.Time, err error) {
+	grpcRequests.WithLabelValues(fullMethod, status.Code(err).String()).Inc()
+	grpcDuration.WithLabelValues(fullMethod).Observe(time.Since(start).Seconds())
+}
+
+// metricsUnaryInterceptor 단항 RPC 의 요청 수와 처리 시간을 기록함. 인증 실패도 집계되도록 가장 바깥에 둠.
+func metricsUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeRPC(info.FullMethod, start, err)
+	return resp, err
+}
+
+// metricsStreamInterceptor 스트림 RPC 의 요청 수와 스트림이 끝날 때까지의 시간을 기록함.
+func metricsStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observeRPC(info.FullMethod, start, err)
+	return err
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/seoyhaein/tori/auth"
+	"github.com/seoyhaein/tori/config"
+	globallog "github.com/seoyhaein/tori/log"
+	pb "github.com/seoyhaein/tori/protos"
+	"github.com/seoyhaein/tori/service"
+	"github.com/seoyhaein/tori/tlsutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"net"
+	"os"
+	"time"
+)
+
+var (
+	// DataBlockPollInterval 다른 프로세스에서 datablock.pb 를 갱신했는지 확인하는 주기.
+	DataBlockPollInterval = 2 * time.Second
+	logger                = globallog.Log
+)
+
+// gRPC 요청을 받을 때마다 요청 메서드와 에러 정보를 로깅함.
+func loggingInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	logger.Infof("Received request for %s", info.FullMethod)
+	resp, err := handler(ctx, req)
+	if err != nil {
+		logger.Infof("Method %s error: %v", info.FullMethod, err)
+	}
+	return resp, err
+}
+
+// NewGRPCServer DataBlockService, DBApisService, 헬스 체크, reflection 이 등록된 gRPC 서버를 생성함.
+// 설정에 토큰 소스가 있으면 모든 RPC 에 인증/인가 인터셉터를 붙임.
+func NewGRPCServer(core *service.DataBlockCliService) (*grpc.Server, *health.Server, error) {
+	serverCfg := core.Config().Server.WithDefaults()
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{metricsUnaryInterceptor, loggingInterceptor}
+	streamInterceptors := []grpc.StreamServerInterceptor{metricsStreamInterceptor}
+	if unary, stream := compressionInterceptors(serverCfg.Compression); unary != nil {
+		unaryInterceptors = append(unaryInterceptors, unary)
+		streamInterceptors = append(streamInterceptors, stream)
+	}
+	authn, policy, err := auth.New(core.Config().Auth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to set up authentication: %w", err)
+	}
+	if authn != nil {
+		unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor(authn, policy))
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor(authn, policy))
+	} else {
+		logger.Warn("authentication is disabled; every client can call every RPC")
+	}
+
+	ka := serverCfg.Keepalive
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(serverCfg.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(serverCfg.MaxSendMsgSize),
+		grpc.MaxConcurrentStreams(serverCfg.MaxConcurrentStreams),
+		// 0 인 값은 gRPC 기본값이 적용됨.
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:              ka.Time.D(),
+			Timeout:           ka.Timeout.D(),
+			MaxConnectionIdle: ka.MaxConnectionIdle.D(),
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             ka.MinTime.D(),
+			PermitWithoutStream: ka.PermitWithoutStream,
+		}),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+	if serverCfg.TLS.Enabled() {
+		tlsCfg, err := tlsutil.ServerConfig(serverCfg.TLS.CertFile, serverCfg.TLS.KeyFile, serverCfg.TLS.ClientCAFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to set up TLS: %w", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	} else {
+		logger.Warn("TLS is disabled; data paths and tokens are sent in cleartext")
+	}
+	grpcServer := grpc.NewServer(opts...)
+
+	pb.RegisterDataBlockServiceServer(grpcServer, service.NewDataBlockServer(core))
+	pb.RegisterDBApisServiceServer(grpcServer, service.NewDBApisServer(core))
+
+	// 헬스 체크 서비스 등록
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	// Reflection 서비스 등록, 디버깅 및 grpcurl 노출 위해서.
+	reflection.Register(grpcServer)
+	return grpcServer, healthServer, nil
+}
+
+// ServeGRPC 설정의 server.address(또는 server.unixSocket)에서 gRPC 서버를 실행하고, ctx 가 취소되면 graceful shutdown 처리함.
+func ServeGRPC(ctx context.Context, core *service.DataBlockCliService) error {
+	lis, err := Listen(core.Config().Server.WithDefaults())
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, lis, core)
+}
+
+// Listen cfg 의 unixSocket 이 있으면 Unix socket 에서, 아니면 address 의 TCP 포트에서 listen 함.
+// 이전 실행에서 남은 socket 파일은 지우고 다시 만듦.
+func Listen(cfg config.ServerConfig) (net.Listener, error) {
+	if cfg.UnixSocket == "" {
+		lis, err := net.Listen("tcp", cfg.Address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", cfg.Address, err)
+		}
+		return lis, nil
+	}
+	if info, err := os.Lstat(cfg.UnixSocket); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", cfg.UnixSocket)
+		}
+		if err := os.Remove(cfg.UnixSocket); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", cfg.UnixSocket, err)
+		}
+	}
+	lis, err := net.Listen("unix", cfg.UnixSocket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket %s: %w", cfg.UnixSocket, err)
+	}
+	return lis, nil
+}
+
+// Serve 주어진 listener 에서 gRPC 서버를 실행함. 테스트에서는 bufconn listener 를 넘겨서 사용.
+func Serve(ctx context.Context, lis net.Listener, core *service.DataBlockCliService) error {
+	grpcServer, healthServer, err := NewGRPCServer(core)
+	if err != nil {
+		return err
+	}
+	grace := core.Config().Server.WithDefaults().ShutdownGracePeriod.D()
+
+	// CLI 등 다른 프로세스에서 sync 한 결과도 WatchDataBlock 구독자들에게 전달되도록 datablock.pb 를 감시함.
+	go core.WatchDataBlockFile(ctx, DataBlockPollInterval)
+
+	// graceful shutdown 처리
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		logger.Infof("gRPC server shutting down: %v", context.Cause(ctx))
+		// 헬스 체크를 먼저 NOT_SERVING 으로 바꿔서 로드밸런서 등이 새 요청을 보내지 않도록 함.
+		healthServer.Shutdown()
+		// WatchDataBlock 스트림은 스스로 끝나지 않으므로 구독을 먼저 끊어줘야 GracefulStop 이 끝남.
+		core.CloseSubscribers()
+		// GracefulStop 은 현재 처리 중인 요청을 모두 완료한 후 서버를 중지함.
+		// grace period 안에 끝나지 않으면 남은 연결을 강제로 끊음.
+		done := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(done)
+		}()
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+			logger.Warnf("graceful shutdown did not finish within %s; forcing stop", grace)
+			grpcServer.Stop()
+			<-done
+		}
+	}()
+
+	logger.Infof("gRPC server started, address: %s", lis.Addr())
+	if err := grpcServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("gRPC server returned with error: %w", err)
+	}
+	<-stopped
+	logger.Info("gRPC server is shut down")
+	return nil
+}
@@ -0,0 +1,1396 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"github.com/seoyhaein/tori/checksum"
+	"github.com/seoyhaein/tori/client"
+	"github.com/seoyhaein/tori/config"
+	dbUtils "github.com/seoyhaein/tori/db"
+	"github.com/seoyhaein/tori/metrics"
+	"github.com/seoyhaein/tori/protofile"
+	pb "github.com/seoyhaein/tori/protos"
+	"github.com/seoyhaein/tori/service"
+	"github.com/seoyhaein/tori/tlsutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const bufSize = 1024 * 1024
+
+// startBufServer 는 rootDir 에 datablock.pb 를 만들고, bufconn 위에서 서버를 띄운 뒤 연결된 ClientConn 을 반환함.
+func startBufServer(t *testing.T, data *pb.DataBlock) (*grpc.ClientConn, context.CancelFunc, <-chan error) {
+	t.Helper()
+	rootDir := t.TempDir()
+	if data != nil {
+		writeDataBlock(t, rootDir, data)
+	}
+	conn, _, cancel, errCh := startBufServerWithCore(t, rootDir)
+	return conn, cancel, errCh
+}
+
+// writeDataBlock rootDir/datablock.pb 를 data 로 덮어씀.
+func writeDataBlock(t *testing.T, rootDir string, data *pb.DataBlock) {
+	t.Helper()
+	if err := protofile.Save(filepath.Join(rootDir, "datablock.pb"), data, 0o644, protofile.None); err != nil {
+		t.Fatalf("failed to save datablock: %v", err)
+	}
+}
+
+func startBufServerWithCore(t *testing.T, rootDir string) (*grpc.ClientConn, *service.DataBlockCliService, context.CancelFunc, <-chan error) {
+	t.Helper()
+	return startBufServerWithConfig(t, &config.Config{RootDir: rootDir})
+}
+
+// startBufServerWithConfig cfg 로 서버를 띄움. dialOpts 는 클라이언트 연결에 추가됨.
+func startBufServerWithConfig(t *testing.T, cfg *config.Config, dialOpts ...grpc.DialOption) (*grpc.ClientConn, *service.DataBlockCliService, context.CancelFunc, <-chan error) {
+	t.Helper()
+	return startBufServerWithService(t, newCore(t, nil, cfg), dialOpts...)
+}
+
+// newCore service.NewDataBlockCliService 를 만들고, 실패하면 테스트를 끝냄.
+func newCore(t *testing.T, db *sql.DB, cfg *config.Config) *service.DataBlockCliService {
+	t.Helper()
+	core, err := service.NewDataBlockCliService(db, cfg)
+	if err != nil {
+		t.Fatalf("NewDataBlockCliService failed: %v", err)
+	}
+	return core
+}
+
+// startBufServerWithService core 로 서버를 띄움. DB 가 필요한 테스트에서 사용함.
+func startBufServerWithService(t *testing.T, core *service.DataBlockCliService, dialOpts ...grpc.DialOption) (*grpc.ClientConn, *service.DataBlockCliService, context.CancelFunc, <-chan error) {
+	t.Helper()
+	lis := bufconn.Listen(bufSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Serve(ctx, lis, core)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet", append([]grpc.DialOption{
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials())}, dialOpts...)...)
+	if err != nil {
+		cancel()
+		t.Fatalf("failed to connect via bufnet: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := conn.Close(); err != nil {
+			t.Logf("failed to close connection: %v", err)
+		}
+	})
+	return conn, core, cancel, errCh
+}
+
+func TestServeGetDataBlock(t *testing.T) {
+	updatedAt := timestamppb.New(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	data := &pb.DataBlock{
+		UpdatedAt: updatedAt,
+		Blocks:    []*pb.FileBlock{{BlockId: "b1", ColumnHeaders: []string{"R1"}}},
+	}
+	conn, cancel, errCh := startBufServer(t, data)
+	defer cancel()
+
+	client := pb.NewDataBlockServiceClient(conn)
+	ctx := context.Background()
+
+	// 클라이언트가 아무 버전도 없으면 전체 DataBlock 을 받음.
+	resp, err := client.GetDataBlock(ctx, &pb.GetDataBlockRequest{})
+	if err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	if resp.GetNoUpdate() || len(resp.GetData().GetBlocks()) != 1 {
+		t.Fatalf("expected full datablock, got %+v", resp)
+	}
+
+	// 동일한 버전이면 no_update.
+	resp, err = client.GetDataBlock(ctx, &pb.GetDataBlockRequest{CurrentUpdatedAt: updatedAt})
+	if err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	if !resp.GetNoUpdate() || resp.GetData() != nil {
+		t.Fatalf("expected no_update response, got %+v", resp)
+	}
+
+	// 요청 수가 metric 으로 집계됨.
+	if want := `tori_grpc_requests_total{code="OK",method="` + pb.DataBlockService_GetDataBlock_FullMethodName + `"}`; !strings.Contains(scrapeMetrics(t), want) {
+		t.Errorf("metrics are missing %s", want)
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Errorf("Serve returned error: %v", err)
+	}
+}
+
+// scrapeMetrics metrics.Handler 가 내보내는 text exposition 을 반환함.
+func scrapeMetrics(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from /metrics, got %d", rec.Code)
+	}
+	return rec.Body.String()
+}
+
+// TestDataBlockVersioning generation·content_hash 로 비교하고, 서버가 모르는 버전이면 full_resync 로 전체를 보내는지 확인함.
+func TestDataBlockVersioning(t *testing.T) {
+	updatedAt := timestamppb.New(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	data := &pb.DataBlock{
+		UpdatedAt:   updatedAt,
+		Generation:  5,
+		ContentHash: "sha256:current",
+		Blocks:      []*pb.FileBlock{{BlockId: "b1", ColumnHeaders: []string{"R1"}}},
+	}
+	conn, cancel, _ := startBufServer(t, data)
+	defer cancel()
+	client := pb.NewDataBlockServiceClient(conn)
+
+	tests := []struct {
+		name       string
+		req        *pb.GetDataBlockRequest
+		noUpdate   bool
+		fullResync bool
+	}{
+		{"same hash", &pb.GetDataBlockRequest{CurrentContentHash: "sha256:current"}, true, false},
+		{"same generation", &pb.GetDataBlockRequest{CurrentGeneration: 5}, true, false},
+		{"older generation", &pb.GetDataBlockRequest{CurrentGeneration: 4}, false, false},
+		{"newer generation", &pb.GetDataBlockRequest{CurrentGeneration: 6}, false, true},
+		{"unknown hash", &pb.GetDataBlockRequest{CurrentContentHash: "sha256:other", CurrentGeneration: 5}, false, true},
+		{"newer updated_at", &pb.GetDataBlockRequest{CurrentUpdatedAt: timestamppb.New(updatedAt.AsTime().Add(time.Hour))}, false, true},
+		{"hash wins over updated_at", &pb.GetDataBlockRequest{CurrentContentHash: "sha256:current", CurrentUpdatedAt: timestamppb.Now()}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetDataBlock(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("GetDataBlock failed: %v", err)
+			}
+			if resp.GetNoUpdate() != tt.noUpdate || resp.GetFullResync() != tt.fullResync {
+				t.Fatalf("no_update=%v full_resync=%v, want %v %v", resp.GetNoUpdate(), resp.GetFullResync(), tt.noUpdate, tt.fullResync)
+			}
+			if !tt.noUpdate && (resp.GetData().GetGeneration() != 5 || resp.GetData().GetContentHash() != "sha256:current") {
+				t.Fatalf("unexpected version in response: %d %q", resp.GetData().GetGeneration(), resp.GetData().GetContentHash())
+			}
+		})
+	}
+
+	// 스트림 header 에도 같은 버전 정보가 실림.
+	stream, err := client.StreamDataBlock(context.Background(), &pb.GetDataBlockRequest{CurrentGeneration: 6})
+	if err != nil {
+		t.Fatalf("StreamDataBlock failed: %v", err)
+	}
+	chunk, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if h := chunk.GetHeader(); h.GetGeneration() != 5 || h.GetContentHash() != "sha256:current" || !h.GetFullResync() {
+		t.Fatalf("unexpected header %+v", h)
+	}
+}
+
+// TestDataBlockCache datablock.pb 의 수정 시각·크기가 그대로면 캐시를 쓰고, 바뀌었을 때만 다시 읽는지 확인함.
+func TestDataBlockCache(t *testing.T) {
+	rootDir := t.TempDir()
+	dataBlockPath := filepath.Join(rootDir, "datablock.pb")
+	core := newCore(t, nil, &config.Config{RootDir: rootDir})
+	ctx := context.Background()
+
+	// 두 버전은 updated_at 만 다르고 직렬화 크기는 같음.
+	v1 := &pb.DataBlock{UpdatedAt: &timestamppb.Timestamp{Seconds: 1000}}
+	v2 := &pb.DataBlock{UpdatedAt: &timestamppb.Timestamp{Seconds: 2000}}
+	writeDataBlock(t, rootDir, v1)
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(dataBlockPath, mtime, mtime); err != nil {
+		t.Fatalf("chtimes failed: %v", err)
+	}
+
+	first, err := core.GetDataBlock(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	again, err := core.GetDataBlock(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	if again != first {
+		t.Errorf("expected cached datablock to be reused")
+	}
+
+	// 내용은 바뀌었지만 수정 시각과 크기가 같으면 다시 읽지 않음.
+	writeDataBlock(t, rootDir, v2)
+	if err := os.Chtimes(dataBlockPath, mtime, mtime); err != nil {
+		t.Fatalf("chtimes failed: %v", err)
+	}
+	got, err := core.GetDataBlock(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	if got != first {
+		t.Errorf("expected cached datablock while mtime and size are unchanged")
+	}
+
+	// 수정 시각이 바뀌면 다시 읽음.
+	mtime = mtime.Add(time.Minute)
+	if err := os.Chtimes(dataBlockPath, mtime, mtime); err != nil {
+		t.Fatalf("chtimes failed: %v", err)
+	}
+	second, err := core.GetDataBlock(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	if !proto.Equal(second.GetUpdatedAt(), v2.GetUpdatedAt()) {
+		t.Fatalf("expected reloaded datablock %v, got %v", v2.GetUpdatedAt(), second.GetUpdatedAt())
+	}
+
+	// 수정 시각만 바뀌고 내용이 같으면 다시 파싱하지 않고 기존 DataBlock 을 씀.
+	mtime = mtime.Add(time.Minute)
+	if err := os.Chtimes(dataBlockPath, mtime, mtime); err != nil {
+		t.Fatalf("chtimes failed: %v", err)
+	}
+	touched, err := core.GetDataBlock(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	if touched != second {
+		t.Errorf("expected the same datablock when only mtime changed")
+	}
+}
+
+func TestServeHealthAndShutdown(t *testing.T) {
+	conn, cancel, errCh := startBufServer(t, nil)
+	defer cancel()
+
+	healthClient := grpc_health_v1.NewHealthClient(conn)
+	healthResp, err := healthClient.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("health check failed: %v", err)
+	}
+	if healthResp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("health status mismatch. expected: %v, got: %v", grpc_health_v1.HealthCheckResponse_SERVING, healthResp.Status)
+	}
+
+	// datablock.pb 가 없으면 에러를 돌려줘야 함.
+	if _, err := pb.NewDataBlockServiceClient(conn).GetDataBlock(context.Background(), &pb.GetDataBlockRequest{}); err == nil {
+		t.Errorf("expected error when datablock.pb is missing")
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("Serve returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func TestWatchDataBlock(t *testing.T) {
+	rootDir := t.TempDir()
+	first := &pb.DataBlock{UpdatedAt: timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))}
+	writeDataBlock(t, rootDir, first)
+	conn, core, cancel, errCh := startBufServerWithCore(t, rootDir)
+	defer cancel()
+
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+	stream, err := pb.NewDataBlockServiceClient(conn).WatchDataBlock(streamCtx, &pb.GetDataBlockRequest{})
+	if err != nil {
+		t.Fatalf("WatchDataBlock failed: %v", err)
+	}
+
+	// 구독하자마자 현재 DataBlock 을 받아야 함.
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("first Recv failed: %v", err)
+	}
+	if !proto.Equal(resp.GetData().GetUpdatedAt(), first.UpdatedAt) {
+		t.Fatalf("unexpected first datablock: %+v", resp)
+	}
+
+	// 디스크의 datablock.pb 가 바뀌고 알림이 가면 새 DataBlock 이 push 되어야 함.
+	second := &pb.DataBlock{
+		UpdatedAt: timestamppb.New(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)),
+		Blocks:    []*pb.FileBlock{{BlockId: "b2"}},
+	}
+	writeDataBlock(t, rootDir, second)
+	if err := core.NotifyDataBlockChanged(context.Background()); err != nil {
+		t.Fatalf("NotifyDataBlockChanged failed: %v", err)
+	}
+	resp, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("second Recv failed: %v", err)
+	}
+	if !proto.Equal(resp.GetData().GetUpdatedAt(), second.UpdatedAt) || len(resp.GetData().GetBlocks()) != 1 {
+		t.Fatalf("unexpected pushed datablock: %+v", resp)
+	}
+
+	// 클라이언트가 끊으면 구독이 정리되어야 함.
+	streamCancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for core.Subscribers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscription was not cleaned up, subscribers: %d", core.Subscribers())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Errorf("Serve returned error: %v", err)
+	}
+}
+
+func TestWatchDataBlockEndsOnShutdown(t *testing.T) {
+	conn, cancel, errCh := startBufServer(t, &pb.DataBlock{UpdatedAt: timestamppb.Now()})
+	defer cancel()
+
+	stream, err := pb.NewDataBlockServiceClient(conn).WatchDataBlock(context.Background(), &pb.GetDataBlockRequest{})
+	if err != nil {
+		t.Fatalf("WatchDataBlock failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("first Recv failed: %v", err)
+	}
+
+	// 열린 스트림이 있어도 서버는 종료되어야 함.
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("Serve returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down with an open watch stream")
+	}
+}
+
+func TestGetDataBlockDelta(t *testing.T) {
+	row := func(n int32, r1 string) *pb.Row {
+		return &pb.Row{RowNumber: n, Cells: map[string]string{"R1": r1}}
+	}
+	rootDir := t.TempDir()
+	v1 := &pb.DataBlock{
+		UpdatedAt: timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Blocks: []*pb.FileBlock{
+			{BlockId: "keep", ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{row(0, "a"), row(1, "b"), row(2, "c")}},
+			{BlockId: "gone", ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{row(0, "x")}},
+			{BlockId: "same", ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{row(0, "s")}},
+		},
+	}
+	writeDataBlock(t, rootDir, v1)
+	conn, _, cancel, _ := startBufServerWithCore(t, rootDir)
+	defer cancel()
+	client := pb.NewDataBlockServiceClient(conn)
+	ctx := context.Background()
+
+	// 서버가 v1 을 한 번 읽어야 history 에 남음.
+	if _, err := client.GetDataBlock(ctx, &pb.GetDataBlockRequest{}); err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+
+	v2 := &pb.DataBlock{
+		UpdatedAt: timestamppb.New(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)),
+		Blocks: []*pb.FileBlock{
+			{BlockId: "keep", ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{row(0, "a"), row(1, "B")}},
+			{BlockId: "same", ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{row(0, "s")}},
+			{BlockId: "new", ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{row(0, "n")}},
+		},
+	}
+	writeDataBlock(t, rootDir, v2)
+
+	delta, err := client.GetDataBlockDelta(ctx, &pb.GetDataBlockDeltaRequest{CurrentUpdatedAt: v1.UpdatedAt})
+	if err != nil {
+		t.Fatalf("GetDataBlockDelta failed: %v", err)
+	}
+	if delta.GetFullSnapshot() || delta.GetNoUpdate() {
+		t.Fatalf("expected incremental delta, got %+v", delta)
+	}
+	if len(delta.GetAddedBlocks()) != 1 || delta.GetAddedBlocks()[0].GetBlockId() != "new" {
+		t.Errorf("unexpected added blocks: %v", delta.GetAddedBlocks())
+	}
+	if len(delta.GetRemovedBlockIds()) != 1 || delta.GetRemovedBlockIds()[0] != "gone" {
+		t.Errorf("unexpected removed blocks: %v", delta.GetRemovedBlockIds())
+	}
+	if len(delta.GetModifiedBlocks()) != 1 {
+		t.Fatalf("expected 1 modified block, got %v", delta.GetModifiedBlocks())
+	}
+	modified := delta.GetModifiedBlocks()[0]
+	if modified.GetBlockId() != "keep" || len(modified.GetUpsertedRows()) != 1 || modified.GetUpsertedRows()[0].GetRowNumber() != 1 ||
+		len(modified.GetRemovedRowNumbers()) != 1 || modified.GetRemovedRowNumbers()[0] != 2 {
+		t.Errorf("unexpected modified block: %+v", modified)
+	}
+
+	// 클라이언트가 delta 를 적용하면 서버의 v2 와 같아야 함.
+	applied, err := service.ApplyDataBlockDelta(v1, delta)
+	if err != nil {
+		t.Fatalf("ApplyDataBlockDelta failed: %v", err)
+	}
+	if !proto.Equal(applied, v2) {
+		t.Errorf("applied delta mismatch.\ngot:  %v\nwant: %v", applied, v2)
+	}
+
+	// 최신 버전이면 no_update.
+	delta, err = client.GetDataBlockDelta(ctx, &pb.GetDataBlockDeltaRequest{CurrentUpdatedAt: v2.UpdatedAt})
+	if err != nil {
+		t.Fatalf("GetDataBlockDelta failed: %v", err)
+	}
+	if !delta.GetNoUpdate() {
+		t.Errorf("expected no_update, got %+v", delta)
+	}
+
+	// 서버가 모르는 버전이면 full snapshot.
+	unknown := timestamppb.New(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	delta, err = client.GetDataBlockDelta(ctx, &pb.GetDataBlockDeltaRequest{CurrentUpdatedAt: unknown})
+	if err != nil {
+		t.Fatalf("GetDataBlockDelta failed: %v", err)
+	}
+	if !delta.GetFullSnapshot() || !proto.Equal(delta.GetSnapshot(), v2) {
+		t.Errorf("expected full snapshot, got %+v", delta)
+	}
+}
+
+func TestGetDataBlockDelta_Generation(t *testing.T) {
+	row := func(n int32, r1 string) *pb.Row {
+		return &pb.Row{RowNumber: n, Cells: map[string]string{"R1": r1}}
+	}
+	rootDir := t.TempDir()
+	v1 := &pb.DataBlock{
+		UpdatedAt:   timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Generation:  1,
+		ContentHash: "sha256:v1",
+		Blocks:      []*pb.FileBlock{{BlockId: "keep", ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{row(0, "a")}}},
+	}
+	writeDataBlock(t, rootDir, v1)
+	conn, _, cancel, _ := startBufServerWithCore(t, rootDir)
+	defer cancel()
+	client := pb.NewDataBlockServiceClient(conn)
+	ctx := context.Background()
+
+	// 서버가 v1 을 한 번 읽어야 history 에 남음.
+	if _, err := client.GetDataBlock(ctx, &pb.GetDataBlockRequest{}); err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	v2 := &pb.DataBlock{
+		UpdatedAt:   timestamppb.New(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)),
+		Generation:  2,
+		ContentHash: "sha256:v2",
+		Blocks:      []*pb.FileBlock{{BlockId: "keep", ColumnHeaders: []string{"R1"}, Rows: []*pb.Row{row(0, "a"), row(1, "b")}}},
+	}
+	writeDataBlock(t, rootDir, v2)
+
+	for name, req := range map[string]*pb.GetDataBlockDeltaRequest{
+		"generation":   {CurrentGeneration: 1},
+		"content_hash": {CurrentContentHash: "sha256:v1"},
+		// 모르는 hash 면 같이 보낸 updated_at 으로 찾음.
+		"updated_at fallback": {CurrentContentHash: "sha256:unknown", CurrentUpdatedAt: v1.UpdatedAt},
+	} {
+		delta, err := client.GetDataBlockDelta(ctx, req)
+		if err != nil {
+			t.Fatalf("%s: GetDataBlockDelta failed: %v", name, err)
+		}
+		if delta.GetFullSnapshot() || delta.GetNoUpdate() {
+			t.Fatalf("%s: expected incremental delta, got %+v", name, delta)
+		}
+		if delta.GetGeneration() != 2 || delta.GetContentHash() != "sha256:v2" {
+			t.Errorf("%s: expected generation 2 and sha256:v2, got %d %q", name, delta.GetGeneration(), delta.GetContentHash())
+		}
+		applied, err := service.ApplyDataBlockDelta(v1, delta)
+		if err != nil {
+			t.Fatalf("%s: ApplyDataBlockDelta failed: %v", name, err)
+		}
+		if !proto.Equal(applied, v2) {
+			t.Errorf("%s: applied delta mismatch.\ngot:  %v\nwant: %v", name, applied, v2)
+		}
+	}
+
+	// 최신 generation 이면 no_update.
+	delta, err := client.GetDataBlockDelta(ctx, &pb.GetDataBlockDeltaRequest{CurrentGeneration: 2})
+	if err != nil {
+		t.Fatalf("GetDataBlockDelta failed: %v", err)
+	}
+	if !delta.GetNoUpdate() || delta.GetGeneration() != 2 {
+		t.Errorf("expected no_update at generation 2, got %+v", delta)
+	}
+
+	// 서버가 모르는 generation 이면 full snapshot.
+	delta, err = client.GetDataBlockDelta(ctx, &pb.GetDataBlockDeltaRequest{CurrentGeneration: 7})
+	if err != nil {
+		t.Fatalf("GetDataBlockDelta failed: %v", err)
+	}
+	if !delta.GetFullSnapshot() || !proto.Equal(delta.GetSnapshot(), v2) {
+		t.Errorf("expected full snapshot, got %+v", delta)
+	}
+}
+
+func TestServeAuth(t *testing.T) {
+	rootDir := t.TempDir()
+	writeDataBlock(t, rootDir, &pb.DataBlock{UpdatedAt: timestamppb.Now()})
+	tokenFile := filepath.Join(t.TempDir(), "tokens")
+	tokens := "# token subject roles\nreader-token alice reader\nadmin-token bob admin\n"
+	if err := os.WriteFile(tokenFile, []byte(tokens), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	cfg := &config.Config{RootDir: rootDir, Auth: config.AuthConfig{TokenFile: tokenFile, Roles: config.DefaultRoles()}}
+	conn, _, cancel, _ := startBufServerWithConfig(t, cfg)
+	defer cancel()
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+	dataClient := pb.NewDataBlockServiceClient(conn)
+	syncClient := pb.NewDBApisServiceClient(conn)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		call func(ctx context.Context) error
+		want codes.Code
+	}{
+		{"no token", context.Background(), func(ctx context.Context) error {
+			_, err := dataClient.GetDataBlock(ctx, &pb.GetDataBlockRequest{})
+			return err
+		}, codes.Unauthenticated},
+		{"bad token", withToken("nope"), func(ctx context.Context) error {
+			_, err := dataClient.GetDataBlock(ctx, &pb.GetDataBlockRequest{})
+			return err
+		}, codes.Unauthenticated},
+		{"reader reads", withToken("reader-token"), func(ctx context.Context) error {
+			_, err := dataClient.GetDataBlock(ctx, &pb.GetDataBlockRequest{})
+			return err
+		}, codes.OK},
+		{"reader watches", withToken("reader-token"), func(ctx context.Context) error {
+			stream, err := dataClient.WatchDataBlock(ctx, &pb.GetDataBlockRequest{})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.OK},
+		{"reader cannot sync", withToken("reader-token"), func(ctx context.Context) error {
+			_, err := syncClient.SyncFoldersInfo(ctx, &pb.SyncFoldersInfoRequest{})
+			return err
+		}, codes.PermissionDenied},
+		{"anonymous stream", context.Background(), func(ctx context.Context) error {
+			stream, err := dataClient.WatchDataBlock(ctx, &pb.GetDataBlockRequest{})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(tt.ctx, 5*time.Second)
+			defer cancel()
+			if got := status.Code(tt.call(ctx)); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// 헬스 체크는 토큰 없이도 호출 가능해야 함.
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Errorf("health check should not require a token: %v", err)
+	}
+}
+
+// TestNewServiceRejectsBadBlockIDKey 쓸 수 없는 blockIds 키로는 서비스를 만들지 않아서 serve 가 시작하지 않는지 확인함.
+func TestNewServiceRejectsBadBlockIDKey(t *testing.T) {
+	dir := t.TempDir()
+	shortKey := filepath.Join(dir, "short.key")
+	if err := os.WriteFile(shortKey, []byte("abcd"), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	for _, keyFile := range []string{filepath.Join(dir, "missing.key"), shortKey} {
+		cfg := &config.Config{RootDir: dir, BlockIDs: config.BlockIDConfig{Mode: config.BlockIDModeOpaque, KeyFile: keyFile}}
+		if _, err := service.NewDataBlockCliService(nil, cfg); err == nil {
+			t.Errorf("expected an error for key file %s", keyFile)
+		}
+	}
+}
+
+func TestServeOpaqueBlockIDs(t *testing.T) {
+	rootDir := t.TempDir()
+	folder := filepath.Join(rootDir, "run1", "sample")
+	writeDataBlock(t, rootDir, &pb.DataBlock{
+		UpdatedAt: timestamppb.Now(),
+		Blocks:    []*pb.FileBlock{{BlockId: folder, ColumnHeaders: []string{"R1"}}},
+	})
+	keyFile := filepath.Join(t.TempDir(), "blockid.key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("ab", 32)), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	cfg := &config.Config{RootDir: rootDir, BlockIDs: config.BlockIDConfig{Mode: config.BlockIDModeOpaque, KeyFile: keyFile}}
+	conn, core, cancel, _ := startBufServerWithConfig(t, cfg)
+	defer cancel()
+
+	resp, err := pb.NewDataBlockServiceClient(conn).GetDataBlock(context.Background(), &pb.GetDataBlockRequest{})
+	if err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	id := resp.GetData().GetBlocks()[0].GetBlockId()
+	if strings.Contains(id, rootDir) || strings.Contains(id, "sample") {
+		t.Errorf("block id leaks folder path: %s", id)
+	}
+	decoded, err := core.DecodeBlockID(id)
+	if err != nil {
+		t.Fatalf("DecodeBlockID failed: %v", err)
+	}
+	if decoded != folder {
+		t.Errorf("expected %s, got %s", folder, decoded)
+	}
+	// 디스크의 datablock.pb 는 그대로 경로를 가지고 있어야 함.
+	onDisk, err := service.LoadDataBlock(filepath.Join(rootDir, "datablock.pb"))
+	if err != nil {
+		t.Fatalf("LoadDataBlock failed: %v", err)
+	}
+	if onDisk.GetBlocks()[0].GetBlockId() != folder {
+		t.Errorf("datablock.pb should keep raw paths, got %s", onDisk.GetBlocks()[0].GetBlockId())
+	}
+}
+
+// setupRunFolder rootDir/run1 에 rule.json 과 fastq 파일들을 만들고, 그 폴더를 가리키는 datablock.pb 를 씀.
+// 1번 행의 R2 파일(s2_R2.fastq)은 DataBlock 에만 있고 디스크에는 없음.
+func setupRunFolder(t *testing.T) (string, string) {
+	t.Helper()
+	rootDir := t.TempDir()
+	folder := filepath.Join(rootDir, "run1")
+	if err := os.Mkdir(folder, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	rule := `{"version":"1","delimiter":["_",".fastq"],"header":["R1","R2"],` +
+		`"rowRules":{"matchParts":[0]},"columnRules":{"matchParts":[1]},"sizeRules":{"minSize":0,"maxSize":1000}}`
+	if err := os.WriteFile(filepath.Join(folder, "rule.json"), []byte(rule), 0o644); err != nil {
+		t.Fatalf("write rule.json failed: %v", err)
+	}
+	for _, name := range []string{"s1_R1.fastq", "s1_R2.fastq", "s2_R1.fastq"} {
+		if err := os.WriteFile(filepath.Join(folder, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+	writeDataBlock(t, rootDir, &pb.DataBlock{
+		UpdatedAt: timestamppb.Now(),
+		Blocks: []*pb.FileBlock{{
+			BlockId:       folder,
+			ColumnHeaders: []string{"R1", "R2"},
+			Rows: []*pb.Row{
+				{RowNumber: 0, Cells: map[string]string{"R1": "s1_R1.fastq", "R2": "s1_R2.fastq"}},
+				{RowNumber: 1, Cells: map[string]string{"R1": "s2_R1.fastq", "R2": "s2_R2.fastq"}},
+			},
+		}},
+	})
+	return rootDir, folder
+}
+
+func TestResolvePaths(t *testing.T) {
+	rootDir, folder := setupRunFolder(t)
+	keyFile := filepath.Join(t.TempDir(), "blockid.key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("cd", 32)), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	cfg := &config.Config{RootDir: rootDir, BlockIDs: config.BlockIDConfig{Mode: config.BlockIDModeOpaque, KeyFile: keyFile}}
+	conn, core, cancel, _ := startBufServerWithConfig(t, cfg)
+	defer cancel()
+	blockID, err := core.EncodeBlockID(folder)
+	if err != nil {
+		t.Fatalf("EncodeBlockID failed: %v", err)
+	}
+
+	client := pb.NewDataBlockServiceClient(conn)
+	byNumber := func(n int32, col string) *pb.PathSelection {
+		return &pb.PathSelection{BlockId: blockID, Row: &pb.PathSelection_RowNumber{RowNumber: n}, Column: col}
+	}
+	selections := []*pb.PathSelection{
+		byNumber(0, "R2"),
+		{BlockId: blockID, Row: &pb.PathSelection_RowKey{RowKey: "s2"}, Column: "R1"},
+		byNumber(1, "R2"), // 셀은 있지만 파일이 디스크에 없음
+		byNumber(0, "R3"), // 없는 컬럼
+		byNumber(7, "R1"), // 없는 행
+		{BlockId: folder, Row: &pb.PathSelection_RowNumber{RowNumber: 0}, Column: "R1"}, // opaque 모드에서 raw 경로는 거부
+	}
+	resp, err := client.ResolvePaths(context.Background(), &pb.ResolvePathsRequest{Selections: selections})
+	if err != nil {
+		t.Fatalf("ResolvePaths failed: %v", err)
+	}
+	results := resp.GetResults()
+	if len(results) != len(selections) {
+		t.Fatalf("expected %d results, got %d", len(selections), len(results))
+	}
+	if got, want := results[0].GetPath(), filepath.Join(folder, "s1_R2.fastq"); got != want || results[0].GetError() != "" {
+		t.Errorf("result 0: expected %s, got %q (error %q)", want, got, results[0].GetError())
+	}
+	if got, want := results[1].GetPath(), filepath.Join(folder, "s2_R1.fastq"); got != want {
+		t.Errorf("result 1: expected %s, got %q (error %q)", want, got, results[1].GetError())
+	}
+	for i := 2; i < len(results); i++ {
+		if results[i].GetPath() != "" || results[i].GetError() == "" {
+			t.Errorf("result %d: expected an error, got %+v", i, results[i])
+		}
+	}
+
+	resp, err = client.ResolvePaths(context.Background(), &pb.ResolvePathsRequest{Selections: selections[:1], Relative: true})
+	if err != nil {
+		t.Fatalf("ResolvePaths failed: %v", err)
+	}
+	if got := resp.GetResults()[0].GetPath(); got != filepath.Join("run1", "s1_R2.fastq") {
+		t.Errorf("expected relative path, got %q", got)
+	}
+}
+
+// TestResolvePathsDotDotFolder 이름이 ".." 로 시작하는 폴더도 root 안에 있으면 상대 경로로 돌려주는지 확인함.
+func TestResolvePathsDotDotFolder(t *testing.T) {
+	rootDir, run1 := setupRunFolder(t)
+	folder := filepath.Join(rootDir, "..run1")
+	if err := os.Rename(run1, folder); err != nil {
+		t.Fatalf("rename failed: %v", err)
+	}
+	writeDataBlock(t, rootDir, &pb.DataBlock{
+		UpdatedAt: timestamppb.Now(),
+		Blocks: []*pb.FileBlock{{
+			BlockId:       folder,
+			ColumnHeaders: []string{"R1", "R2"},
+			Rows:          []*pb.Row{{RowNumber: 0, Cells: map[string]string{"R1": "s1_R1.fastq", "R2": "s1_R2.fastq"}}},
+		}},
+	})
+	conn, _, cancel, _ := startBufServerWithConfig(t, &config.Config{RootDir: rootDir})
+	defer cancel()
+
+	sel := &pb.PathSelection{BlockId: folder, Row: &pb.PathSelection_RowNumber{RowNumber: 0}, Column: "R1"}
+	resp, err := pb.NewDataBlockServiceClient(conn).ResolvePaths(context.Background(), &pb.ResolvePathsRequest{Selections: []*pb.PathSelection{sel}, Relative: true})
+	if err != nil {
+		t.Fatalf("ResolvePaths failed: %v", err)
+	}
+	if got, want := resp.GetResults()[0].GetPath(), filepath.Join("..run1", "s1_R1.fastq"); got != want {
+		t.Errorf("expected %s, got %q (error %q)", want, got, resp.GetResults()[0].GetError())
+	}
+}
+
+// TestResolvePathsChecksum checksum 을 켜면 ResolvePaths 가 마지막 sync 때 계산한 checksum 을 돌려주고,
+// 그 뒤로 파일이 수정되면 믿을 수 없는 값이므로 비워 두는지 확인함.
+func TestResolvePathsChecksum(t *testing.T) {
+	rootDir, folder := setupRunFolder(t)
+	cfg := &config.Config{RootDir: rootDir, Checksum: checksum.SHA256, FilesExclusions: []string{"*.json", "invalid_files", "*.csv", "*.pb"}}
+	db, err := dbUtils.ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := dbUtils.InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase failed: %v", err)
+	}
+	core := newCore(t, db, cfg)
+	if err := core.SaveFolders(context.Background()); err != nil {
+		t.Fatalf("SaveFolders failed: %v", err)
+	}
+	if _, err := core.SyncFoldersWithOptions(context.Background(), dbUtils.SyncOptions{Force: true}); err != nil {
+		t.Fatalf("SyncFoldersWithOptions failed: %v", err)
+	}
+	conn, _, cancel, _ := startBufServerWithService(t, core)
+	defer cancel()
+	c := pb.NewDataBlockServiceClient(conn)
+
+	sel := &pb.PathSelection{BlockId: folder, Row: &pb.PathSelection_RowNumber{RowNumber: 0}, Column: "R1"}
+	want, err := checksum.File(filepath.Join(folder, "s1_R1.fastq"), checksum.SHA256)
+	if err != nil {
+		t.Fatalf("checksum.File failed: %v", err)
+	}
+	for _, relative := range []bool{false, true} {
+		resp, err := c.ResolvePaths(context.Background(), &pb.ResolvePathsRequest{Selections: []*pb.PathSelection{sel}, Relative: relative})
+		if err != nil {
+			t.Fatalf("ResolvePaths failed: %v", err)
+		}
+		if got := resp.GetResults()[0].GetChecksum(); got != want {
+			t.Errorf("relative=%v: expected checksum %s, got %q", relative, want, got)
+		}
+	}
+
+	// sync 전에 바뀐 파일의 checksum 은 돌려주지 않음.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(folder, "s1_R1.fastq"), later, later); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	resp, err := c.ResolvePaths(context.Background(), &pb.ResolvePathsRequest{Selections: []*pb.PathSelection{sel}})
+	if err != nil {
+		t.Fatalf("ResolvePaths failed: %v", err)
+	}
+	if got := resp.GetResults()[0]; got.GetPath() == "" || got.GetChecksum() != "" {
+		t.Errorf("expected a path without checksum for a modified file, got %+v", got)
+	}
+}
+
+func TestRenderScript(t *testing.T) {
+	rootDir, folder := setupRunFolder(t)
+	conn, _, cancel, _ := startBufServerWithCore(t, rootDir)
+	defer cancel()
+	client := pb.NewDataBlockServiceClient(conn)
+	ctx := context.Background()
+
+	tmpl := `#!/bin/sh
+bwa mem {{ fileBlock "run1" | row 0 | col "R1" }} {{- " " -}} {{ fileBlock "run1" | rowKey "s1" | col "R2" }}
+{{ range $r := fileBlock "run1" | rows }}echo {{ $r.Number }} {{ $r | col "R1" }}
+{{ end }}`
+	resp, err := client.RenderScript(ctx, &pb.RenderScriptRequest{Template: tmpl, Relative: true})
+	if err != nil {
+		t.Fatalf("RenderScript failed: %v", err)
+	}
+	if len(resp.GetErrors()) != 0 {
+		t.Fatalf("unexpected render errors: %v", resp.GetErrors())
+	}
+	want := `#!/bin/sh
+bwa mem run1/s1_R1.fastq run1/s1_R2.fastq
+echo 0 run1/s1_R1.fastq
+echo 1 run1/s2_R1.fastq
+`
+	if resp.GetScript() != want {
+		t.Errorf("unexpected script.\ngot:\n%s\nwant:\n%s", resp.GetScript(), want)
+	}
+
+	// 절대 경로로도 block 을 지정할 수 있음.
+	resp, err = client.RenderScript(ctx, &pb.RenderScriptRequest{Template: `{{ fileBlock "` + folder + `" | row 1 | col "R1" }}`})
+	if err != nil {
+		t.Fatalf("RenderScript failed: %v", err)
+	}
+	if got := resp.GetScript(); got != filepath.Join(folder, "s2_R1.fastq") {
+		t.Errorf("expected absolute path, got %q (errors %v)", got, resp.GetErrors())
+	}
+
+	// 채우지 못한 자리표시자는 모두 errors 로 보고됨.
+	resp, err = client.RenderScript(ctx, &pb.RenderScriptRequest{
+		Template: `{{ fileBlock "run1" | row 1 | col "R2" }} {{ fileBlock "run1" | row 9 | col "R1" }} {{ fileBlock "nope" | row 0 | col "R1" }}`,
+	})
+	if err != nil {
+		t.Fatalf("RenderScript failed: %v", err)
+	}
+	if resp.GetScript() != "" || len(resp.GetErrors()) != 3 {
+		t.Errorf("expected 3 errors and no script, got %q %v", resp.GetScript(), resp.GetErrors())
+	}
+
+	// 문법이 잘못된 템플릿은 InvalidArgument.
+	_, err = client.RenderScript(ctx, &pb.RenderScriptRequest{Template: `{{ fileBlock "run1" | row }`})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+// TestRenderScriptQuoting 공백, $, ;, 작은따옴표가 있는 경로도 shell 에서 한 단어로 읽히는지 확인함.
+func TestRenderScriptQuoting(t *testing.T) {
+	rootDir := t.TempDir()
+	folder := filepath.Join(rootDir, "my run")
+	names := []string{"s1 $HOME;x_R1.fastq", "it's_R1.fastq"}
+	if err := os.Mkdir(folder, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(folder, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+	writeDataBlock(t, rootDir, &pb.DataBlock{
+		UpdatedAt: timestamppb.Now(),
+		Blocks: []*pb.FileBlock{{
+			BlockId:       folder,
+			ColumnHeaders: []string{"R1"},
+			Rows: []*pb.Row{
+				{RowNumber: 0, Cells: map[string]string{"R1": names[0]}},
+				{RowNumber: 1, Cells: map[string]string{"R1": names[1]}},
+			},
+		}},
+	})
+	conn, _, cancel, _ := startBufServerWithCore(t, rootDir)
+	defer cancel()
+	client := pb.NewDataBlockServiceClient(conn)
+
+	tmpl := `{{ range fileBlock "my run" | rows }}{{ . | col "R1" }} {{ . | col "R1" | raw }}|{{ . | col "R1" | raw | printf "%s.bam" | shellquote }}
+{{ end }}`
+	resp, err := client.RenderScript(context.Background(), &pb.RenderScriptRequest{Template: tmpl, Relative: true})
+	if err != nil || len(resp.GetErrors()) != 0 {
+		t.Fatalf("RenderScript failed: %v %v", err, resp.GetErrors())
+	}
+	want := `'my run/s1 $HOME;x_R1.fastq' my run/s1 $HOME;x_R1.fastq|'my run/s1 $HOME;x_R1.fastq.bam'
+'my run/it'\''s_R1.fastq' my run/it's_R1.fastq|'my run/it'\''s_R1.fastq.bam'
+`
+	if resp.GetScript() != want {
+		t.Errorf("unexpected script.\ngot:\n%s\nwant:\n%s", resp.GetScript(), want)
+	}
+
+	// shell 이 실제로 경로 하나씩으로 읽는지 확인함.
+	resp, err = client.RenderScript(context.Background(), &pb.RenderScriptRequest{
+		Template: `printf '%s\n'{{ range fileBlock "my run" | rows }} {{ . | col "R1" }}{{ end }}`,
+	})
+	if err != nil || len(resp.GetErrors()) != 0 {
+		t.Fatalf("RenderScript failed: %v %v", err, resp.GetErrors())
+	}
+	out, err := exec.Command("sh", "-c", resp.GetScript()).Output()
+	if err != nil {
+		t.Fatalf("sh failed: %v", err)
+	}
+	wantOut := filepath.Join(folder, names[0]) + "\n" + filepath.Join(folder, names[1]) + "\n"
+	if string(out) != wantOut {
+		t.Errorf("expected one argument per path, got %q want %q", out, wantOut)
+	}
+}
+
+// TestRenderScriptOpaqueBlockIDs opaque 모드에서는 fileBlock 이 block ID 만 받고 경로로는 찾지 않는지 확인함.
+func TestRenderScriptOpaqueBlockIDs(t *testing.T) {
+	rootDir, folder := setupRunFolder(t)
+	keyFile := filepath.Join(t.TempDir(), "blockid.key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("ab", 32)), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	cfg := &config.Config{RootDir: rootDir, BlockIDs: config.BlockIDConfig{Mode: config.BlockIDModeOpaque, KeyFile: keyFile}}
+	conn, core, cancel, _ := startBufServerWithConfig(t, cfg)
+	defer cancel()
+	blockID, err := core.EncodeBlockID(folder)
+	if err != nil {
+		t.Fatalf("EncodeBlockID failed: %v", err)
+	}
+	client := pb.NewDataBlockServiceClient(conn)
+
+	resp, err := client.RenderScript(context.Background(), &pb.RenderScriptRequest{
+		Template: `{{ fileBlock "` + blockID + `" | row 0 | col "R1" }}`,
+		Relative: true,
+	})
+	if err != nil || resp.GetScript() != filepath.Join("run1", "s1_R1.fastq") {
+		t.Errorf("expected path of row 0, got %q %v (err %v)", resp.GetScript(), resp.GetErrors(), err)
+	}
+	resp, err = client.RenderScript(context.Background(), &pb.RenderScriptRequest{
+		Template: `{{ fileBlock "run1" | row 0 | col "R1" }} {{ fileBlock "` + folder + `" | row 0 | col "R1" }}`,
+	})
+	if err != nil || resp.GetScript() != "" || len(resp.GetErrors()) != 2 {
+		t.Errorf("expected paths to be rejected, got %q %v (err %v)", resp.GetScript(), resp.GetErrors(), err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	rootDir, folder := setupRunFolder(t)
+	conn, _, cancel, _ := startBufServerWithCore(t, rootDir)
+	defer cancel()
+	client := pb.NewDataBlockServiceClient(conn)
+
+	resp, err := client.Search(context.Background(), &pb.SearchRequest{BlockIdPrefix: "run1", CellGlob: "s2_*", Column: "R1"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if resp.GetTotalBlocks() != 1 || resp.GetBlocks()[0].GetBlockId() != folder {
+		t.Fatalf("unexpected result: %v", resp)
+	}
+	if rows := resp.GetBlocks()[0].GetRows(); len(rows) != 1 || rows[0].GetRowNumber() != 1 {
+		t.Errorf("expected only row 1, got %v", rows)
+	}
+
+	resp, err = client.Search(context.Background(), &pb.SearchRequest{BlockIdPrefix: "run2"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if resp.GetTotalBlocks() != 0 {
+		t.Errorf("expected no blocks, got %v", resp.GetBlocks())
+	}
+
+	if _, err := client.Search(context.Background(), &pb.SearchRequest{CellRegex: "("}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+// TestSearchOpaqueBlockIDs opaque 모드에서는 block_id_prefix 를 block ID 로 풀고, 경로는 거부하는지 확인함.
+func TestSearchOpaqueBlockIDs(t *testing.T) {
+	rootDir, folder := setupRunFolder(t)
+	keyFile := filepath.Join(t.TempDir(), "blockid.key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("ef", 32)), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	cfg := &config.Config{RootDir: rootDir, BlockIDs: config.BlockIDConfig{Mode: config.BlockIDModeOpaque, KeyFile: keyFile}}
+	conn, core, cancel, _ := startBufServerWithConfig(t, cfg)
+	defer cancel()
+	blockID, err := core.EncodeBlockID(folder)
+	if err != nil {
+		t.Fatalf("EncodeBlockID failed: %v", err)
+	}
+	client := pb.NewDataBlockServiceClient(conn)
+
+	resp, err := client.Search(context.Background(), &pb.SearchRequest{BlockIdPrefix: blockID})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if resp.GetTotalBlocks() != 1 || resp.GetBlocks()[0].GetBlockId() != blockID {
+		t.Errorf("expected the block with id %s, got %v", blockID, resp)
+	}
+	for _, prefix := range []string{"run1", folder, blockID[:len(blockID)/2]} {
+		if _, err := client.Search(context.Background(), &pb.SearchRequest{BlockIdPrefix: prefix}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("prefix %q: expected InvalidArgument, got %v", prefix, err)
+		}
+	}
+}
+
+func TestGetUIDataBlock(t *testing.T) {
+	rootDir, folder := setupRunFolder(t)
+	conn, _, cancel, _ := startBufServerWithCore(t, rootDir)
+	defer cancel()
+	client := pb.NewDataBlockServiceClient(conn)
+	ctx := context.Background()
+
+	// 셀에 경로가 들어 있어도 UI 에는 파일 이름만 넘어가야 함.
+	writeDataBlock(t, rootDir, &pb.DataBlock{
+		UpdatedAt: timestamppb.Now(),
+		Blocks: []*pb.FileBlock{{
+			BlockId:       folder,
+			ColumnHeaders: []string{"R1", "R2"},
+			Rows: []*pb.Row{
+				{RowNumber: 0, Cells: map[string]string{"R1": filepath.Join(folder, "s1_R1.fastq"), "R2": "s1_R2.fastq"}},
+			},
+		}},
+	})
+
+	resp, err := client.GetUIDataBlock(ctx, &pb.GetUIDataBlockRequest{})
+	if err != nil {
+		t.Fatalf("GetUIDataBlock failed: %v", err)
+	}
+	blocks := resp.GetData().GetBlocks()
+	if len(blocks) != 1 || blocks[0].GetBlockId() != "run1" {
+		t.Fatalf("expected one block with relative id run1, got %v", blocks)
+	}
+	if text := prototext.Format(resp.GetData()); strings.Contains(text, rootDir) {
+		t.Errorf("UI datablock leaks server paths:\n%s", text)
+	}
+	if got := blocks[0].GetRows()[0].GetCells()["R1"]; got != "s1_R1.fastq" {
+		t.Errorf("expected bare file name, got %q", got)
+	}
+
+	// UI 가 받은 block ID 로 경로를 다시 찾을 수 있어야 함.
+	resolved, err := client.ResolvePaths(ctx, &pb.ResolvePathsRequest{Selections: []*pb.PathSelection{
+		{BlockId: blocks[0].GetBlockId(), Row: &pb.PathSelection_RowNumber{RowNumber: 0}, Column: "R2"},
+	}})
+	if err != nil {
+		t.Fatalf("ResolvePaths failed: %v", err)
+	}
+	if got := resolved.GetResults()[0].GetPath(); got != filepath.Join(folder, "s1_R2.fastq") {
+		t.Errorf("expected %s, got %q (error %q)", filepath.Join(folder, "s1_R2.fastq"), got, resolved.GetResults()[0].GetError())
+	}
+
+	// 컬럼 whitelist.
+	resp, err = client.GetUIDataBlock(ctx, &pb.GetUIDataBlockRequest{Columns: []string{"R2"}})
+	if err != nil {
+		t.Fatalf("GetUIDataBlock failed: %v", err)
+	}
+	fb := resp.GetData().GetBlocks()[0]
+	if !slices.Equal(fb.GetColumnHeaders(), []string{"R2"}) || len(fb.GetRows()[0].GetCells()) != 1 {
+		t.Errorf("expected only R2 column, got %v", fb)
+	}
+	resp, err = client.GetUIDataBlock(ctx, &pb.GetUIDataBlockRequest{Columns: []string{"R3"}})
+	if err != nil {
+		t.Fatalf("GetUIDataBlock failed: %v", err)
+	}
+	if len(resp.GetData().GetBlocks()) != 0 {
+		t.Errorf("expected blocks without whitelisted columns to be dropped, got %v", resp.GetData().GetBlocks())
+	}
+
+	// 같은 버전이면 no_update.
+	resp, err = client.GetUIDataBlock(ctx, &pb.GetUIDataBlockRequest{CurrentUpdatedAt: resp.GetData().GetUpdatedAt()})
+	if err != nil {
+		t.Fatalf("GetUIDataBlock failed: %v", err)
+	}
+	if !resp.GetNoUpdate() {
+		t.Errorf("expected no_update, got %v", resp)
+	}
+}
+
+func TestServeUnixSocket(t *testing.T) {
+	rootDir := t.TempDir()
+	writeDataBlock(t, rootDir, &pb.DataBlock{UpdatedAt: timestamppb.Now()})
+	socket := filepath.Join(t.TempDir(), "tori.sock")
+	// 이전 실행에서 남은 socket 파일이 있어도 다시 listen 할 수 있어야 함.
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets not supported: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	core := newCore(t, nil, &config.Config{
+		RootDir: rootDir,
+		Server:  config.ServerConfig{UnixSocket: socket, ShutdownGracePeriod: config.Duration(time.Second)},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- ServeGRPC(ctx, core) }()
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer conn.Close()
+	callCtx, callCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer callCancel()
+	if _, err := pb.NewDataBlockServiceClient(conn).GetDataBlock(callCtx, &pb.GetDataBlockRequest{}, grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("GetDataBlock over unix socket failed: %v", err)
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Errorf("ServeGRPC returned error: %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("expected socket file to be removed on shutdown, got %v", err)
+	}
+}
+
+// TestNewGRPCServerMissingTLSFiles 설정을 읽을 때는 확인하지 않는 인증서 파일이 없으면 서버를 시작하지 않는지 확인함.
+func TestNewGRPCServerMissingTLSFiles(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		RootDir: dir,
+		Server: config.ServerConfig{TLS: config.TLSConfig{
+			CertFile: filepath.Join(dir, "server.crt"),
+			KeyFile:  filepath.Join(dir, "server.key"),
+		}},
+	}
+	if _, _, err := NewGRPCServer(newCore(t, nil, cfg)); err == nil || !strings.Contains(err.Error(), "server.crt") {
+		t.Errorf("expected an error naming the missing certificate, got %v", err)
+	}
+}
+
+func TestServeMutualTLS(t *testing.T) {
+	old := tlsutil.ReloadCheckInterval
+	tlsutil.ReloadCheckInterval = 0
+	defer func() { tlsutil.ReloadCheckInterval = old }()
+
+	rootDir := t.TempDir()
+	writeDataBlock(t, rootDir, &pb.DataBlock{UpdatedAt: timestamppb.Now()})
+	certDir := t.TempDir()
+	ca, err := tlsutil.GenerateCA("test-ca", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateCA failed: %v", err)
+	}
+	caFile := filepath.Join(certDir, "ca.crt")
+	if err := os.WriteFile(caFile, ca.CertPEM, 0o644); err != nil {
+		t.Fatalf("failed to write CA: %v", err)
+	}
+	issue := func(name string, hosts []string, client bool) (string, string) {
+		certPEM, keyPEM, err := ca.Issue(name, hosts, client, time.Hour)
+		if err != nil {
+			t.Fatalf("Issue(%s) failed: %v", name, err)
+		}
+		certFile, keyFile, err := tlsutil.WritePair(certDir, name, certPEM, keyPEM)
+		if err != nil {
+			t.Fatalf("WritePair(%s) failed: %v", name, err)
+		}
+		return certFile, keyFile
+	}
+	serverCert, serverKey := issue("server", []string{"localhost", "127.0.0.1"}, false)
+
+	cfg := &config.Config{
+		RootDir: rootDir,
+		Server: config.ServerConfig{
+			ShutdownGracePeriod: config.Duration(time.Second),
+			TLS:                 config.TLSConfig{CertFile: serverCert, KeyFile: serverKey, ClientCAFile: caFile},
+		},
+		Auth: config.AuthConfig{
+			ClientCerts: map[string][]string{"pipeline": {"reader"}},
+			Roles:       config.DefaultRoles(),
+		},
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	core := newCore(t, nil, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- Serve(ctx, lis, core) }()
+	defer func() {
+		cancel()
+		<-errCh
+	}()
+
+	dial := func(certFile, keyFile string) *grpc.ClientConn {
+		t.Helper()
+		tlsCfg, err := tlsutil.ClientConfig(caFile, certFile, keyFile, "localhost")
+		if err != nil {
+			t.Fatalf("ClientConfig failed: %v", err)
+		}
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	}
+	call := func(conn *grpc.ClientConn, p *peer.Peer) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := pb.NewDataBlockServiceClient(conn).GetDataBlock(ctx, &pb.GetDataBlockRequest{}, grpc.Peer(p))
+		return err
+	}
+
+	// 클라이언트 인증서가 없으면 handshake 에서 거부됨.
+	if err := call(dial("", ""), &peer.Peer{}); err == nil {
+		t.Error("expected connection without a client certificate to fail")
+	}
+
+	pipelineCert, pipelineKey := issue("pipeline", nil, true)
+	pipeline := dial(pipelineCert, pipelineKey)
+	if err := call(pipeline, &peer.Peer{}); err != nil {
+		t.Fatalf("GetDataBlock with mapped client certificate failed: %v", err)
+	}
+	syncCtx, syncCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer syncCancel()
+	if _, err := pb.NewDBApisServiceClient(pipeline).SyncFoldersInfo(syncCtx, &pb.SyncFoldersInfoRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected reader certificate to be denied sync, got %v", err)
+	}
+
+	strangerCert, strangerKey := issue("stranger", nil, true)
+	if err := call(dial(strangerCert, strangerKey), &peer.Peer{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected unmapped certificate to be unauthenticated, got %v", err)
+	}
+
+	// 서버 인증서를 교체하면 재시작 없이 새 연결부터 새 인증서를 사용해야 함.
+	certPEM, keyPEM, err := ca.Issue("server-rotated", []string{"localhost", "127.0.0.1"}, false, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	if _, _, err := tlsutil.WritePair(certDir, "server", certPEM, keyPEM); err != nil {
+		t.Fatalf("failed to rotate server certificate: %v", err)
+	}
+	var p peer.Peer
+	if err := call(dial(pipelineCert, pipelineKey), &p); err != nil {
+		t.Fatalf("GetDataBlock after rotation failed: %v", err)
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		t.Fatalf("expected TLS peer info, got %T", p.AuthInfo)
+	}
+	if cn := tlsInfo.State.PeerCertificates[0].Subject.CommonName; cn != "server-rotated" {
+		t.Errorf("expected rotated server certificate, got %q", cn)
+	}
+}
+
+func TestStreamDataBlock(t *testing.T) {
+	rootDir := t.TempDir()
+	data := &pb.DataBlock{UpdatedAt: timestamppb.Now()}
+	for i := 0; i < 8; i++ {
+		fb := &pb.FileBlock{BlockId: filepath.Join(rootDir, "sample"+strconv.Itoa(i)), ColumnHeaders: []string{"R1"}}
+		for r := 0; r < 100; r++ {
+			fb.Rows = append(fb.Rows, &pb.Row{RowNumber: int32(r), Cells: map[string]string{"R1": strings.Repeat("x", 100)}})
+		}
+		data.Blocks = append(data.Blocks, fb)
+	}
+	writeDataBlock(t, rootDir, data)
+
+	// DataBlock 전체는 넘지만 FileBlock 하나는 들어가는 크기로 제한함.
+	limit := proto.Size(data) / 4
+	conn, _, cancel, _ := startBufServerWithConfig(t, &config.Config{RootDir: rootDir},
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(limit)))
+	defer cancel()
+	c := pb.NewDataBlockServiceClient(conn)
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer ctxCancel()
+
+	if _, err := c.GetDataBlock(ctx, &pb.GetDataBlockRequest{}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected GetDataBlock to exceed the message size limit, got %v", err)
+	}
+	got, err := client.StreamDataBlock(ctx, c, nil)
+	if err != nil {
+		t.Fatalf("StreamDataBlock failed: %v", err)
+	}
+	if !proto.Equal(got, data) {
+		t.Errorf("reassembled DataBlock differs from the original")
+	}
+
+	got, err = client.StreamDataBlock(ctx, c, data.GetUpdatedAt())
+	if err != nil || got != nil {
+		t.Errorf("expected no update for the current version, got %v, %v", got, err)
+	}
+}
+
+// encodingRecorder 클라이언트가 받은 응답 header 의 압축 방식을 기록하는 stats.Handler.
+type encodingRecorder struct {
+	mu       sync.Mutex
+	received []string
+}
+
+func (r *encodingRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+func (r *encodingRecorder) HandleConn(context.Context, stats.ConnStats) {}
+func (r *encodingRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+func (r *encodingRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if h, ok := s.(*stats.InHeader); ok && h.Client {
+		r.mu.Lock()
+		r.received = append(r.received, h.Compression)
+		r.mu.Unlock()
+	}
+}
+
+func (r *encodingRecorder) last() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.received) == 0 {
+		return ""
+	}
+	return r.received[len(r.received)-1]
+}
+
+func TestServeCompression(t *testing.T) {
+	rootDir := t.TempDir()
+	data := &pb.DataBlock{UpdatedAt: timestamppb.Now(), Blocks: []*pb.FileBlock{{BlockId: filepath.Join(rootDir, "s1")}}}
+	// gzip 으로 저장된 datablock.pb 도 자동으로 판별해서 읽어야 함.
+	if err := protofile.Save(filepath.Join(rootDir, "datablock.pb"), data, 0o644, protofile.Gzip); err != nil {
+		t.Fatalf("failed to save compressed datablock: %v", err)
+	}
+	cfg := &config.Config{RootDir: rootDir, Compression: protofile.Gzip, Server: config.ServerConfig{Compression: "gzip"}}
+	recorder := &encodingRecorder{}
+	conn, _, cancel, _ := startBufServerWithConfig(t, cfg, grpc.WithStatsHandler(recorder))
+	defer cancel()
+	c := pb.NewDataBlockServiceClient(conn)
+
+	resp, err := c.GetDataBlock(context.Background(), &pb.GetDataBlockRequest{}, grpc.UseCompressor(gzip.Name))
+	if err != nil {
+		t.Fatalf("GetDataBlock failed: %v", err)
+	}
+	if !proto.Equal(resp.GetData(), data) {
+		t.Errorf("unexpected DataBlock: %v", resp.GetData())
+	}
+	if got := recorder.last(); got != gzip.Name {
+		t.Errorf("expected gzip-compressed response, got %q", got)
+	}
+
+	// server.compression 이 없으면 압축하지 않은 응답을 보내야 함.
+	conn2, _, cancel2, _ := startBufServerWithConfig(t, &config.Config{RootDir: rootDir}, grpc.WithStatsHandler(recorder))
+	defer cancel2()
+	if _, err := pb.NewDataBlockServiceClient(conn2).GetDataBlock(context.Background(), &pb.GetDataBlockRequest{}); err != nil {
+		t.Fatalf("GetDataBlock without compression failed: %v", err)
+	}
+	if got := recorder.last(); got != "" && got != "identity" {
+		t.Errorf("expected uncompressed response when server.compression is unset, got %q", got)
+	}
+}
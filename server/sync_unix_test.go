@@ -0,0 +1,191 @@
+//go:build unix
+
+package server
+
+import (
+	"context"
+	"errors"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/seoyhaein/tori/config"
+	dbUtils "github.com/seoyhaein/tori/db"
+	"github.com/seoyhaein/tori/service"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestSyncFoldersCoalesced rule.json 을 named pipe 로 만들어서 첫 sync 가 rule.json 을 읽는 중에 멈춰 있게 함.
+// 그동안 들어온 sync 요청들이 새로 실행되면 아무도 쓰지 않는 pipe 에서 영원히 멈추므로, 한 번의 쓰기로
+// 모든 요청이 끝나야 하나의 실행으로 합쳐진 것임.
+func TestSyncFoldersCoalesced(t *testing.T) {
+	rootDir := t.TempDir()
+	folder := filepath.Join(rootDir, "run1")
+	if err := os.Mkdir(folder, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	for _, name := range []string{"s1_R1.fastq", "s1_R2.fastq"} {
+		if err := os.WriteFile(filepath.Join(folder, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+	rulePath := filepath.Join(folder, "rule.json")
+	if err := syscall.Mkfifo(rulePath, 0o644); err != nil {
+		t.Skipf("mkfifo not supported: %v", err)
+	}
+
+	db, err := dbUtils.ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB failed: %v", err)
+	}
+	defer db.Close()
+	if err := dbUtils.InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase failed: %v", err)
+	}
+	core := newCore(t, db, &config.Config{
+		RootDir:         rootDir,
+		FilesExclusions: []string{"*.json", "invalid_files", "*.csv", "*.pb"},
+	})
+
+	const callers = 8
+	var wg sync.WaitGroup
+	results := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			updated, err := core.SyncFoldersWithOptions(context.Background(), dbUtils.SyncOptions{Force: true})
+			if err == nil && !updated {
+				err = errors.New("expected updated=true")
+			}
+			results <- err
+		}()
+	}
+
+	// 이미 끝난 요청을 기다리던 쪽이 취소해도 실행 중인 sync 에는 영향이 없어야 함.
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancelledErr := make(chan error, 1)
+	go func() {
+		_, err := core.SyncFoldersWithOptions(cancelled, dbUtils.SyncOptions{Force: true})
+		cancelledErr <- err
+	}()
+
+	// 첫 sync 는 pipe 에서 멈춰 있으므로, 모든 요청이 그 실행에 합류한 것을 확인한 뒤 취소하고 rule.json 을 한 번만 씀.
+	deadline := time.Now().Add(10 * time.Second)
+	for core.SyncWaiters() != callers+1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d waiters, got %d", callers+1, core.SyncWaiters())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-cancelledErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled for cancelled caller, got %v", err)
+	}
+	rule := `{"version":"1","delimiter":["_",".fastq"],"header":["R1","R2"],` +
+		`"rowRules":{"matchParts":[0]},"columnRules":{"matchParts":[1]},"sizeRules":{"minSize":0,"maxSize":1000}}`
+	pipe, err := os.OpenFile(rulePath, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open pipe failed: %v", err)
+	}
+	if _, err := pipe.WriteString(rule); err != nil {
+		t.Fatalf("write pipe failed: %v", err)
+	}
+	if err := pipe.Close(); err != nil {
+		t.Fatalf("close pipe failed: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		// 남아 있는 sync 가 pipe 에서 멈춰 있으므로 풀어 주고 실패 처리함.
+		if p, err := os.OpenFile(rulePath, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+			_ = p.Close()
+		}
+		t.Fatal("sync requests were not coalesced into a single run")
+	}
+	close(results)
+	for err := range results {
+		if err != nil {
+			t.Errorf("sync failed: %v", err)
+		}
+	}
+	if _, err := service.LoadDataBlock(filepath.Join(rootDir, "datablock.pb")); err != nil {
+		t.Errorf("datablock.pb is not readable after sync: %v", err)
+	}
+}
+
+// TestSyncHoldsFileLock sync 가 실행되는 동안 datablock.pb.lock 에 flock 이 걸려 있어서 다른 프로세스가 같은 RootDir 을
+// 동시에 sync 하지 못하는지 확인함. rule.json 을 named pipe 로 만들어서, 쓰기용으로 열리는 순간 sync 가 그것을 읽는 중임을 앎.
+func TestSyncHoldsFileLock(t *testing.T) {
+	rootDir := t.TempDir()
+	folder := filepath.Join(rootDir, "run1")
+	if err := os.Mkdir(folder, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	for _, name := range []string{"s1_R1.fastq", "s1_R2.fastq"} {
+		if err := os.WriteFile(filepath.Join(folder, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+	rulePath := filepath.Join(folder, "rule.json")
+	if err := syscall.Mkfifo(rulePath, 0o644); err != nil {
+		t.Skipf("mkfifo not supported: %v", err)
+	}
+	db, err := dbUtils.ConnectDB("sqlite3", filepath.Join(t.TempDir(), "file_monitor.db"), true)
+	if err != nil {
+		t.Fatalf("ConnectDB failed: %v", err)
+	}
+	defer db.Close()
+	if err := dbUtils.InitializeDatabase(db); err != nil {
+		t.Fatalf("InitializeDatabase failed: %v", err)
+	}
+	core := newCore(t, db, &config.Config{
+		RootDir:         rootDir,
+		FilesExclusions: []string{"*.json", "invalid_files", "*.csv", "*.pb"},
+	})
+
+	syncErr := make(chan error, 1)
+	go func() {
+		_, err := core.SyncFoldersWithOptions(context.Background(), dbUtils.SyncOptions{Force: true})
+		syncErr <- err
+	}()
+	pipe, err := os.OpenFile(rulePath, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open pipe failed: %v", err)
+	}
+
+	tryLock := func() error {
+		f, err := os.Open(filepath.Join(rootDir, "datablock.pb.lock"))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	}
+	if err := tryLock(); !errors.Is(err, syscall.EWOULDBLOCK) {
+		t.Errorf("expected the sync lock to be held during sync, got %v", err)
+	}
+
+	rule := `{"version":"1","delimiter":["_",".fastq"],"header":["R1","R2"],` +
+		`"rowRules":{"matchParts":[0]},"columnRules":{"matchParts":[1]},"sizeRules":{"minSize":0,"maxSize":1000}}`
+	if _, err := pipe.WriteString(rule); err != nil {
+		t.Fatalf("write pipe failed: %v", err)
+	}
+	if err := pipe.Close(); err != nil {
+		t.Fatalf("close pipe failed: %v", err)
+	}
+	if err := <-syncErr; err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if err := tryLock(); err != nil {
+		t.Errorf("expected the sync lock to be released after sync, got %v", err)
+	}
+}
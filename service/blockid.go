@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"github.com/seoyhaein/tori/blockid"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/proto"
+	"path/filepath"
+	"strings"
+)
+
+// DecodeBlockID 클라이언트가 보낸 block ID 를 디스크의 폴더 경로(datablock.pb 의 block_id)로 되돌림.
+// raw 모드에서는 UI 용 DataBlock 이 내보내는 RootDir 기준 상대 경로도 받음.
+func (s *DataBlockCliService) DecodeBlockID(id string) (string, error) {
+	folder, err := s.ids.Decode(id)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := s.ids.(blockid.Raw); !ok || filepath.IsAbs(folder) {
+		return folder, nil
+	}
+	rel := filepath.Clean(filepath.FromSlash(folder))
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: outside the root directory", blockid.ErrInvalidID)
+	}
+	return filepath.Join(filepath.Clean(s.cfg.RootDir), rel), nil
+}
+
+// EncodeBlockID 폴더 경로를 클라이언트에게 보여줄 block ID 로 바꿈.
+func (s *DataBlockCliService) EncodeBlockID(folderPath string) (string, error) {
+	return s.ids.Encode(folderPath)
+}
+
+// exportDataBlock 클라이언트에게 보낼 DataBlock 을 만듦. raw 모드면 그대로, 아니면 block_id 만 바꾼 복사본.
+func (s *DataBlockCliService) exportDataBlock(dataBlock *pb.DataBlock) (*pb.DataBlock, error) {
+	if _, ok := s.ids.(blockid.Raw); ok || dataBlock == nil {
+		return dataBlock, nil
+	}
+	out := proto.Clone(dataBlock).(*pb.DataBlock)
+	for _, fb := range out.Blocks {
+		id, err := s.ids.Encode(fb.GetBlockId())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode block id: %w", err)
+		}
+		fb.BlockId = id
+	}
+	return out, nil
+}
+
+// exportDelta delta 응답의 block_id 들을 클라이언트용 ID 로 바꿈.
+func (s *DataBlockCliService) exportDelta(delta *pb.GetDataBlockDeltaResponse) (*pb.GetDataBlockDeltaResponse, error) {
+	if _, ok := s.ids.(blockid.Raw); ok || delta == nil {
+		return delta, nil
+	}
+	out := proto.Clone(delta).(*pb.GetDataBlockDeltaResponse)
+	var err error
+	if out.Snapshot, err = s.exportDataBlock(out.Snapshot); err != nil {
+		return nil, err
+	}
+	encode := func(id *string) {
+		if err == nil {
+			*id, err = s.ids.Encode(*id)
+		}
+	}
+	for _, fb := range out.AddedBlocks {
+		encode(&fb.BlockId)
+	}
+	for _, fbDelta := range out.ModifiedBlocks {
+		encode(&fbDelta.BlockId)
+	}
+	for i := range out.RemovedBlockIds {
+		encode(&out.RemovedBlockIds[i])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode block id: %w", err)
+	}
+	return out, nil
+}
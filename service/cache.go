@@ -0,0 +1,97 @@
+package service
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/seoyhaein/tori/protofile"
+	pb "github.com/seoyhaein/tori/protos"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	dataBlockSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "tori_datablock_size_bytes",
+		Help: "Size of the datablock.pb currently served.",
+	})
+	dataBlockBlocks = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "tori_datablock_blocks",
+		Help: "FileBlocks in the DataBlock currently served.",
+	})
+	dataBlockRows = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "tori_datablock_rows",
+		Help: "Rows across all FileBlocks in the DataBlock currently served.",
+	})
+)
+
+// cachedDataBlock 메모리에 올려 둔 DataBlock 과, 그것을 읽었을 때의 datablock.pb 상태.
+// 한 번 만들어지면 바뀌지 않으므로 여러 goroutine 이 lock 없이 함께 읽을 수 있음.
+type cachedDataBlock struct {
+	dataBlock *pb.DataBlock
+	modTime   time.Time
+	size      int64
+	hash      [sha256.Size]byte
+}
+
+// dataBlockCache 는 파싱한 DataBlock 을 atomic 포인터로 들고 있음.
+// 읽는 쪽은 stat 한 번으로 파일이 그대로인지 확인한 뒤 캐시를 바로 쓰고,
+// 다시 읽을 때는 새 cachedDataBlock 을 만들어서 통째로 교체함.
+type dataBlockCache struct {
+	current atomic.Pointer[cachedDataBlock]
+}
+
+// fresh 캐시가 info 의 수정 시각·크기와 같으면 캐시된 항목을 반환함.
+func (c *dataBlockCache) fresh(info os.FileInfo) *cachedDataBlock {
+	cur := c.current.Load()
+	if cur == nil || !cur.modTime.Equal(info.ModTime()) || cur.size != info.Size() {
+		return nil
+	}
+	return cur
+}
+
+// reload filePath 를 읽어서 캐시를 교체함. 내용 hash 가 캐시와 같으면 파싱하지 않고 기존 DataBlock 을 그대로 씀.
+// changed 는 DataBlock 이 새로 파싱되었는지를 나타냄.
+func (c *dataBlockCache) reload(filePath string) (entry *cachedDataBlock, changed bool, err error) {
+	// 읽는 도중에 파일이 바뀌어도 stat 이 더 오래된 값이면 다음 요청에서 다시 읽게 되므로, stat 을 먼저 함.
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, false, err
+	}
+	next := &cachedDataBlock{modTime: info.ModTime(), size: info.Size(), hash: sha256.Sum256(data)}
+
+	prev := c.current.Load()
+	if prev != nil && prev.hash == next.hash {
+		// touch 등으로 수정 시각만 바뀐 경우.
+		next.dataBlock = prev.dataBlock
+	} else {
+		dataBlock := &pb.DataBlock{}
+		// 압축 여부는 파일 내용으로 판단하므로 설정을 바꾼 뒤 sync 전의 파일도 그대로 읽힘.
+		if err := protofile.Unmarshal(data, dataBlock); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal datablock: %w", err)
+		}
+		next.dataBlock = dataBlock
+		changed = true
+	}
+	// 그 사이에 다른 쪽(sync 직후의 reload 등)이 더 새로 읽은 값을 넣었으면 덮어쓰지 않음.
+	if c.current.CompareAndSwap(prev, next) {
+		recordDataBlock(next)
+	}
+	return next, changed, nil
+}
+
+func recordDataBlock(entry *cachedDataBlock) {
+	rows := 0
+	for _, fb := range entry.dataBlock.GetBlocks() {
+		rows += len(fb.GetRows())
+	}
+	dataBlockSize.Set(float64(entry.size))
+	dataBlockBlocks.Set(float64(len(entry.dataBlock.GetBlocks())))
+	dataBlockRows.Set(float64(rows))
+}
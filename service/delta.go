@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/seoyhaein/tori/config"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// dataBlockHistory 는 delta 계산을 위해 최근 DataBlock 버전들을 updated_at 기준으로 보관함.
+// 가득 차면 가장 오래된 버전부터 버림.
+type dataBlockHistory struct {
+	mu       sync.Mutex
+	limit    int
+	versions []*pb.DataBlock // 오래된 순서
+}
+
+func newDataBlockHistory(limit int) *dataBlockHistory {
+	if limit <= 0 {
+		limit = config.DefaultDeltaHistory
+	}
+	return &dataBlockHistory{limit: limit}
+}
+
+// add dataBlock 을 최신 버전으로 기록함. 이미 같은 updated_at 이 있으면 무시함.
+func (h *dataBlockHistory) add(dataBlock *pb.DataBlock) {
+	if dataBlock.GetUpdatedAt() == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, v := range h.versions {
+		if proto.Equal(v.GetUpdatedAt(), dataBlock.GetUpdatedAt()) {
+			return
+		}
+	}
+	h.versions = append(h.versions, dataBlock)
+	if len(h.versions) > h.limit {
+		h.versions = slices.Delete(h.versions, 0, len(h.versions)-h.limit)
+	}
+}
+
+// get updatedAt 에 해당하는 버전을 반환함. 없으면 nil.
+func (h *dataBlockHistory) get(updatedAt *timestamppb.Timestamp) *pb.DataBlock {
+	return h.find(func(v *pb.DataBlock) bool { return proto.Equal(v.GetUpdatedAt(), updatedAt) })
+}
+
+// base 클라이언트 버전 v 에 해당하는 버전을 반환함. content_hash, generation, updated_at 중 채워진 값을 이 순서로 써서 찾고, 없으면 nil.
+// updated_at 은 generation 을 보내지 않는 이전 클라이언트나, generation 이 매겨지기 전의 버전을 찾을 때 씀.
+func (h *dataBlockHistory) base(v ClientVersion) *pb.DataBlock {
+	if v.ContentHash != "" {
+		if d := h.find(func(d *pb.DataBlock) bool { return d.GetContentHash() == v.ContentHash }); d != nil {
+			return d
+		}
+	}
+	if v.Generation != 0 {
+		if d := h.find(func(d *pb.DataBlock) bool { return d.GetGeneration() == v.Generation }); d != nil {
+			return d
+		}
+	}
+	if v.UpdatedAt != nil {
+		return h.get(v.UpdatedAt)
+	}
+	return nil
+}
+
+// find match 를 만족하는 가장 최근 버전을 반환함. 없으면 nil.
+func (h *dataBlockHistory) find(match func(*pb.DataBlock) bool) *pb.DataBlock {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := len(h.versions) - 1; i >= 0; i-- {
+		if match(h.versions[i]) {
+			return h.versions[i]
+		}
+	}
+	return nil
+}
+
+// GetDataBlockDelta 클라이언트가 가진 버전 v 이후로 바뀐 FileBlock 과 행만 돌려줌.
+// 서버가 클라이언트 버전을 기억하지 못하거나, delta 가 전체 DataBlock 보다 크면 full snapshot 을 돌려줌.
+func (s *DataBlockCliService) GetDataBlockDelta(ctx context.Context, v ClientVersion) (*pb.GetDataBlockDeltaResponse, error) {
+	current, err := s.loadDataBlock()
+	if err != nil {
+		return nil, err
+	}
+	if current.UpdatedAt == nil {
+		return nil, fmt.Errorf("server datablock is missing UpdatedAt field")
+	}
+
+	if v.matches(current) {
+		return &pb.GetDataBlockDeltaResponse{
+			UpdatedAt:   current.UpdatedAt,
+			NoUpdate:    true,
+			Generation:  current.GetGeneration(),
+			ContentHash: current.GetContentHash(),
+		}, nil
+	}
+
+	base := s.history.base(v)
+	if base == nil {
+		if v != (ClientVersion{}) {
+			logger.Infof("no history for client version (content_hash %q, generation %d, updated_at %v); sending full snapshot",
+				v.ContentHash, v.Generation, v.UpdatedAt.AsTime())
+		}
+		return fullSnapshot(current), nil
+	}
+
+	delta := DiffDataBlocks(base, current)
+	if proto.Size(delta) >= proto.Size(current) {
+		return fullSnapshot(current), nil
+	}
+	return delta, nil
+}
+
+func fullSnapshot(current *pb.DataBlock) *pb.GetDataBlockDeltaResponse {
+	return &pb.GetDataBlockDeltaResponse{
+		UpdatedAt:    current.UpdatedAt,
+		FullSnapshot: true,
+		Snapshot:     current,
+		Generation:   current.GetGeneration(),
+		ContentHash:  current.GetContentHash(),
+	}
+}
+
+// DiffDataBlocks old 에서 new 로 가기 위해 필요한 변경 사항을 계산함.
+// FileBlock 은 block_id 로, 행은 row_number 로 구분함.
+func DiffDataBlocks(old, new *pb.DataBlock) *pb.GetDataBlockDeltaResponse {
+	delta := &pb.GetDataBlockDeltaResponse{UpdatedAt: new.GetUpdatedAt(), Generation: new.GetGeneration(), ContentHash: new.GetContentHash()}
+
+	oldBlocks := make(map[string]*pb.FileBlock, len(old.GetBlocks()))
+	for _, fb := range old.GetBlocks() {
+		oldBlocks[fb.GetBlockId()] = fb
+	}
+	newIDs := make(map[string]struct{}, len(new.GetBlocks()))
+
+	for _, fb := range new.GetBlocks() {
+		newIDs[fb.GetBlockId()] = struct{}{}
+		oldFb, ok := oldBlocks[fb.GetBlockId()]
+		if !ok {
+			delta.AddedBlocks = append(delta.AddedBlocks, fb)
+			continue
+		}
+		if fbDelta := diffFileBlocks(oldFb, fb); fbDelta != nil {
+			delta.ModifiedBlocks = append(delta.ModifiedBlocks, fbDelta)
+		}
+	}
+	for _, fb := range old.GetBlocks() {
+		if _, ok := newIDs[fb.GetBlockId()]; !ok {
+			delta.RemovedBlockIds = append(delta.RemovedBlockIds, fb.GetBlockId())
+		}
+	}
+	return delta
+}
+
+// diffFileBlocks 두 FileBlock 의 행 차이를 계산함. 바뀐 것이 없으면 nil.
+func diffFileBlocks(old, new *pb.FileBlock) *pb.FileBlockDelta {
+	oldRows := make(map[int32]*pb.Row, len(old.GetRows()))
+	for _, r := range old.GetRows() {
+		oldRows[r.GetRowNumber()] = r
+	}
+	newRows := make(map[int32]struct{}, len(new.GetRows()))
+
+	fbDelta := &pb.FileBlockDelta{BlockId: new.GetBlockId(), ColumnHeaders: new.GetColumnHeaders()}
+	for _, r := range new.GetRows() {
+		newRows[r.GetRowNumber()] = struct{}{}
+		if oldRow, ok := oldRows[r.GetRowNumber()]; !ok || !proto.Equal(oldRow, r) {
+			fbDelta.UpsertedRows = append(fbDelta.UpsertedRows, r)
+		}
+	}
+	for _, r := range old.GetRows() {
+		if _, ok := newRows[r.GetRowNumber()]; !ok {
+			fbDelta.RemovedRowNumbers = append(fbDelta.RemovedRowNumbers, r.GetRowNumber())
+		}
+	}
+
+	if len(fbDelta.UpsertedRows) == 0 && len(fbDelta.RemovedRowNumbers) == 0 &&
+		slices.Equal(old.GetColumnHeaders(), new.GetColumnHeaders()) {
+		return nil
+	}
+	return fbDelta
+}
+
+// ApplyDataBlockDelta 클라이언트 쪽에서 base 에 delta 를 적용해서 최신 DataBlock 을 만듦. base 는 바뀌지 않음.
+// 새로 추가된 FileBlock 은 뒤에 붙고, 각 FileBlock 의 행은 row_number 순으로 정렬됨.
+func ApplyDataBlockDelta(base *pb.DataBlock, delta *pb.GetDataBlockDeltaResponse) (*pb.DataBlock, error) {
+	if delta.GetNoUpdate() {
+		return base, nil
+	}
+	if delta.GetFullSnapshot() {
+		if delta.GetSnapshot() == nil {
+			return nil, fmt.Errorf("full snapshot delta has no snapshot")
+		}
+		return delta.GetSnapshot(), nil
+	}
+	if base == nil {
+		return nil, fmt.Errorf("cannot apply delta without a base datablock")
+	}
+
+	result := proto.Clone(base).(*pb.DataBlock)
+	result.UpdatedAt = delta.GetUpdatedAt()
+	result.Generation = delta.GetGeneration()
+	result.ContentHash = delta.GetContentHash()
+
+	removed := make(map[string]struct{}, len(delta.GetRemovedBlockIds()))
+	for _, id := range delta.GetRemovedBlockIds() {
+		removed[id] = struct{}{}
+	}
+	result.Blocks = slices.DeleteFunc(result.Blocks, func(fb *pb.FileBlock) bool {
+		_, ok := removed[fb.GetBlockId()]
+		return ok
+	})
+
+	index := make(map[string]*pb.FileBlock, len(result.Blocks))
+	for _, fb := range result.Blocks {
+		index[fb.GetBlockId()] = fb
+	}
+	for _, fbDelta := range delta.GetModifiedBlocks() {
+		fb, ok := index[fbDelta.GetBlockId()]
+		if !ok {
+			return nil, fmt.Errorf("delta modifies unknown block %q", fbDelta.GetBlockId())
+		}
+		applyFileBlockDelta(fb, fbDelta)
+	}
+	for _, fb := range delta.GetAddedBlocks() {
+		result.Blocks = append(result.Blocks, proto.Clone(fb).(*pb.FileBlock))
+	}
+	return result, nil
+}
+
+func applyFileBlockDelta(fb *pb.FileBlock, fbDelta *pb.FileBlockDelta) {
+	fb.ColumnHeaders = fbDelta.GetColumnHeaders()
+
+	rows := make(map[int32]*pb.Row, len(fb.GetRows()))
+	for _, r := range fb.GetRows() {
+		rows[r.GetRowNumber()] = r
+	}
+	for _, n := range fbDelta.GetRemovedRowNumbers() {
+		delete(rows, n)
+	}
+	for _, r := range fbDelta.GetUpsertedRows() {
+		rows[r.GetRowNumber()] = proto.Clone(r).(*pb.Row)
+	}
+
+	fb.Rows = make([]*pb.Row, 0, len(rows))
+	for _, r := range rows {
+		fb.Rows = append(fb.Rows, r)
+	}
+	sort.Slice(fb.Rows, func(i, j int) bool { return fb.Rows[i].GetRowNumber() < fb.Rows[j].GetRowNumber() })
+}
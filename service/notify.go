@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/proto"
+	"os"
+	"sync"
+	"time"
+)
+
+// dataBlockHub 는 새로 만들어진 DataBlock 을 구독자(WatchDataBlock 스트림 등)에게 전달함.
+// 구독자마다 크기 1 인 채널을 두고, 아직 가져가지 않은 이전 값은 최신 값으로 덮어씀.
+// DataBlock 은 매번 전체 스냅샷이므로 중간 버전을 건너뛰어도 되고, 느린 구독자가 sync 를 막지 않음.
+type dataBlockHub struct {
+	mu     sync.Mutex
+	subs   map[chan *pb.DataBlock]struct{}
+	last   *pb.DataBlock
+	closed bool
+}
+
+func newDataBlockHub() *dataBlockHub {
+	return &dataBlockHub{subs: make(map[chan *pb.DataBlock]struct{})}
+}
+
+// subscribe 새 구독 채널과 구독 해제 함수를 반환함. hub 가 이미 닫혔으면 닫힌 채널을 반환함.
+func (h *dataBlockHub) subscribe() (<-chan *pb.DataBlock, func()) {
+	ch := make(chan *pb.DataBlock, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subs[ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if _, ok := h.subs[ch]; ok {
+				delete(h.subs, ch)
+				close(ch)
+			}
+		})
+	}
+}
+
+// publish 모든 구독자에게 dataBlock 을 전달함. 직전에 보낸 것과 UpdatedAt 이 같으면 무시함.
+func (h *dataBlockHub) publish(dataBlock *pb.DataBlock) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	if h.last != nil && proto.Equal(h.last.GetUpdatedAt(), dataBlock.GetUpdatedAt()) {
+		return false
+	}
+	h.last = dataBlock
+
+	for ch := range h.subs {
+		select {
+		case ch <- dataBlock:
+		default:
+			// 구독자가 이전 값을 아직 안 가져갔으면 버리고 최신 값으로 교체.
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- dataBlock
+		}
+	}
+	return true
+}
+
+// close 모든 구독 채널을 닫아서 스트림 핸들러들이 종료되도록 함.
+func (h *dataBlockHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+func (h *dataBlockHub) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Subscribe DataBlock 이 갱신될 때마다 새 DataBlock 을 받는 채널을 반환함.
+// 사용이 끝나면 반드시 반환된 함수를 호출해서 구독을 해제해야 함.
+func (s *DataBlockCliService) Subscribe() (<-chan *pb.DataBlock, func()) {
+	return s.hub.subscribe()
+}
+
+// Subscribers 현재 구독 중인 수를 반환함.
+func (s *DataBlockCliService) Subscribers() int {
+	return s.hub.count()
+}
+
+// CloseSubscribers 모든 구독을 끊음. 서버 종료 시 스트림이 GracefulStop 을 막지 않도록 먼저 호출함.
+func (s *DataBlockCliService) CloseSubscribers() {
+	s.hub.close()
+}
+
+// NotifyDataBlockChanged 디스크의 datablock.pb 를 다시 읽어서 구독자들에게 전달함.
+func (s *DataBlockCliService) NotifyDataBlockChanged(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	dataBlock, err := s.loadDataBlock()
+	if err != nil {
+		return err
+	}
+	if s.hub.publish(dataBlock) {
+		logger.Infof("DataBlock (updated_at: %v) published to %d subscribers", dataBlock.GetUpdatedAt().AsTime(), s.hub.count())
+	}
+	return nil
+}
+
+// WatchDataBlockFile interval 마다 datablock.pb 의 수정 시각과 크기를 확인해서, 바뀌었으면 구독자들에게 알림.
+// 다른 프로세스(tori-admin sync 등)에서 실행한 sync 도 감지하기 위함. ctx 가 취소될 때까지 블록됨.
+func (s *DataBlockCliService) WatchDataBlockFile(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastMod time.Time
+	var lastSize int64 = -1
+	for {
+		info, err := os.Stat(s.dataBlockPath())
+		if err == nil && (!info.ModTime().Equal(lastMod) || info.Size() != lastSize) {
+			if nErr := s.NotifyDataBlockChanged(ctx); nErr != nil {
+				logger.Warnf("failed to notify datablock change: %v", nErr)
+			} else {
+				lastMod, lastSize = info.ModTime(), info.Size()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	pb "github.com/seoyhaein/tori/protos"
+	"github.com/seoyhaein/tori/rules"
+	"strings"
+	"text/template"
+)
+
+// ErrInvalidTemplate 템플릿 문법이 잘못되었거나 실행 중 함수 사용이 잘못되었을 때 반환함.
+var ErrInvalidTemplate = errors.New("invalid script template")
+
+// RenderError 템플릿은 올바르지만 일부 자리표시자를 채우지 못했을 때 반환함. 실패한 자리표시자를 모두 담음.
+type RenderError struct {
+	Problems []string
+}
+
+func (e *RenderError) Error() string {
+	return fmt.Sprintf("failed to resolve %d placeholder(s): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// templateBlock 템플릿 안에서 fileBlock "X" 가 돌려주는 값.
+type templateBlock struct {
+	name   string
+	folder string
+	fb     *pb.FileBlock
+}
+
+// TemplateRow 템플릿 안에서 row/rowKey/rows 가 돌려주는 행. {{ $r.Number }} 처럼 행 번호를 쓸 수 있음.
+type TemplateRow struct {
+	Number int32
+	block  *templateBlock
+	row    *pb.Row
+}
+
+// TemplatePath 템플릿 안에서 col 이 돌려주는 파일 경로. 그대로 출력하면 shell 에서 한 단어가 되도록 shellQuote 로 감싸서 나오고,
+// raw 를 거치면 감싸지 않은 경로가 됨.
+type TemplatePath string
+
+func (p TemplatePath) String() string {
+	return shellQuote(string(p))
+}
+
+// shellQuote s 를 POSIX shell 에서 한 단어로 읽히도록 작은따옴표로 감쌈. 감쌀 필요가 없는 문자만 있으면 그대로 반환함.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool { return !shellSafe(r) }) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func shellSafe(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-./:@%+=,", r)
+}
+
+// RenderScript user script 템플릿의 자리표시자를 현재 DataBlock 의 실제 파일 경로로 채움.
+//
+//	{{ fileBlock "run1/sample" | row 3 | col "R1" }}       행 번호로 지정
+//	{{ fileBlock "run1/sample" | rowKey "s1" | col "R1" }} row key 로 지정
+//	{{ range fileBlock "run1/sample" | rows }}{{ . | col "R1" }} {{ end }}
+//
+// 경로에 공백이나 $, ; 같은 문자가 있어도 script 가 깨지지 않도록 col 의 경로는 shell 용으로 따옴표를 쳐서 출력함.
+// 따옴표 없이 쓰려면 {{ ... | col "R1" | raw }} 처럼 raw 를 거치고, 경로로 새 문자열을 만들었으면 shellquote 로 다시 감쌈.
+//
+//	{{ fileBlock "run1/sample" | row 3 | col "R1" | raw | printf "%s.bam" | shellquote }}
+//
+// text/template 에서 block 은 예약어이므로 함수 이름은 fileBlock 임. fileBlock 의 이름은 클라이언트용 block ID 이고,
+// raw 모드에서는 RootDir 기준 상대 경로나 절대 경로도 됨. 채우지 못한 자리표시자는
+// 모두 모아서 *RenderError 로 반환하고, 템플릿 자체가 잘못되었으면 ErrInvalidTemplate 을 감싼 에러를 반환함.
+func (s *DataBlockCliService) RenderScript(ctx context.Context, text string, relative bool) (string, error) {
+	dataBlock, err := s.loadDataBlock()
+	if err != nil {
+		return "", err
+	}
+	blocks := make(map[string]*pb.FileBlock, len(dataBlock.GetBlocks()))
+	for _, fb := range dataBlock.GetBlocks() {
+		blocks[fb.GetBlockId()] = fb
+	}
+	ruleSets := make(map[string]rules.RuleSet)
+
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	// 찾지 못한 block/행은 nil 을 넘겨서 실행을 계속하고, 마지막에 문제를 한꺼번에 보고함.
+	funcs := template.FuncMap{
+		"fileBlock": func(name string) *templateBlock {
+			b := s.lookupTemplateBlock(name, blocks)
+			if b == nil {
+				fail("block %q not found", name)
+			}
+			return b
+		},
+		"row": func(n int, b *templateBlock) *TemplateRow {
+			if b == nil {
+				return nil
+			}
+			row := findRowByNumber(b.fb, int32(n))
+			if row == nil {
+				fail("block %q: row %d not found", b.name, n)
+				return nil
+			}
+			return &TemplateRow{Number: row.GetRowNumber(), block: b, row: row}
+		},
+		"rowKey": func(key string, b *templateBlock) *TemplateRow {
+			if b == nil {
+				return nil
+			}
+			row, err := findRowByKey(b.fb, b.folder, key, ruleSets)
+			if err != nil {
+				fail("block %q: %v", b.name, err)
+				return nil
+			}
+			return &TemplateRow{Number: row.GetRowNumber(), block: b, row: row}
+		},
+		"rows": func(b *templateBlock) []*TemplateRow {
+			if b == nil {
+				return nil
+			}
+			rows := make([]*TemplateRow, 0, len(b.fb.GetRows()))
+			for _, row := range b.fb.GetRows() {
+				rows = append(rows, &TemplateRow{Number: row.GetRowNumber(), block: b, row: row})
+			}
+			return rows
+		},
+		"col": func(column string, r *TemplateRow) TemplatePath {
+			if r == nil {
+				return ""
+			}
+			p, err := s.cellPath(r.block.folder, r.row, column, relative)
+			if err != nil {
+				fail("block %q: %v", r.block.name, err)
+				return ""
+			}
+			return TemplatePath(p)
+		},
+		"raw": func(p TemplatePath) string {
+			return string(p)
+		},
+		"shellquote": shellQuote,
+	}
+
+	tmpl, err := template.New("script").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if len(problems) > 0 {
+		return "", &RenderError{Problems: problems}
+	}
+	return buf.String(), nil
+}
+
+// lookupTemplateBlock 클라이언트용 block ID 로 block 을 찾음. raw 모드에서만 RootDir 기준 상대 경로와 절대 경로를
+// 받고, opaque 모드에서는 경로로 찾을 수 없음.
+func (s *DataBlockCliService) lookupTemplateBlock(name string, blocks map[string]*pb.FileBlock) *templateBlock {
+	folder, err := s.DecodeBlockID(name)
+	if err != nil {
+		return nil
+	}
+	fb, ok := blocks[folder]
+	if !ok {
+		return nil
+	}
+	return &templateBlock{name: name, folder: folder, fb: fb}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/seoyhaein/tori/checksum"
+	dbUtils "github.com/seoyhaein/tori/db"
+	pb "github.com/seoyhaein/tori/protos"
+	"github.com/seoyhaein/tori/rules"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResolvePaths 클라이언트가 선택한 (block_id, 행, 컬럼)들을 실제 파일 경로로 바꿈.
+// 선택마다 결과를 따로 돌려주며, 셀이 없거나 파일이 디스크에서 사라졌으면 해당 결과의 Error 에 이유를 담음.
+// block_id 는 클라이언트가 받은 ID(opaque 모드면 암호화된 토큰)를 그대로 받음.
+func (s *DataBlockCliService) ResolvePaths(ctx context.Context, selections []*pb.PathSelection, relative bool) ([]*pb.ResolvedPath, error) {
+	dataBlock, err := s.loadDataBlock()
+	if err != nil {
+		return nil, err
+	}
+	blocks := make(map[string]*pb.FileBlock, len(dataBlock.GetBlocks()))
+	for _, fb := range dataBlock.GetBlocks() {
+		blocks[fb.GetBlockId()] = fb
+	}
+	// row key 로 찾을 때 필요한 rule.json 은 요청 안에서 폴더마다 한 번만 읽음.
+	ruleSets := make(map[string]rules.RuleSet)
+
+	results := make([]*pb.ResolvedPath, 0, len(selections))
+	for _, sel := range selections {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result := &pb.ResolvedPath{Selection: sel}
+		if p, err := s.resolvePath(sel, blocks, ruleSets, relative); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Path = p
+			fullPath := p
+			if relative {
+				fullPath = filepath.Join(filepath.Clean(s.cfg.RootDir), p)
+			}
+			result.Checksum = s.fileChecksum(ctx, fullPath)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *DataBlockCliService) resolvePath(sel *pb.PathSelection, blocks map[string]*pb.FileBlock, ruleSets map[string]rules.RuleSet, relative bool) (string, error) {
+	folder, err := s.DecodeBlockID(sel.GetBlockId())
+	if err != nil {
+		return "", err
+	}
+	fb, ok := blocks[folder]
+	if !ok {
+		return "", fmt.Errorf("block %q not found", sel.GetBlockId())
+	}
+
+	var row *pb.Row
+	switch r := sel.GetRow().(type) {
+	case *pb.PathSelection_RowNumber:
+		row = findRowByNumber(fb, r.RowNumber)
+		if row == nil {
+			return "", fmt.Errorf("row %d not found", r.RowNumber)
+		}
+	case *pb.PathSelection_RowKey:
+		if row, err = findRowByKey(fb, folder, r.RowKey, ruleSets); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("row_number or row_key is required")
+	}
+
+	return s.cellPath(folder, row, sel.GetColumn(), relative)
+}
+
+// cellPath folder 의 row 에서 column 셀이 가리키는 파일 경로를 만들고, 파일이 아직 있는지 확인함.
+func (s *DataBlockCliService) cellPath(folder string, row *pb.Row, column string, relative bool) (string, error) {
+	fileName, ok := row.GetCells()[column]
+	if !ok || fileName == "" {
+		return "", fmt.Errorf("column %q is empty in row %d", column, row.GetRowNumber())
+	}
+	// 셀에는 파일 이름만 들어 있어야 함. 다른 폴더를 가리키는 값은 거부함.
+	if filepath.Base(fileName) != fileName {
+		return "", fmt.Errorf("invalid file name %q in cell", fileName)
+	}
+
+	fullPath := filepath.Join(folder, fileName)
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file %s no longer exists", fileName)
+		}
+		return "", fmt.Errorf("failed to stat %s: %w", fileName, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", fileName)
+	}
+
+	if !relative {
+		return fullPath, nil
+	}
+	rel, err := filepath.Rel(filepath.Clean(s.cfg.RootDir), fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file %s is outside the root directory", fileName)
+	}
+	return rel, nil
+}
+
+// fileChecksum 마지막 sync 때 DB 에 저장한 fullPath 의 checksum. checksum 을 쓰지 않거나, 저장된 값이 없거나,
+// 그 뒤로 파일이 수정되어 값을 믿을 수 없으면 빈 문자열.
+func (s *DataBlockCliService) fileChecksum(ctx context.Context, fullPath string) string {
+	if !checksum.Enabled(s.cfg.Checksum) || s.db == nil {
+		return ""
+	}
+	sum, modTime, err := dbUtils.GetFileChecksum(ctx, s.db, filepath.Dir(fullPath), filepath.Base(fullPath))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("failed to read checksum of %s: %v", fullPath, err)
+		}
+		return ""
+	}
+	if checksum.Algorithm(sum) != s.cfg.Checksum {
+		return ""
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil || info.ModTime().UnixNano() != modTime {
+		return ""
+	}
+	return sum
+}
+
+func findRowByNumber(fb *pb.FileBlock, rowNumber int32) *pb.Row {
+	for _, r := range fb.GetRows() {
+		if r.GetRowNumber() == rowNumber {
+			return r
+		}
+	}
+	return nil
+}
+
+// findRowByKey 셀의 파일 이름으로 row key 를 다시 계산해서 일치하는 행을 찾음.
+// 한 행의 파일들은 모두 같은 row key 를 가지므로 행마다 셀 하나만 보면 됨.
+// folder 의 rule.json 은 ruleSets 에 캐시해서 요청 안에서 한 번만 읽음.
+func findRowByKey(fb *pb.FileBlock, folder, rowKey string, ruleSets map[string]rules.RuleSet) (*pb.Row, error) {
+	ruleSet, ok := ruleSets[folder]
+	if !ok {
+		var err error
+		if ruleSet, err = rules.LoadRuleSetFromFile(folder); err != nil {
+			return nil, fmt.Errorf("failed to load rule set: %w", err)
+		}
+		ruleSets[folder] = ruleSet
+	}
+	for _, r := range fb.GetRows() {
+		for _, header := range fb.GetColumnHeaders() {
+			if fileName, ok := r.GetCells()[header]; ok {
+				if rules.RowKey(fileName, ruleSet) == rowKey {
+					return r, nil
+				}
+				break
+			}
+		}
+	}
+	return nil, fmt.Errorf("row key %q not found", rowKey)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/seoyhaein/tori/blockid"
+	"github.com/seoyhaein/tori/search"
+	"path/filepath"
+	"strings"
+)
+
+// Search 현재 DataBlock 에서 q 에 맞는 FileBlock 과 행만 찾음.
+// raw 모드에서 BlockIDPrefix 가 상대 경로면 RootDir 기준으로 해석함. opaque 모드에서는 경로를 받지 않고,
+// BlockIDPrefix 를 block ID 로 풀어서 그 폴더 경로로 시작하는 FileBlock 을 찾음.
+// 결과의 block_id 는 디스크와 같은 폴더 경로임.
+func (s *DataBlockCliService) Search(ctx context.Context, q search.Query) (*search.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	dataBlock, err := s.loadDataBlock()
+	if err != nil {
+		return nil, err
+	}
+	if _, raw := s.ids.(blockid.Raw); !raw && q.BlockIDPrefix != "" {
+		// opaque ID 는 일부만 잘라서는 풀 수 없으므로 완전한 block ID 만 받음.
+		folder, err := s.ids.Decode(q.BlockIDPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("%w: block_id_prefix must be a block id: %v", search.ErrInvalidQuery, err)
+		}
+		q.BlockIDPrefix = folder
+	} else if q.BlockIDPrefix != "" && !filepath.IsAbs(q.BlockIDPrefix) {
+		q.BlockIDPrefix = filepath.Clean(s.cfg.RootDir) + string(filepath.Separator) + strings.TrimPrefix(q.BlockIDPrefix, "./")
+	}
+	return search.Search(dataBlock, q)
+}
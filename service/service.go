@@ -3,78 +3,213 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	pb "github.com/seoyhaein/api-protos/gen/go/datablock/ichthys"
-	"github.com/seoyhaein/api-protos/gen/go/datablock/ichthys/service"
+	"github.com/seoyhaein/tori/blockid"
 	"github.com/seoyhaein/tori/config"
 	dbUtils "github.com/seoyhaein/tori/db"
 	globallog "github.com/seoyhaein/tori/log"
+	"github.com/seoyhaein/tori/protofile"
+	pb "github.com/seoyhaein/tori/protos"
+	"github.com/seoyhaein/tori/search"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 )
 
 var logger = globallog.Log
 
 // DataBlockCliService encapsulates core folder/database operations for CLI and gRPC.
 type DataBlockCliService struct {
-	db  *sql.DB
-	cfg *config.Config
+	db      *sql.DB
+	cfg     *config.Config
+	hub     *dataBlockHub
+	history *dataBlockHistory
+	ids     blockid.Codec
+	cache   dataBlockCache
+
+	// 동시에 들어온 sync/load 요청은 하나의 실행으로 합쳐서 결과를 공유함.
+	syncGroup singleflight.Group
+	loadGroup singleflight.Group
+	// 서로 다른 key(force 여부)의 sync 도 DB upsert 와 datablock.pb 쓰기가 섞이지 않도록 한 번에 하나만 실행함.
+	syncMu sync.Mutex
+	// 진행 중인 sync 에 합류해서 결과를 기다리는 요청 수.
+	syncWaiters atomic.Int64
 }
 
 // NewDataBlockCliService constructs a new CLI service instance.
-func NewDataBlockCliService(dbConn *sql.DB, cfg *config.Config) *DataBlockCliService {
-	return &DataBlockCliService{db: dbConn, cfg: cfg}
+// blockIds 키를 읽을 수 없으면 에러를 반환함. 키 없이 raw 로 내보내면 경로가 노출되므로 시작하지 않아야 함.
+func NewDataBlockCliService(dbConn *sql.DB, cfg *config.Config) (*DataBlockCliService, error) {
+	ids, err := blockid.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up block id codec: %w", err)
+	}
+	return &DataBlockCliService{
+		db:      dbConn,
+		cfg:     cfg,
+		hub:     newDataBlockHub(),
+		history: newDataBlockHistory(cfg.DeltaHistory),
+		ids:     ids,
+	}, nil
 }
 
-// GetDataBlock loads the DataBlock and applies timestamp-based logic.
-func (s *DataBlockCliService) GetDataBlock(ctx context.Context, updateAt *timestamppb.Timestamp) (*pb.DataBlock, error) {
-	// 서버의 데이터 블록 경로 정리
-	dataBlockPath := filepath.Clean(s.cfg.RootDir)
-	dataBlockPath = filepath.Join(dataBlockPath, "datablock.pb")
+// Config 서비스가 사용하는 설정을 반환함.
+func (s *DataBlockCliService) Config() *config.Config {
+	return s.cfg
+}
+
+// dataBlockPath 서버의 datablock.pb 경로.
+func (s *DataBlockCliService) dataBlockPath() string {
+	return filepath.Join(filepath.Clean(s.cfg.RootDir), "datablock.pb")
+}
+
+// syncLockPath 프로세스 사이에서 sync 를 한 번에 하나만 실행하기 위한 lock 파일. datablock.pb 옆에 둠.
+func (s *DataBlockCliService) syncLockPath() string {
+	return s.dataBlockPath() + ".lock"
+}
 
-	// 서버의 데이터 블록 로드
-	dataBlock, err := LoadDataBlock(dataBlockPath)
+// loadDataBlock 메모리에 캐시된 DataBlock 을 반환함. datablock.pb 의 수정 시각이나 크기가 바뀌었을 때만 다시 읽음.
+// 반환값은 모든 요청이 공유하므로 수정하면 안 됨.
+func (s *DataBlockCliService) loadDataBlock() (*pb.DataBlock, error) {
+	dataBlockPath := s.dataBlockPath()
+	info, err := os.Stat(dataBlockPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load datablock from %s: %w", dataBlockPath, err)
 	}
-
-	// 서버 데이터 블록에 UpdatedAt 필드가 없는 경우 에러 처리
-	if dataBlock.UpdatedAt == nil {
-		return nil, fmt.Errorf("server datablock is missing UpdatedAt field")
+	if cached := s.cache.fresh(info); cached != nil {
+		return cached.dataBlock, nil
 	}
+	return s.reloadDataBlock()
+}
 
-	// 클라이언트가 업데이트 타임스탬프를 제공하지 않으면, 서버 데이터를 반환
-	if updateAt == nil {
-		return dataBlock, nil
+// reloadDataBlock datablock.pb 를 디스크에서 다시 읽어 캐시를 교체하고, 새 버전이면 delta 계산을 위해 history 에 기록함.
+// 동시에 호출되면 디스크는 한 번만 읽고 결과를 공유함.
+func (s *DataBlockCliService) reloadDataBlock() (*pb.DataBlock, error) {
+	dataBlockPath := s.dataBlockPath()
+	v, err, _ := s.loadGroup.Do(dataBlockPath, func() (interface{}, error) {
+		entry, changed, err := s.cache.reload(dataBlockPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load datablock from %s: %w", dataBlockPath, err)
+		}
+		if changed {
+			s.history.add(entry.dataBlock)
+		}
+		return entry.dataBlock, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return v.(*pb.DataBlock), nil
+}
 
-	// 클라이언트와 서버 타임스탬프를 Go의 time.Time 으로 변환
-	clientTime := updateAt.AsTime()
-	serverTime := dataBlock.UpdatedAt.AsTime()
+// GetDataBlockVersion 서버의 DataBlock 과, 클라이언트 버전 v 를 그것과 비교한 결과를 반환함.
+// 결과가 VersionCurrent 여도 DataBlock 을 반환하므로, 응답에 서버의 generation 과 content_hash 를 담을 수 있음.
+func (s *DataBlockCliService) GetDataBlockVersion(ctx context.Context, v ClientVersion) (*pb.DataBlock, VersionStatus, error) {
+	dataBlock, err := s.loadDataBlock()
+	if err != nil {
+		return nil, 0, err
+	}
+	// generation, content_hash 가 없는 이전 datablock.pb 는 updated_at 으로만 비교할 수 있음.
+	if dataBlock.GetContentHash() == "" && dataBlock.UpdatedAt == nil {
+		return nil, 0, fmt.Errorf("server datablock is missing UpdatedAt field")
+	}
+	return dataBlock, s.compareVersion(ctx, dataBlock, v), nil
+}
 
-	if clientTime.Before(serverTime) {
-		// 클라이언트 데이터가 구버전이면 서버의 최신 데이터를 반환
-		return dataBlock, nil
-	} else if clientTime.Equal(serverTime) {
-		// 버전이 동일하면 업데이트할 내용이 없으므로 nil 반환
-		return nil, nil
-	} else { // clientTime.After(serverTime)
-		// 클라이언트 데이터가 서버보다 최신하면 에러 반환
-		return nil, fmt.Errorf("client datablock is newer than server version")
+// GetDataBlock updated_at 만 보내는 이전 방식. 클라이언트 버전이 최신이면 nil 을, 아니면 서버의 DataBlock 을 반환함.
+func (s *DataBlockCliService) GetDataBlock(ctx context.Context, updateAt *timestamppb.Timestamp) (*pb.DataBlock, error) {
+	dataBlock, st, err := s.GetDataBlockVersion(ctx, ClientVersion{UpdatedAt: updateAt})
+	if err != nil || st == VersionCurrent {
+		return nil, err
 	}
+	return dataBlock, nil
 }
 
 // SaveFolders 폴더 정보를 DB에 저장, TODO 이건 한번만 실행되어야 하는 메서드 임. 이름을 이러한 맥락을 고려해서 넣어 주어야 할듯
 func (s *DataBlockCliService) SaveFolders(ctx context.Context) error {
-	err := dbUtils.SaveFolders(ctx, s.db, s.cfg.RootDir, nil, s.cfg.FilesExclusions)
+	err := dbUtils.SaveFolderTree(ctx, s.db, s.cfg.RootDir, s.cfg.MaxDepth, s.cfg.FoldersExclusions, s.cfg.FilesExclusions)
 	return err
 }
 
+// SyncFolders DB 스냅샷과 폴더를 동기화하고, DataBlock 이 새로 만들어졌으면 구독자들에게 알림.
 func (s *DataBlockCliService) SyncFolders(ctx context.Context) (bool, error) {
-	// 디렉터리 경로와 파일 제외 패턴을 넘겨서 dbUtils 쪽으로 위임
-	return dbUtils.SyncFolders(ctx, s.db, s.cfg.RootDir, nil, s.cfg.FilesExclusions)
+	return s.SyncFoldersWithOptions(ctx, dbUtils.SyncOptions{})
+}
+
+// SyncFoldersWithOptions opts 에 따라 동기화함. opts.Force 면 변경 사항이 없어도 DataBlock 을 다시 생성함.
+// 이미 같은 종류의 sync 가 진행 중이면 새로 실행하지 않고 그 결과를 함께 받음.
+func (s *DataBlockCliService) SyncFoldersWithOptions(ctx context.Context, opts dbUtils.SyncOptions) (bool, error) {
+	key := "sync"
+	if opts.Force {
+		key = "sync:force"
+	}
+	// 먼저 요청한 클라이언트가 끊겨도, 결과를 기다리는 다른 클라이언트가 있으므로 sync 는 끝까지 진행함.
+	runCtx := context.WithoutCancel(ctx)
+	// DoChan 을 부르기 전에 세서, sync 에 합류한 요청이 세어지지 않은 채 결과를 기다리는 때가 없게 함.
+	s.syncWaiters.Add(1)
+	defer s.syncWaiters.Add(-1)
+	ch := s.syncGroup.DoChan(key, func() (interface{}, error) {
+		return s.runSync(runCtx, opts)
+	})
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			logger.Infof("%s request coalesced with an in-flight run", key)
+		}
+		if res.Err != nil {
+			return false, res.Err
+		}
+		return res.Val.(bool), nil
+	}
+}
+
+// SyncWaiters 진행 중인 sync 에 합류해서 결과를 기다리고 있는 요청 수를 반환함.
+func (s *DataBlockCliService) SyncWaiters() int {
+	return int(s.syncWaiters.Load())
+}
+
+func (s *DataBlockCliService) runSync(ctx context.Context, opts dbUtils.SyncOptions) (bool, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	// syncMu 는 이 프로세스 안에서만 막으므로, serve 와 watch 를 함께 띄운 경우를 위해 파일 lock 도 검.
+	unlock, err := lockSync(s.syncLockPath())
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	if opts.Compression == "" {
+		opts.Compression = s.cfg.Compression
+	}
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = s.cfg.MaxDepth
+	}
+	if opts.Checksum == "" {
+		opts.Checksum = s.cfg.Checksum
+	}
+	// 디렉터리 경로와 폴더/파일 제외 패턴을 넘겨서 dbUtils 쪽으로 위임
+	updated, err := dbUtils.SyncFolders(ctx, s.db, s.cfg.RootDir, s.cfg.FoldersExclusions, s.cfg.FilesExclusions, opts)
+	if err != nil || !updated {
+		return updated, err
+	}
+	// 수정 시각 해상도 안에서 같은 크기로 다시 쓰였을 수도 있으므로 stat 비교 없이 바로 다시 읽어서 교체함.
+	// 이미 진행 중인 load 는 sync 전의 파일을 읽었을 수 있으므로 합류하지 않음.
+	s.loadGroup.Forget(s.dataBlockPath())
+	if _, rErr := s.reloadDataBlock(); rErr != nil {
+		logger.Warnf("sync succeeded but failed to reload datablock: %v", rErr)
+	}
+	if nErr := s.NotifyDataBlockChanged(ctx); nErr != nil {
+		logger.Warnf("sync succeeded but failed to notify subscribers: %v", nErr)
+	}
+	return true, nil
 }
 
 // DataBlockServer bridges DataBlockCliService with the gRPC interface.
@@ -99,10 +234,209 @@ func NewDataBlockServer(core *DataBlockCliService) pb.DataBlockServiceServer {
 	return &emptypb.Empty{}, s.core.SaveFolders(ctx)
 }*/
 
-// GetDataBlock RPC handler.
-/*func (s *DataBlockServer) GetDataBlock(ctx context.Context, req *pb.DataBlockRequest) (*pb.DataBlock, error) {
-	return s.core.GetDataBlock(ctx, req)
-}*/
+// GetDataBlock RPC handler. 클라이언트가 보낸 버전(content_hash, generation, updated_at)과 서버의 DataBlock 을 비교해서,
+// 동일하면 no_update 를, 서버가 모르는 버전이면 full_resync 와 함께 최신 DataBlock 을, 그 외에는 최신 DataBlock 을 담아서 반환.
+func (s *DataBlockServer) GetDataBlock(ctx context.Context, req *pb.GetDataBlockRequest) (*pb.GetDataBlockResponse, error) {
+	resp, _, err := s.getDataBlock(ctx, req)
+	return resp, err
+}
+
+// getDataBlock GetDataBlock 응답과 함께, 비교에 사용한 서버의 DataBlock(export 전)을 반환함.
+func (s *DataBlockServer) getDataBlock(ctx context.Context, req *pb.GetDataBlockRequest) (*pb.GetDataBlockResponse, *pb.DataBlock, error) {
+	dataBlock, st, err := s.core.GetDataBlockVersion(ctx, ClientVersionOf(req))
+	if err != nil {
+		return nil, nil, err
+	}
+	// 클라이언트와 서버의 버전이 동일하다면 업데이트 할 필요 없음.
+	if st == VersionCurrent {
+		return &pb.GetDataBlockResponse{NoUpdate: true}, dataBlock, nil
+	}
+	if st == VersionUnknown {
+		logger.Infof("unknown client datablock version %+v; asking for a full resync", ClientVersionOf(req))
+	}
+	// block_id 는 설정에 따라 opaque ID 로 바꿔서 내보냄.
+	exported, err := s.core.exportDataBlock(dataBlock)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &pb.GetDataBlockResponse{Data: exported, FullResync: st == VersionUnknown}, dataBlock, nil
+}
+
+// StreamDataBlock RPC handler. GetDataBlock 과 같은 버전 비교를 한 뒤, header 다음에 FileBlock 을 하나씩 보냄.
+// DataBlock 전체가 gRPC 메시지 크기 제한을 넘어도 FileBlock 하나가 제한 안에 들면 받을 수 있음.
+func (s *DataBlockServer) StreamDataBlock(req *pb.GetDataBlockRequest, stream pb.DataBlockService_StreamDataBlockServer) error {
+	resp, current, err := s.getDataBlock(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	header := &pb.DataBlockHeader{
+		UpdatedAt:   current.GetUpdatedAt(),
+		Generation:  current.GetGeneration(),
+		ContentHash: current.GetContentHash(),
+		NoUpdate:    resp.GetNoUpdate(),
+		FullResync:  resp.GetFullResync(),
+	}
+	if resp.GetNoUpdate() {
+		return stream.Send(&pb.DataBlockChunk{Chunk: &pb.DataBlockChunk_Header{Header: header}})
+	}
+	dataBlock := resp.GetData()
+	header.TotalBlocks = int32(len(dataBlock.GetBlocks()))
+	if err := stream.Send(&pb.DataBlockChunk{Chunk: &pb.DataBlockChunk_Header{Header: header}}); err != nil {
+		return err
+	}
+	for _, fb := range dataBlock.GetBlocks() {
+		if err := stream.Send(&pb.DataBlockChunk{Chunk: &pb.DataBlockChunk_Block{Block: fb}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchDataBlock RPC handler. 현재 DataBlock 을 바로 보내고, 이후 DataBlock 이 갱신될 때마다 새로 보냄.
+// 클라이언트가 연결을 끊거나 서버가 종료되면 구독을 해제하고 반환함.
+func (s *DataBlockServer) WatchDataBlock(req *pb.GetDataBlockRequest, stream pb.DataBlockService_WatchDataBlockServer) error {
+	ctx := stream.Context()
+	// 구독을 먼저 걸어야 현재 DataBlock 을 보내는 사이에 일어난 sync 를 놓치지 않음.
+	updates, unsubscribe := s.core.Subscribe()
+	defer unsubscribe()
+
+	// lastSent 클라이언트가 가지고 있는 서버 DataBlock. 같은 버전의 알림은 건너뜀.
+	var lastSent *pb.DataBlock
+	if _, err := os.Stat(s.core.dataBlockPath()); err == nil {
+		resp, current, err := s.getDataBlock(ctx, req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		lastSent = current
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat datablock: %w", err)
+	}
+	// datablock.pb 가 아직 없으면 첫 sync 가 끝날 때까지 기다림.
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case dataBlock, ok := <-updates:
+			if !ok {
+				// 서버 종료로 구독이 끊김.
+				return nil
+			}
+			if lastSent != nil && sameVersion(lastSent, dataBlock) {
+				continue
+			}
+			exported, err := s.core.exportDataBlock(dataBlock)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&pb.GetDataBlockResponse{Data: exported}); err != nil {
+				return err
+			}
+			lastSent = dataBlock
+		}
+	}
+}
+
+// GetDataBlockDelta RPC handler. 클라이언트 버전은 GetDataBlock 과 같이 content_hash, generation, updated_at 으로 받음.
+func (s *DataBlockServer) GetDataBlockDelta(ctx context.Context, req *pb.GetDataBlockDeltaRequest) (*pb.GetDataBlockDeltaResponse, error) {
+	v := ClientVersion{ContentHash: req.GetCurrentContentHash(), Generation: req.GetCurrentGeneration(), UpdatedAt: req.GetCurrentUpdatedAt()}
+	delta, err := s.core.GetDataBlockDelta(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	return s.core.exportDelta(delta)
+}
+
+// ResolvePaths RPC handler. 항목별 실패는 응답의 error 필드로 돌려주고, RPC 자체는 성공함.
+func (s *DataBlockServer) ResolvePaths(ctx context.Context, req *pb.ResolvePathsRequest) (*pb.ResolvePathsResponse, error) {
+	results, err := s.core.ResolvePaths(ctx, req.GetSelections(), req.GetRelative())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ResolvePathsResponse{Results: results}, nil
+}
+
+// RenderScript RPC handler. 채우지 못한 자리표시자는 응답의 errors 로, 잘못된 템플릿은 InvalidArgument 로 돌려줌.
+func (s *DataBlockServer) RenderScript(ctx context.Context, req *pb.RenderScriptRequest) (*pb.RenderScriptResponse, error) {
+	script, err := s.core.RenderScript(ctx, req.GetTemplate(), req.GetRelative())
+	var renderErr *RenderError
+	switch {
+	case errors.As(err, &renderErr):
+		return &pb.RenderScriptResponse{Errors: renderErr.Problems}, nil
+	case errors.Is(err, ErrInvalidTemplate):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	return &pb.RenderScriptResponse{Script: script}, nil
+}
+
+// Search RPC handler. 잘못된 조건이나 오래된 페이지 토큰은 InvalidArgument 로 돌려줌.
+func (s *DataBlockServer) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	result, err := s.core.Search(ctx, search.Query{
+		BlockIDPrefix: req.GetBlockIdPrefix(),
+		Headers:       req.GetHeaders(),
+		CellGlob:      req.GetCellGlob(),
+		CellRegex:     req.GetCellRegex(),
+		Column:        req.GetColumn(),
+		MinRows:       int(req.GetMinRows()),
+		MaxRows:       int(req.GetMaxRows()),
+		PageSize:      int(req.GetPageSize()),
+		PageToken:     req.GetPageToken(),
+	})
+	if errors.Is(err, search.ErrInvalidQuery) || errors.Is(err, search.ErrStalePageToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	exported, err := s.core.exportDataBlock(&pb.DataBlock{Blocks: result.Blocks})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SearchResponse{
+		Blocks:        exported.GetBlocks(),
+		TotalBlocks:   int32(result.TotalBlocks),
+		NextPageToken: result.NextPageToken,
+		UpdatedAt:     result.UpdatedAt,
+	}, nil
+}
+
+// GetUIDataBlock RPC handler. 서버 경로를 뺀 UI 용 DataBlock 을 반환함. 버전 비교는 GetDataBlock 과 같음.
+func (s *DataBlockServer) GetUIDataBlock(ctx context.Context, req *pb.GetUIDataBlockRequest) (*pb.GetDataBlockResponse, error) {
+	v := ClientVersion{ContentHash: req.GetCurrentContentHash(), Generation: req.GetCurrentGeneration(), UpdatedAt: req.GetCurrentUpdatedAt()}
+	dataBlock, st, err := s.core.GetUIDataBlock(ctx, v, req.GetColumns())
+	if err != nil {
+		return nil, err
+	}
+	if st == VersionCurrent {
+		return &pb.GetDataBlockResponse{NoUpdate: true}, nil
+	}
+	return &pb.GetDataBlockResponse{Data: dataBlock, FullResync: st == VersionUnknown}, nil
+}
+
+// DBApisServer bridges DataBlockCliService with the DBApisService gRPC interface.
+type DBApisServer struct {
+	pb.UnimplementedDBApisServiceServer
+	core *DataBlockCliService
+}
+
+// NewDBApisServer wraps DataBlockCliService for gRPC.
+func NewDBApisServer(core *DataBlockCliService) pb.DBApisServiceServer {
+	return &DBApisServer{core: core}
+}
+
+// SyncFoldersInfo RPC handler. force 가 true 면 폴더 변경이 없어도 DataBlock 을 다시 생성함.
+func (s *DBApisServer) SyncFoldersInfo(ctx context.Context, req *pb.SyncFoldersInfoRequest) (*pb.SyncFoldersInfoResponse, error) {
+	updated, err := s.core.SyncFoldersWithOptions(ctx, dbUtils.SyncOptions{Force: req.GetForce()})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SyncFoldersInfoResponse{Updated: updated}, nil
+}
 
 // TODO 이건 api-proto 프로젝트로 빼자.
 
@@ -123,6 +457,11 @@ func SaveDataBlockToTextFile(filePath string, data *pb.DataBlock) error {
 	return nil
 }
 
+// LoadDataBlock filePath 의 DataBlock 을 읽음. gzip 으로 압축된 파일과 압축하지 않은 파일 모두 읽을 수 있음.
 func LoadDataBlock(filePath string) (*pb.DataBlock, error) {
-	return service.LoadDataBlock(filePath)
+	dataBlock := &pb.DataBlock{}
+	if err := protofile.Load(filePath, dataBlock); err != nil {
+		return nil, err
+	}
+	return dataBlock, nil
 }
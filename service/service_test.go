@@ -4,10 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	_ "github.com/mattn/go-sqlite3"
-	pb "github.com/seoyhaein/api-protos/gen/go/datablock/ichthys"
-	"github.com/seoyhaein/api-protos/gen/go/datablock/ichthys/service"
 	"github.com/seoyhaein/tori/config"
 	d "github.com/seoyhaein/tori/db"
+	pb "github.com/seoyhaein/tori/protos"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"os"
 	"path/filepath"
@@ -153,7 +152,7 @@ func TestSGenerateDataBlock(t *testing.T) {
 	if _, err := os.Stat(out); err != nil {
 		t.Fatalf("output file missing: %v", err)
 	}
-	dbLoaded, err := service.LoadDataBlock(out)
+	dbLoaded, err := LoadDataBlock(out)
 	if err != nil {
 		t.Fatalf("failed to load datablock: %v", err)
 	}
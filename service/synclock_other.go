@@ -0,0 +1,8 @@
+//go:build !unix
+
+package service
+
+// lockSync flock 이 없는 플랫폼에서는 프로세스 사이의 lock 을 걸지 않음. 같은 RootDir 은 한 프로세스에서만 sync 해야 함.
+func lockSync(path string) (func(), error) {
+	return func() {}, nil
+}
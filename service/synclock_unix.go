@@ -0,0 +1,33 @@
+//go:build unix
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/sys/unix"
+	"os"
+)
+
+// lockSync path 에 flock 을 걸어서, 같은 RootDir 을 쓰는 다른 프로세스(serve, watch, sync 명령)와 sync 가 겹치지 않게 함.
+// 다른 프로세스가 sync 중이면 끝날 때까지 기다림. 반환한 함수로 lock 을 풂.
+func lockSync(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sync lock %s: %w", path, err)
+	}
+	for {
+		err = unix.Flock(int(f.Fd()), unix.LOCK_EX)
+		if !errors.Is(err, unix.EINTR) {
+			break
+		}
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return func() {
+		_ = unix.Flock(int(f.Fd()), unix.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
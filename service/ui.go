@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/seoyhaein/tori/blockid"
+	pb "github.com/seoyhaein/tori/protos"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// GetUIDataBlock GetDataBlockVersion 과 같은 버전 비교를 한 뒤, UI 에 넘길 수 있도록 ProjectForUI 로 바꾼 DataBlock 을 반환함.
+// 클라이언트 버전이 최신(VersionCurrent)이면 nil 을 반환함.
+func (s *DataBlockCliService) GetUIDataBlock(ctx context.Context, v ClientVersion, columns []string) (*pb.DataBlock, VersionStatus, error) {
+	dataBlock, st, err := s.GetDataBlockVersion(ctx, v)
+	if err != nil || st == VersionCurrent {
+		return nil, st, err
+	}
+	ui, err := s.ProjectForUI(dataBlock, columns)
+	return ui, st, err
+}
+
+// ProjectForUI UI 에 필요한 정보만 남긴 DataBlock 복사본을 만듦. 서버의 경로는 하나도 남기지 않음.
+//   - block_id 는 opaque 모드면 opaque ID, 아니면 RootDir 기준 상대 경로
+//   - 셀 값은 디렉터리를 뺀 파일 이름
+//   - columns 가 있으면 그 컬럼만 남기고, 해당 컬럼이 하나도 없는 FileBlock 은 뺌
+func (s *DataBlockCliService) ProjectForUI(dataBlock *pb.DataBlock, columns []string) (*pb.DataBlock, error) {
+	// 버전은 원본 DataBlock 의 것을 그대로 써서, 다음 요청에서 원본과 비교할 수 있게 함.
+	out := &pb.DataBlock{UpdatedAt: dataBlock.GetUpdatedAt(), Generation: dataBlock.GetGeneration(), ContentHash: dataBlock.GetContentHash()}
+	for _, fb := range dataBlock.GetBlocks() {
+		headers := fb.GetColumnHeaders()
+		if len(columns) > 0 {
+			headers = slices.DeleteFunc(slices.Clone(headers), func(h string) bool {
+				return !slices.Contains(columns, h)
+			})
+			if len(headers) == 0 {
+				continue
+			}
+		}
+		id, err := s.uiBlockID(fb.GetBlockId())
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([]*pb.Row, 0, len(fb.GetRows()))
+		for _, r := range fb.GetRows() {
+			cells := make(map[string]string, len(headers))
+			for _, h := range headers {
+				if v, ok := r.GetCells()[h]; ok {
+					cells[h] = filepath.Base(filepath.FromSlash(v))
+				}
+			}
+			rows = append(rows, &pb.Row{RowNumber: r.GetRowNumber(), Cells: cells})
+		}
+		out.Blocks = append(out.Blocks, &pb.FileBlock{BlockId: id, ColumnHeaders: headers, Rows: rows})
+	}
+	return out, nil
+}
+
+// uiBlockID UI 용 block ID. raw 모드에서도 절대 경로는 내보내지 않고 RootDir 기준 상대 경로로 바꿈.
+func (s *DataBlockCliService) uiBlockID(folderPath string) (string, error) {
+	if _, ok := s.ids.(blockid.Raw); !ok {
+		id, err := s.ids.Encode(folderPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode block id: %w", err)
+		}
+		return id, nil
+	}
+	rel, err := filepath.Rel(filepath.Clean(s.cfg.RootDir), filepath.Clean(folderPath))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("folder %s is not under the root directory", folderPath)
+	}
+	return filepath.ToSlash(rel), nil
+}
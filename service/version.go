@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	dbUtils "github.com/seoyhaein/tori/db"
+	pb "github.com/seoyhaein/tori/protos"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ClientVersion 클라이언트가 가진 DataBlock 버전. ContentHash, Generation, UpdatedAt 중 먼저 채워진 값 하나로 비교함.
+// 모두 비어 있으면 아무것도 없는 클라이언트로 봄.
+type ClientVersion struct {
+	ContentHash string
+	Generation  uint64
+	UpdatedAt   *timestamppb.Timestamp
+}
+
+// ClientVersionOf GetDataBlockRequest 에 담긴 클라이언트 버전.
+func ClientVersionOf(req *pb.GetDataBlockRequest) ClientVersion {
+	return ClientVersion{
+		ContentHash: req.GetCurrentContentHash(),
+		Generation:  req.GetCurrentGeneration(),
+		UpdatedAt:   req.GetCurrentUpdatedAt(),
+	}
+}
+
+// matches d 가 v 와 같은 버전인지. ContentHash, Generation, UpdatedAt 중 먼저 채워진 값 하나로 비교하고, 모두 비어 있으면 false.
+func (v ClientVersion) matches(d *pb.DataBlock) bool {
+	switch {
+	case v.ContentHash != "":
+		return v.ContentHash == d.GetContentHash()
+	case v.Generation != 0:
+		return v.Generation == d.GetGeneration()
+	case v.UpdatedAt != nil:
+		return proto.Equal(v.UpdatedAt, d.GetUpdatedAt())
+	default:
+		return false
+	}
+}
+
+// VersionStatus 클라이언트 버전과 서버 DataBlock 을 비교한 결과.
+type VersionStatus int
+
+const (
+	// VersionOutdated 클라이언트에 버전이 없거나 서버가 아는 이전 버전임. 새 DataBlock 을 보냄.
+	VersionOutdated VersionStatus = iota
+	// VersionCurrent 클라이언트가 서버와 같은 버전을 가지고 있음.
+	VersionCurrent
+	// VersionUnknown 서버가 알지 못하는 버전임(서버보다 큰 generation, 기록에 없는 hash, 서버보다 늦은 updated_at).
+	// datablock.pb 를 이전 것으로 되돌렸거나 다른 서버의 데이터일 수 있으므로, 클라이언트는 가진 것을 버리고 전체를 다시 받아야 함.
+	VersionUnknown
+)
+
+func (v VersionStatus) String() string {
+	switch v {
+	case VersionOutdated:
+		return "outdated"
+	case VersionCurrent:
+		return "current"
+	case VersionUnknown:
+		return "unknown"
+	default:
+		return "invalid"
+	}
+}
+
+// compareVersion current 를 기준으로 클라이언트 버전 v 의 상태를 판단함. 시계는 updated_at 만 보내는 이전 클라이언트에만 사용함.
+func (s *DataBlockCliService) compareVersion(ctx context.Context, current *pb.DataBlock, v ClientVersion) VersionStatus {
+	switch {
+	case v.ContentHash != "":
+		if v.ContentHash == current.GetContentHash() {
+			return VersionCurrent
+		}
+		if s.knownContentHash(ctx, v.ContentHash) {
+			return VersionOutdated
+		}
+		return VersionUnknown
+	case v.Generation != 0:
+		// generation 은 단조 증가하므로 서버보다 작으면 이전 버전임.
+		switch {
+		case v.Generation == current.GetGeneration():
+			return VersionCurrent
+		case v.Generation < current.GetGeneration():
+			return VersionOutdated
+		default:
+			return VersionUnknown
+		}
+	case v.UpdatedAt != nil:
+		clientTime, serverTime := v.UpdatedAt.AsTime(), current.GetUpdatedAt().AsTime()
+		switch {
+		case clientTime.Equal(serverTime):
+			return VersionCurrent
+		case clientTime.Before(serverTime):
+			return VersionOutdated
+		default:
+			return VersionUnknown
+		}
+	default:
+		return VersionOutdated
+	}
+}
+
+// knownContentHash 서버가 hash 를 이전 버전으로 기억하고 있는지. 메모리의 history 를 먼저 보고, 없으면 DB 의 기록을 찾음.
+func (s *DataBlockCliService) knownContentHash(ctx context.Context, hash string) bool {
+	if s.history.find(func(d *pb.DataBlock) bool { return d.GetContentHash() == hash }) != nil {
+		return true
+	}
+	if s.db == nil {
+		return false
+	}
+	v, err := dbUtils.FindDataBlockVersion(ctx, s.db, 0, hash)
+	if err != nil {
+		logger.Warnf("failed to look up datablock version %s: %v", hash, err)
+		return false
+	}
+	return v != nil
+}
+
+// sameVersion 두 DataBlock 이 같은 버전인지. content_hash 가 있으면 그것으로, 없으면 updated_at 으로 비교함.
+func sameVersion(a, b *pb.DataBlock) bool {
+	if a.GetContentHash() != "" && b.GetContentHash() != "" {
+		return a.GetContentHash() == b.GetContentHash() && a.GetGeneration() == b.GetGeneration()
+	}
+	return proto.Equal(a.GetUpdatedAt(), b.GetUpdatedAt())
+}
@@ -0,0 +1,179 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	globallog "github.com/seoyhaein/tori/log"
+	"os"
+	"sync"
+	"time"
+)
+
+var logger = globallog.Log
+
+// ReloadCheckInterval 인증서 파일이 바뀌었는지 다시 확인하는 최소 간격. handshake 마다 stat 하지 않기 위함.
+var ReloadCheckInterval = time.Second
+
+// fileStamp 파일이 바뀌었는지 판단하기 위한 수정 시각과 크기.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stampOf(paths ...string) ([]fileStamp, error) {
+	stamps := make([]fileStamp, 0, len(paths))
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, fileStamp{modTime: info.ModTime(), size: info.Size()})
+	}
+	return stamps, nil
+}
+
+func sameStamps(a, b []fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+	return true
+}
+
+// reloader paths 의 파일이 바뀌면 load 로 값을 다시 만듦. 다시 읽다가 실패하면 이전 값을 계속 사용함.
+// 인증서 교체 중간(cert 만 바뀌고 key 는 아직)처럼 잠깐 맞지 않는 상태가 있을 수 있기 때문.
+type reloader[T any] struct {
+	paths []string
+	load  func() (T, error)
+
+	mu        sync.Mutex
+	value     T
+	stamps    []fileStamp
+	lastCheck time.Time
+}
+
+func newReloader[T any](load func() (T, error), paths ...string) (*reloader[T], error) {
+	r := &reloader[T]{paths: paths, load: load}
+	stamps, err := stampOf(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if r.value, err = load(); err != nil {
+		return nil, err
+	}
+	r.stamps, r.lastCheck = stamps, time.Now()
+	return r, nil
+}
+
+func (r *reloader[T]) get() T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.lastCheck) < ReloadCheckInterval {
+		return r.value
+	}
+	r.lastCheck = time.Now()
+	stamps, err := stampOf(r.paths...)
+	if err != nil || sameStamps(stamps, r.stamps) {
+		return r.value
+	}
+	value, err := r.load()
+	if err != nil {
+		logger.Warnf("failed to reload %v, keeping the previous one: %v", r.paths, err)
+		return r.value
+	}
+	logger.Infof("reloaded %v", r.paths)
+	r.value, r.stamps = value, stamps
+	return r.value
+}
+
+// loadKeyPair certFile/keyFile 을 읽음.
+func loadKeyPair(certFile, keyFile string) func() (*tls.Certificate, error) {
+	return func() (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificate %s: %w", certFile, err)
+		}
+		return &cert, nil
+	}
+}
+
+// loadCAPool PEM CA bundle 을 읽음.
+func loadCAPool(caFile string) func() (*x509.CertPool, error) {
+	return func() (*x509.CertPool, error) {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+		}
+		return pool, nil
+	}
+}
+
+// ServerConfig certFile/keyFile 로 서버용 tls.Config 를 만듦. clientCAFile 이 있으면 그 CA 가 서명한 클라이언트 인증서를 요구함(mTLS).
+// 인증서, 키, CA 파일이 바뀌면 재시작 없이 새 handshake 부터 다시 읽은 값을 사용함.
+// config 패키지에 의존하지 않도록 파일 경로만 받음. client 패키지가 이 패키지를 import 함.
+func ServerConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	certs, err := newReloader(loadKeyPair(certFile, keyFile), certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return certs.get(), nil
+		},
+	}
+	if clientCAFile == "" {
+		return base, nil
+	}
+
+	cas, err := newReloader(loadCAPool(clientCAFile), clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	base.ClientAuth = tls.RequireAndVerifyClientCert
+	base.ClientCAs = cas.get()
+	// ClientCAs 는 handshake 마다 바꿀 수 없으므로, 연결마다 현재 CA 로 설정을 복사해서 사용함.
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.GetConfigForClient = nil
+		c.ClientCAs = cas.get()
+		return c, nil
+	}
+	return base, nil
+}
+
+// ClientConfig 클라이언트용 tls.Config 를 만듦. caFile 이 비어 있으면 시스템 CA 를 사용하고,
+// certFile/keyFile 이 있으면 mTLS 용 클라이언트 인증서로 사용함(바뀌면 다음 연결부터 다시 읽음).
+// serverName 이 비어 있으면 접속 주소의 호스트 이름으로 검증함.
+func ClientConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	c := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if caFile != "" {
+		pool, err := loadCAPool(caFile)()
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs = pool
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("client certificate and key must be set together")
+	}
+	if certFile != "" {
+		certs, err := newReloader(loadKeyPair(certFile, keyFile), certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return certs.get(), nil
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,81 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestServerConfigReload(t *testing.T) {
+	old := ReloadCheckInterval
+	ReloadCheckInterval = 0
+	defer func() { ReloadCheckInterval = old }()
+
+	dir := t.TempDir()
+	ca, err := GenerateCA("test-ca", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateCA failed: %v", err)
+	}
+	writeServer := func(cn string) (string, string) {
+		certPEM, keyPEM, err := ca.Issue(cn, []string{"localhost"}, false, time.Hour)
+		if err != nil {
+			t.Fatalf("Issue failed: %v", err)
+		}
+		certFile, keyFile, err := WritePair(dir, "server", certPEM, keyPEM)
+		if err != nil {
+			t.Fatalf("WritePair failed: %v", err)
+		}
+		return certFile, keyFile
+	}
+	certFile, keyFile := writeServer("first")
+	cfg, err := ServerConfig(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("ServerConfig failed: %v", err)
+	}
+	servedCN := func() string {
+		t.Helper()
+		cert, err := cfg.GetCertificate(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatalf("GetCertificate failed: %v", err)
+		}
+		leaf := cert.Leaf
+		if leaf == nil {
+			t.Fatal("expected parsed leaf certificate")
+		}
+		return leaf.Subject.CommonName
+	}
+	if cn := servedCN(); cn != "first" {
+		t.Fatalf("expected first, got %q", cn)
+	}
+
+	writeServer("second")
+	if cn := servedCN(); cn != "second" {
+		t.Errorf("expected reloaded certificate, got %q", cn)
+	}
+
+	// 잘못된 파일로 바뀌면 이전 인증서를 계속 사용함.
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o644); err != nil {
+		t.Fatalf("failed to corrupt certificate: %v", err)
+	}
+	if cn := servedCN(); cn != "second" {
+		t.Errorf("expected previous certificate to be kept, got %q", cn)
+	}
+
+	if _, err := ServerConfig(certFile, keyFile, ""); err == nil {
+		t.Error("expected error for an invalid certificate at startup")
+	}
+}
+
+func TestClientConfig(t *testing.T) {
+	if _, err := ClientConfig("", "client.crt", "", ""); err == nil {
+		t.Error("expected error when only the certificate is set")
+	}
+	cfg, err := ClientConfig("", "", "", "tori.example")
+	if err != nil {
+		t.Fatalf("ClientConfig failed: %v", err)
+	}
+	if cfg.ServerName != "tori.example" || cfg.GetClientCertificate != nil {
+		t.Errorf("unexpected client config: %+v", cfg)
+	}
+}
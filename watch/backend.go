@@ -0,0 +1,173 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/seoyhaein/tori/config"
+	dbUtils "github.com/seoyhaein/tori/db"
+	"github.com/seoyhaein/tori/rules"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// ruleFileName 폴더의 FileBlock 규칙 파일. 바뀌면 파일 목록이 같아도 FileBlock 을 다시 만들어야 함.
+const ruleFileName = "rule.json"
+
+// generatedFiles sync 가 직접 쓰는 파일. 감시하면 sync 할 때마다 다시 sync 가 예약됨.
+// "*.pb" 는 이름에 .pb 가 들어간 파일을 모두 잡으므로 저장 중인 임시 파일과 datablock.pb.lock 도 포함함.
+var generatedFiles = []string{rules.InvalidFilesPattern, "fileblock.csv", "*.pb"}
+
+// Backend RootDir 아래의 변경을 감지하는 방식.
+type Backend interface {
+	// Name "poll" 또는 "inotify".
+	Name() string
+	// Run ctx 가 취소될 때까지 블록되면서 바뀐 파일이나 폴더의 경로마다 changed 를 호출함.
+	// changed 는 한 goroutine 에서만 호출됨. ctx 취소로 끝나면 nil 을 반환함.
+	Run(ctx context.Context, changed func(path string)) error
+}
+
+// IgnoreFunc path 의 변경을 무시할지. isDir 이면 폴더이고, 무시한 폴더 아래는 감시하지 않음. RootDir 에는 호출하지 않음.
+type IgnoreFunc func(path string, isDir bool) bool
+
+// Ignore foldersExclusions 에 해당하는 폴더와, sync 가 쓰는 파일과 filesExclusions 에 해당하는 파일을 무시함.
+// 폴더는 sync 와 같이 이름이 정확히 같을 때 제외함. rule.json 은 filesExclusions 에 있어도 감시함.
+func Ignore(foldersExclusions, filesExclusions []string) IgnoreFunc {
+	return func(path string, isDir bool) bool {
+		name := filepath.Base(path)
+		if isDir {
+			return slices.Contains(foldersExclusions, name)
+		}
+		if name == ruleFileName {
+			return false
+		}
+		return dbUtils.ExcludedFile(name, generatedFiles) || dbUtils.ExcludedFile(name, filesExclusions)
+	}
+}
+
+// NewBackend cfg.Backend 에 맞는 Backend 를 만듦. backend 를 비워 두면 Linux 에서는 inotify 를 쓰고,
+// inotify 를 준비하지 못하면(감시 수 제한 등) poll 로 대신함.
+func NewBackend(cfg config.WatchConfig, root string, ignore IgnoreFunc) (Backend, error) {
+	root = filepath.Clean(root)
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("failed to watch %s: %w", root, err)
+	}
+	switch cfg.Backend {
+	case config.WatchBackendPoll:
+		return newPollBackend(root, cfg.WithDefaults().PollInterval.D(), ignore), nil
+	case config.WatchBackendInotify:
+		return newInotifyBackend(root, ignore)
+	case "":
+		if runtime.GOOS == "linux" {
+			b, err := newInotifyBackend(root, ignore)
+			if err == nil {
+				return b, nil
+			}
+			logger.Warnf("falling back to polling: %v", err)
+		}
+		return newPollBackend(root, cfg.WithDefaults().PollInterval.D(), ignore), nil
+	default:
+		return nil, fmt.Errorf("unknown watch backend %q", cfg.Backend)
+	}
+}
+
+// fileState poll 에서 비교하는 파일 상태. 폴더는 있는지만 비교함.
+// 폴더의 수정 시각은 sync 가 *.pb 를 쓸 때도 바뀌므로 보지 않음.
+type fileState struct {
+	dir     bool
+	size    int64
+	modTime int64
+}
+
+// pollBackend interval 마다 RootDir 전체를 읽어서 이전 스캔과 다른 경로를 알림.
+type pollBackend struct {
+	root     string
+	interval time.Duration
+	ignore   IgnoreFunc
+}
+
+func newPollBackend(root string, interval time.Duration, ignore IgnoreFunc) *pollBackend {
+	return &pollBackend{root: root, interval: interval, ignore: ignore}
+}
+
+func (p *pollBackend) Name() string { return config.WatchBackendPoll }
+
+func (p *pollBackend) Run(ctx context.Context, changed func(path string)) error {
+	prev, err := p.scan()
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		cur, err := p.scan()
+		if err != nil {
+			// RootDir 이 잠시 보이지 않는 경우(마운트 재연결 등)는 다음 스캔에서 다시 비교함.
+			logger.Warnf("failed to scan %s: %v", p.root, err)
+			continue
+		}
+		for path, st := range cur {
+			if old, ok := prev[path]; !ok || old != st {
+				changed(path)
+			}
+		}
+		for path := range prev {
+			if _, ok := cur[path]; !ok {
+				changed(path)
+			}
+		}
+		prev = cur
+	}
+}
+
+// scan RootDir 아래의 모든 폴더와 무시하지 않는 파일의 상태. 스캔 중에 사라진 항목은 건너뜀.
+func (p *pollBackend) scan() (map[string]fileState, error) {
+	states := make(map[string]fileState)
+	err := filepath.WalkDir(p.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == p.root {
+				return err
+			}
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			logger.Warnf("skipping %s: %v", path, err)
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != p.root && p.ignore != nil && p.ignore(path, true) {
+				return filepath.SkipDir
+			}
+			states[path] = fileState{dir: true}
+			return nil
+		}
+		if p.ignore != nil && p.ignore(path, false) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		states[path] = fileState{size: info.Size(), modTime: info.ModTime().UnixNano()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", p.root, err)
+	}
+	return states, nil
+}
@@ -0,0 +1,172 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seoyhaein/tori/config"
+)
+
+func TestIgnore(t *testing.T) {
+	ignore := Ignore([]string{"scratch"}, []string{"*.json", "*.tmp"})
+	for path, want := range map[string]bool{
+		"/root/a/sample_R1.fastq.gz":               false,
+		"/root/a/rule.json":                        false,
+		"/root/a/meta.json":                        true,
+		"/root/a/upload.tmp":                       true,
+		"/root/a/afiles.pb":                        true,
+		"/root/datablock.pb":                       true,
+		"/root/a/invalid_files_20260101120000.txt": true,
+		"/root/a/fileblock.csv":                    true,
+		"/root/datablock.pb.lock":                  true,
+		"/root/datablock.pb.1234567":               true,
+	} {
+		if got := ignore(path, false); got != want {
+			t.Errorf("Ignore(%q) = %v, want %v", path, got, want)
+		}
+	}
+	for path, want := range map[string]bool{
+		"/root/scratch":      true,
+		"/root/a/scratch":    true,
+		"/root/scratch2":     false,
+		"/root/a.json":       false, // 파일 제외 규칙은 폴더에 적용하지 않음
+		"/root/a/rule.json":  false,
+		"/root/run1/sampleA": false,
+	} {
+		if got := ignore(path, true); got != want {
+			t.Errorf("Ignore(%q, dir) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+// collect backend 를 실행하고 받은 경로를 채널로 넘김.
+func collect(t *testing.T, b Backend) <-chan string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	paths := make(chan string, 64)
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx, func(p string) { paths <- p }) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("%s backend returned error: %v", b.Name(), err)
+		}
+	})
+	return paths
+}
+
+// expectPath want 가 올 때까지 받은 경로를 버림.
+func expectPath(t *testing.T, paths <-chan string, want string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case p := <-paths:
+			if p == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("did not see change for %s", want)
+		}
+	}
+}
+
+func TestPollBackend(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "run1")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBackend(config.WatchConfig{Backend: config.WatchBackendPoll, PollInterval: config.Duration(20 * time.Millisecond)}, root, Ignore(nil, nil))
+	if err != nil {
+		t.Fatalf("NewBackend: %v", err)
+	}
+	paths := collect(t, b)
+	time.Sleep(50 * time.Millisecond)
+
+	// sync 가 쓰는 파일은 알리지 않음.
+	if err := os.WriteFile(filepath.Join(sub, "run1files.pb"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data := filepath.Join(sub, "a_R1.fastq")
+	if err := os.WriteFile(data, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case p := <-paths:
+		if p != data {
+			t.Fatalf("unexpected change %s", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("poll backend did not report new file")
+	}
+
+	if err := os.Remove(data); err != nil {
+		t.Fatal(err)
+	}
+	expectPath(t, paths, data)
+}
+
+// TestSyncOutputDoesNotRetrigger sync 가 쓰는 파일(invalid_files_*.txt, fileblock.csv, *.pb, 저장 중인 임시 파일, lock 파일)이
+// 다시 sync 를 예약하지 않는지 확인함.
+func TestSyncOutputDoesNotRetrigger(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "run1")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBackend(config.WatchConfig{Backend: config.WatchBackendPoll, PollInterval: config.Duration(20 * time.Millisecond)}, root, Ignore(nil, config.DefaultFilesExclusions()))
+	if err != nil {
+		t.Fatalf("NewBackend: %v", err)
+	}
+	rs := newRecordingSync()
+	outputs := 0
+	writeOutputs := func(ctx context.Context, force bool) (bool, error) {
+		outputs++
+		for _, name := range []string{
+			filepath.Join(sub, fmt.Sprintf("invalid_files_2026010112000%d.txt", outputs)),
+			filepath.Join(sub, "fileblock.csv"),
+			filepath.Join(sub, "run1files.pb"),
+			filepath.Join(root, fmt.Sprintf("datablock.pb.%d", outputs)),
+			filepath.Join(root, "datablock.pb"),
+			filepath.Join(root, "datablock.pb.lock"),
+		} {
+			if err := os.WriteFile(name, []byte(name), 0o644); err != nil {
+				return false, err
+			}
+		}
+		return rs.sync(ctx, force)
+	}
+	cfg := config.WatchConfig{QuietPeriod: config.Duration(50 * time.Millisecond), MaxDelay: config.Duration(time.Second)}
+	w := newWatcher(b, cfg, root, writeOutputs)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- w.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-errCh; err != nil {
+			t.Errorf("Run returned error: %v", err)
+		}
+	})
+
+	// 시작할 때 한 번 sync 하고, 그 sync 가 쓴 파일로는 다시 sync 하지 않아야 함.
+	rs.wait(t)
+	time.Sleep(500 * time.Millisecond)
+	if n := rs.count(); n != 1 {
+		t.Fatalf("sync output scheduled %d extra syncs", n-1)
+	}
+
+	// 데이터 파일은 그대로 sync 를 예약함.
+	if err := os.WriteFile(filepath.Join(sub, "a_R1.fastq"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rs.wait(t)
+	time.Sleep(500 * time.Millisecond)
+	if n := rs.count(); n != 2 {
+		t.Fatalf("expected one sync for the data file, got %d syncs", n-1)
+	}
+}
@@ -0,0 +1,170 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/seoyhaein/tori/config"
+	"golang.org/x/sys/unix"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+)
+
+// inotifyMask 폴더마다 받는 이벤트. 큰 파일을 올리는 동안에는 IN_MODIFY 가 계속 들어와서 sync 가 미뤄짐.
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE | unix.IN_ATTRIB |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF | unix.IN_ONLYDIR
+
+// inotifyBackend RootDir 과 모든 하위 폴더에 inotify watch 를 걸고, 새로 생긴 폴더에도 watch 를 추가함.
+// 다른 노드에서 바꾼 파일은 알 수 없으므로 NFS, lustre 에서는 poll 을 써야 함.
+type inotifyBackend struct {
+	root   string
+	ignore IgnoreFunc
+	fd     int
+	// file fd 를 Go runtime poller 에 등록해서, Close 하면 블록된 Read 가 바로 끝나게 함.
+	file    *os.File
+	watches map[int]string
+}
+
+// newInotifyBackend inotify 인스턴스를 만들고 root 아래의 모든 폴더를 등록함. fd 는 Run 이 끝날 때 닫힘.
+func newInotifyBackend(root string, ignore IgnoreFunc) (Backend, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize inotify: %w", err)
+	}
+	b := &inotifyBackend{
+		root:    root,
+		ignore:  ignore,
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		watches: make(map[int]string),
+	}
+	if err := b.addTree(root); err != nil {
+		_ = b.file.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *inotifyBackend) Name() string { return config.WatchBackendInotify }
+
+// addTree dir 과 그 아래의 모든 폴더에 watch 를 등록함. 등록 도중 사라진 폴더와 제외한 폴더는 건너뜀.
+func (b *inotifyBackend) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != b.root && b.ignore != nil && b.ignore(path, true) {
+			return filepath.SkipDir
+		}
+		wd, err := unix.InotifyAddWatch(b.fd, path, inotifyMask)
+		if err != nil {
+			if errors.Is(err, unix.ENOENT) || errors.Is(err, unix.ENOTDIR) {
+				return filepath.SkipDir
+			}
+			if errors.Is(err, unix.ENOSPC) {
+				return fmt.Errorf("failed to watch %s: too many watches, raise fs.inotify.max_user_watches: %w", path, err)
+			}
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		b.watches[wd] = path
+		return nil
+	})
+}
+
+// removeTree dir 아래로 등록된 watch 를 지움. 폴더가 RootDir 밖으로 옮겨졌을 때 사용함.
+func (b *inotifyBackend) removeTree(dir string) {
+	for wd, path := range b.watches {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			_, _ = unix.InotifyRmWatch(b.fd, uint32(wd))
+			delete(b.watches, wd)
+		}
+	}
+}
+
+func (b *inotifyBackend) Run(ctx context.Context, changed func(path string)) error {
+	defer b.file.Close()
+	stop := context.AfterFunc(ctx, func() { _ = b.file.Close() })
+	defer stop()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := b.file.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read inotify events: %w", err)
+		}
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameStart := off + unix.SizeofInotifyEvent
+			off = nameStart + int(ev.Len)
+			name := strings.TrimRight(string(buf[nameStart:off]), "\x00")
+			b.handle(int(ev.Wd), ev.Mask, name, changed)
+		}
+	}
+}
+
+func (b *inotifyBackend) handle(wd int, mask uint32, name string, changed func(path string)) {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		// 이벤트를 놓쳤으므로 어떤 경로가 바뀌었는지 모름. RootDir 을 알려서 sync 가 전체를 비교하게 함.
+		logger.Warnf("inotify event queue overflowed under %s", b.root)
+		changed(b.root)
+		return
+	}
+	dir, ok := b.watches[wd]
+	if mask&unix.IN_IGNORED != 0 {
+		delete(b.watches, wd)
+		return
+	}
+	if !ok {
+		return
+	}
+	path := dir
+	if name != "" {
+		path = filepath.Join(dir, name)
+	}
+
+	switch {
+	case mask&(unix.IN_DELETE_SELF|unix.IN_MOVE_SELF) != 0:
+		// 하위 폴더는 부모 폴더의 IN_DELETE, IN_MOVED_FROM 으로 이미 알렸음. RootDir 자체인 경우만 알림.
+		if path == b.root {
+			logger.Warnf("%s was removed or moved", b.root)
+			changed(path)
+		}
+	case mask&unix.IN_ISDIR != 0:
+		if b.ignore != nil && b.ignore(path, true) {
+			return
+		}
+		switch {
+		case mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+			// 폴더를 만들고 바로 파일을 쓰면 watch 를 걸기 전의 파일은 이벤트가 없지만, 폴더 생성으로 sync 가 예약되므로 놓치지 않음.
+			if err := b.addTree(path); err != nil {
+				logger.Warnf("failed to watch new folder: %v", err)
+			}
+		case mask&unix.IN_MOVED_FROM != 0:
+			b.removeTree(path)
+		case mask&unix.IN_DELETE == 0:
+			// 폴더 자체의 속성 변경은 sync 결과에 영향을 주지 않음.
+			return
+		}
+		changed(path)
+	default:
+		if b.ignore != nil && b.ignore(path, false) {
+			return
+		}
+		changed(path)
+	}
+}
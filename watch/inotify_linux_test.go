@@ -0,0 +1,57 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seoyhaein/tori/config"
+)
+
+func TestInotifyBackend(t *testing.T) {
+	root := t.TempDir()
+	// 제외한 폴더는 처음부터 있든 나중에 생기든 감시하지 않음.
+	scratch := filepath.Join(root, "scratch")
+	if err := os.Mkdir(scratch, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBackend(config.WatchConfig{Backend: config.WatchBackendInotify}, root, Ignore([]string{"scratch"}, nil))
+	if err != nil {
+		t.Fatalf("NewBackend: %v", err)
+	}
+	paths := collect(t, b)
+
+	// 새 폴더도 감시 대상에 추가되어야 그 안의 파일 변경을 받을 수 있음.
+	sub := filepath.Join(root, "run1")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	expectPath(t, paths, sub)
+
+	if err := os.WriteFile(filepath.Join(sub, "run1files.pb"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(scratch, "a_R1.fastq"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(sub, "scratch", "tmp"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "scratch", "tmp", "a_R1.fastq"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data := filepath.Join(sub, "a_R1.fastq")
+	if err := os.WriteFile(data, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := <-paths
+	if p != data {
+		t.Fatalf("expected change for %s first, got %s", data, p)
+	}
+
+	moved := filepath.Join(root, "run2")
+	if err := os.Rename(sub, moved); err != nil {
+		t.Fatal(err)
+	}
+	expectPath(t, paths, moved)
+}
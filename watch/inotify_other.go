@@ -0,0 +1,10 @@
+//go:build !linux
+
+package watch
+
+import "fmt"
+
+// newInotifyBackend inotify 는 Linux 에서만 사용할 수 있음. 다른 OS 에서는 poll backend 를 써야 함.
+func newInotifyBackend(root string, ignore IgnoreFunc) (Backend, error) {
+	return nil, fmt.Errorf("inotify backend is only supported on linux")
+}
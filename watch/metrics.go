@@ -0,0 +1,21 @@
+package watch
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	changesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tori_watch_changes_total",
+		Help: "File system changes under rootDir seen by the watcher, per backend.",
+	}, []string{"backend"})
+	pendingChanges = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "tori_watch_pending_changes",
+		Help: "Distinct paths changed since the last sync started.",
+	})
+	syncsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tori_watch_syncs_total",
+		Help: "Syncs started by the watcher by result (updated, unchanged, error).",
+	}, []string{"result"})
+)
@@ -0,0 +1,294 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/seoyhaein/tori/config"
+	dbUtils "github.com/seoyhaein/tori/db"
+	globallog "github.com/seoyhaein/tori/log"
+	"github.com/seoyhaein/tori/metrics"
+	"github.com/seoyhaein/tori/service"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var logger = globallog.Log
+
+// Watcher 상태 값.
+const (
+	StatePending = "pending" // 변경을 받았고 quiet period 가 끝나기를 기다리는 중
+	StateIdle    = "idle"    // 마지막 sync 이후 변경 없음
+	StateSyncing = "syncing" // sync 실행 중
+	StateStopped = "stopped" // Run 이 끝남
+)
+
+// SyncResult watcher 가 실행한 sync 한 번의 결과.
+type SyncResult struct {
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Force    bool      `json:"force"`   // rule.json 이 바뀌어서 모든 FileBlock 을 다시 만들었는지
+	Updated  bool      `json:"updated"` // DataBlock 이 새로 만들어졌는지
+	Error    string    `json:"error,omitempty"`
+}
+
+// Status /status 로 노출하는 watcher 상태.
+type Status struct {
+	Backend        string      `json:"backend"`
+	State          string      `json:"state"`
+	PendingChanges int         `json:"pendingChanges"` // 마지막 sync 시작 후 바뀐 경로 수
+	LastChange     *time.Time  `json:"lastChange,omitempty"`
+	LastChangePath string      `json:"lastChangePath,omitempty"` // RootDir 기준 상대 경로. /status 는 인증이 없으므로 서버 경로를 드러내지 않음
+	NextSync       *time.Time  `json:"nextSync,omitempty"`
+	Syncs          int         `json:"syncs"`
+	Failures       int         `json:"failures"`
+	LastSync       *SyncResult `json:"lastSync,omitempty"`
+}
+
+// syncFunc 변경이 잠잠해진 뒤 실행하는 sync. force 는 rule.json 이 바뀌었을 때 true.
+type syncFunc func(ctx context.Context, force bool) (bool, error)
+
+// Watcher RootDir 의 변경을 Backend 로 받아서, 변경이 quietPeriod 동안 멈추면 sync 를 한 번 실행함.
+// 변경이 계속 들어와도 첫 변경 후 maxDelay 안에는 sync 함.
+type Watcher struct {
+	backend  Backend
+	root     string
+	sync     syncFunc
+	quiet    time.Duration
+	maxDelay time.Duration
+
+	mu     sync.Mutex
+	status Status
+}
+
+// New core 의 설정(watch, rootDir, foldersExclusions, filesExclusions)으로 Watcher 를 만듦. sync 는 core.SyncFoldersWithOptions 로 실행함.
+func New(core *service.DataBlockCliService) (*Watcher, error) {
+	cfg := core.Config()
+	wcfg := cfg.Watch.WithDefaults()
+	if err := wcfg.Validate(); err != nil {
+		return nil, err
+	}
+	backend, err := NewBackend(wcfg, cfg.RootDir, Ignore(cfg.FoldersExclusions, cfg.FilesExclusions))
+	if err != nil {
+		return nil, err
+	}
+	return newWatcher(backend, wcfg, cfg.RootDir, func(ctx context.Context, force bool) (bool, error) {
+		return core.SyncFoldersWithOptions(ctx, dbUtils.SyncOptions{Force: force})
+	}), nil
+}
+
+func newWatcher(backend Backend, cfg config.WatchConfig, root string, sync syncFunc) *Watcher {
+	cfg = cfg.WithDefaults()
+	return &Watcher{
+		backend:  backend,
+		root:     filepath.Clean(root),
+		sync:     sync,
+		quiet:    cfg.QuietPeriod.D(),
+		maxDelay: cfg.MaxDelay.D(),
+		status:   Status{Backend: backend.Name(), State: StatePending},
+	}
+}
+
+// Status 현재 상태의 복사본.
+func (w *Watcher) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st := w.status
+	if st.LastSync != nil {
+		last := *st.LastSync
+		st.LastSync = &last
+	}
+	return st
+}
+
+// relPath path 를 RootDir 기준 상대 경로로 바꿈. RootDir 밖의 경로는 나오지 않지만, 그런 경우에도 경로를 드러내지 않음.
+func (w *Watcher) relPath(path string) string {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return rel
+}
+
+func (w *Watcher) update(fn func(st *Status)) {
+	w.mu.Lock()
+	fn(&w.status)
+	w.mu.Unlock()
+}
+
+// Run ctx 가 취소될 때까지 변경을 감시하면서 sync 함. 시작하면 먼저 한 번 sync 해서 watch 가 꺼져 있던 동안의 변경을 반영함.
+// ctx 가 취소되면 새 sync 는 시작하지 않고, 실행 중인 sync 는 끝날 때까지 기다린 뒤 nil 을 반환함.
+// 반영하지 못한 변경은 다음 실행의 첫 sync 에서 반영됨. Backend 가 실패하면 그 에러를 반환함.
+func (w *Watcher) Run(ctx context.Context) error {
+	backendCtx, cancelBackend := context.WithCancel(ctx)
+	defer cancelBackend()
+	events := make(chan string, 256)
+	backendDone := make(chan error, 1)
+	go func() {
+		backendDone <- w.backend.Run(backendCtx, func(path string) {
+			select {
+			case events <- path:
+			case <-backendCtx.Done():
+			}
+		})
+	}()
+	logger.Infof("watching for changes with %s backend (quiet period %s, max delay %s)", w.backend.Name(), w.quiet, w.maxDelay)
+
+	var (
+		// 마지막 sync 시작 후 바뀐 경로. 비어 있지 않거나 retry 가 true 면 sync 가 예약된 상태임.
+		changed     = make(map[string]struct{})
+		force       bool
+		retry       = true
+		first, last time.Time
+		running     chan SyncResult
+		runErr      error
+	)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	pending := func() bool { return retry || len(changed) > 0 }
+	schedule := func(at time.Time) {
+		timer.Reset(time.Until(at))
+		w.update(func(st *Status) { st.State, st.NextSync = StatePending, &at })
+	}
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case err := <-backendDone:
+			backendDone = nil
+			if err == nil {
+				err = fmt.Errorf("stopped unexpectedly")
+			}
+			runErr = fmt.Errorf("%s backend failed: %w", w.backend.Name(), err)
+			break loop
+		case path := <-events:
+			now := time.Now()
+			if len(changed) == 0 {
+				first = now
+			}
+			last = now
+			changed[path] = struct{}{}
+			if filepath.Base(path) == ruleFileName {
+				force = true
+			}
+			changesTotal.WithLabelValues(w.backend.Name()).Inc()
+			pendingChanges.Set(float64(len(changed)))
+			n, rel := len(changed), w.relPath(path)
+			w.update(func(st *Status) { st.PendingChanges, st.LastChange, st.LastChangePath = n, &now, rel })
+			if running == nil {
+				schedule(w.deadline(first, last))
+			}
+		case <-timer.C:
+			if running != nil || !pending() {
+				continue
+			}
+			running = make(chan SyncResult, 1)
+			go w.runSync(context.WithoutCancel(ctx), force, len(changed), running)
+			clear(changed)
+			force, retry = false, false
+			pendingChanges.Set(0)
+		case res := <-running:
+			running = nil
+			if res.Error != "" {
+				// 변경이 더 없어도 maxDelay 뒤에 다시 시도함. 실패한 sync 가 force 였으면 다음에도 force 로 실행함.
+				force = force || res.Force
+				if !retry && len(changed) == 0 {
+					first = time.Now()
+					last = first.Add(w.maxDelay)
+				}
+				retry = true
+			}
+			if pending() {
+				schedule(w.deadline(first, last))
+			} else {
+				w.update(func(st *Status) { st.State, st.NextSync = StateIdle, nil })
+			}
+		}
+	}
+
+	cancelBackend()
+	if running != nil {
+		logger.Info("waiting for the running sync to finish before stopping")
+		<-running
+	}
+	if backendDone != nil {
+		<-backendDone
+	}
+	if len(changed) > 0 {
+		logger.Infof("%d changed paths were not synced yet; they will be picked up by the next sync", len(changed))
+	}
+	w.update(func(st *Status) { st.State, st.NextSync = StateStopped, nil })
+	logger.Info("watcher stopped")
+	return runErr
+}
+
+// deadline 마지막 변경 후 quiet period 와 첫 변경 후 maxDelay 중 이른 시각.
+func (w *Watcher) deadline(first, last time.Time) time.Time {
+	at := last.Add(w.quiet)
+	if limit := first.Add(w.maxDelay); limit.Before(at) {
+		at = limit
+	}
+	return at
+}
+
+// runSync sync 를 실행하고 결과를 상태와 metric 에 기록한 뒤 done 으로 보냄.
+func (w *Watcher) runSync(ctx context.Context, force bool, changes int, done chan<- SyncResult) {
+	res := SyncResult{Started: time.Now(), Force: force}
+	w.update(func(st *Status) { st.State, st.NextSync, st.PendingChanges = StateSyncing, nil, 0 })
+	logger.Infof("syncing after %d changed paths (force: %v)", changes, force)
+
+	updated, err := w.sync(ctx, force)
+	res.Finished, res.Updated = time.Now(), updated
+	result := "unchanged"
+	switch {
+	case err != nil:
+		res.Error, result = err.Error(), "error"
+		logger.Errorf("watch sync failed: %v", err)
+	case updated:
+		result = "updated"
+		logger.Infof("watch sync regenerated the DataBlock in %s", res.Finished.Sub(res.Started))
+	default:
+		logger.Infof("watch sync found no changes")
+	}
+	syncsTotal.WithLabelValues(result).Inc()
+	w.update(func(st *Status) {
+		st.Syncs++
+		if err != nil {
+			st.Failures++
+		}
+		st.LastSync = &res
+	})
+	done <- res
+}
+
+// Handler GET /status(JSON 상태), GET /healthz, GET /metrics 를 처리함.
+// /healthz 는 Run 이 끝났거나 마지막 sync 가 실패했으면 503 을 반환함.
+func (w *Watcher) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(rw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(w.Status()); err != nil {
+			logger.Warnf("failed to write watch status: %v", err)
+		}
+	})
+	mux.HandleFunc("GET /healthz", func(rw http.ResponseWriter, r *http.Request) {
+		st := w.Status()
+		switch {
+		case st.State == StateStopped:
+			http.Error(rw, "watcher stopped", http.StatusServiceUnavailable)
+		case st.LastSync != nil && st.LastSync.Error != "":
+			http.Error(rw, "last sync failed: "+st.LastSync.Error, http.StatusServiceUnavailable)
+		default:
+			fmt.Fprintln(rw, "ok")
+		}
+	})
+	mux.Handle("GET /metrics", metrics.Handler())
+	return mux
+}
@@ -0,0 +1,226 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"github.com/seoyhaein/tori/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBackend 테스트에서 변경을 직접 넣는 Backend.
+type fakeBackend struct {
+	events chan string
+}
+
+func (f *fakeBackend) Name() string { return "fake" }
+
+func (f *fakeBackend) Run(ctx context.Context, changed func(path string)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case p := <-f.events:
+			changed(p)
+		}
+	}
+}
+
+// recordingSync 호출마다 force 값을 기록하고, block 이 있으면 닫힐 때까지 기다림.
+type recordingSync struct {
+	mu     sync.Mutex
+	calls  []bool
+	called chan bool
+	block  chan struct{}
+	err    error
+}
+
+func newRecordingSync() *recordingSync {
+	return &recordingSync{called: make(chan bool, 16)}
+}
+
+func (r *recordingSync) sync(ctx context.Context, force bool) (bool, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, force)
+	block, err := r.block, r.err
+	r.mu.Unlock()
+	r.called <- force
+	if block != nil {
+		<-block
+	}
+	return err == nil, err
+}
+
+func (r *recordingSync) wait(t *testing.T) bool {
+	t.Helper()
+	select {
+	case force := <-r.called:
+		return force
+	case <-time.After(5 * time.Second):
+		t.Fatal("sync was not called")
+		return false
+	}
+}
+
+func (r *recordingSync) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+func startWatcher(t *testing.T, cfg config.WatchConfig, rs *recordingSync) (*Watcher, *fakeBackend, context.CancelFunc, <-chan error) {
+	t.Helper()
+	backend := &fakeBackend{events: make(chan string)}
+	w := newWatcher(backend, cfg, "/root", rs.sync)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- w.Run(ctx) }()
+	t.Cleanup(cancel)
+	return w, backend, cancel, errCh
+}
+
+// TestWatcherDebounce 연속된 변경은 quiet period 가 지난 뒤 sync 한 번으로 합쳐지는지 확인함.
+func TestWatcherDebounce(t *testing.T) {
+	rs := newRecordingSync()
+	cfg := config.WatchConfig{QuietPeriod: config.Duration(100 * time.Millisecond), MaxDelay: config.Duration(time.Minute)}
+	w, backend, cancel, errCh := startWatcher(t, cfg, rs)
+
+	// 시작할 때 한 번 sync 함.
+	if rs.wait(t) {
+		t.Fatal("initial sync should not be forced")
+	}
+
+	for _, p := range []string{"/root/a/1.fastq", "/root/a/2.fastq", "/root/a/1.fastq"} {
+		backend.events <- p
+		time.Sleep(20 * time.Millisecond)
+	}
+	if st := w.Status(); st.State != StatePending || st.PendingChanges != 2 || st.NextSync == nil || st.LastChangePath != "a/1.fastq" {
+		t.Fatalf("unexpected status while pending: %+v", st)
+	}
+	if rs.wait(t) {
+		t.Fatal("sync for data files should not be forced")
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := rs.count(); n != 2 {
+		t.Fatalf("expected burst to be synced once, got %d syncs", n)
+	}
+
+	// rule.json 이 바뀌면 force sync.
+	backend.events <- "/root/a/rule.json"
+	if !rs.wait(t) {
+		t.Fatal("rule.json change should force sync")
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if st := w.Status(); st.State != StateStopped || st.Syncs != 3 || st.LastSync == nil || !st.LastSync.Updated {
+		t.Fatalf("unexpected final status: %+v", st)
+	}
+}
+
+// TestWatcherMaxDelay 변경이 멈추지 않아도 maxDelay 가 지나면 sync 하는지 확인함.
+func TestWatcherMaxDelay(t *testing.T) {
+	rs := newRecordingSync()
+	cfg := config.WatchConfig{QuietPeriod: config.Duration(200 * time.Millisecond), MaxDelay: config.Duration(300 * time.Millisecond)}
+	_, backend, _, _ := startWatcher(t, cfg, rs)
+	rs.wait(t)
+
+	start := time.Now()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			case backend.events <- "/root/a/upload.bam":
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+	rs.wait(t)
+	close(stop)
+	<-done
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("sync was delayed %s despite maxDelay", elapsed)
+	}
+}
+
+// TestWatcherShutdownWaitsForSync 종료 신호를 받아도 실행 중인 sync 가 끝날 때까지 기다리는지 확인함.
+func TestWatcherShutdownWaitsForSync(t *testing.T) {
+	rs := newRecordingSync()
+	rs.block = make(chan struct{})
+	w, _, cancel, errCh := startWatcher(t, config.WatchConfig{}, rs)
+	rs.wait(t)
+	if st := w.Status(); st.State != StateSyncing {
+		t.Fatalf("expected syncing state, got %+v", st)
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		t.Fatalf("Run returned %v before the sync finished", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(rs.block)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the sync finished")
+	}
+	if st := w.Status(); st.State != StateStopped || st.Syncs != 1 {
+		t.Fatalf("unexpected final status: %+v", st)
+	}
+}
+
+// TestWatcherRetryAndHealth sync 가 실패하면 변경이 없어도 maxDelay 뒤에 다시 시도하고, 성공하면 /healthz 가 200 을 반환하는지 확인함.
+func TestWatcherRetryAndHealth(t *testing.T) {
+	rs := newRecordingSync()
+	rs.err = errors.New("disk gone")
+	cfg := config.WatchConfig{QuietPeriod: config.Duration(50 * time.Millisecond), MaxDelay: config.Duration(100 * time.Millisecond)}
+	w, _, _, _ := startWatcher(t, cfg, rs)
+	rs.wait(t)
+	rs.wait(t)
+
+	rs.mu.Lock()
+	rs.err = nil
+	rs.mu.Unlock()
+	rs.wait(t)
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Status().State != StateIdle && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	st := w.Status()
+	if st.State != StateIdle || st.Failures < 2 || st.LastSync.Error != "" {
+		t.Fatalf("unexpected status after recovery: %+v", st)
+	}
+
+	srv := httptest.NewServer(w.Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		t.Fatalf("unexpected /status response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	health, err := http.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz failed: %v", err)
+	}
+	health.Body.Close()
+	if health.StatusCode != http.StatusOK {
+		t.Fatalf("expected healthy watcher, got %d", health.StatusCode)
+	}
+}